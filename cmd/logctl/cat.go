@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+	"github.com/jbarasa/logger/logger/reader"
+)
+
+// readFlags are the flags selecting and rendering entries, shared by cat and tail
+type readFlags struct {
+	archives   bool
+	naming     string
+	since      string
+	until      string
+	level      string
+	grep       string
+	regexp     string
+	fields     fieldFlags
+	timeFormat string
+	utc        bool
+	keyEnv     string
+	output     string
+	color      string
+}
+
+// register adds the flags to fs
+func (f *readFlags) register(fs *flag.FlagSet) {
+	f.fields = fieldFlags{}
+	fs.BoolVar(&f.archives, "archives", false, "also read the rotated archives of FILE, oldest first")
+	fs.StringVar(&f.naming, "naming", "numbered", "archive naming of the log: numbered or lumberjack")
+	fs.StringVar(&f.since, "since", "", "entries at or after this time: a duration ago (2h) or a time (2006-01-02, RFC 3339)")
+	fs.StringVar(&f.until, "until", "", "entries before this time, in the same forms as -since")
+	fs.StringVar(&f.level, "level", "", "entries at this level and above, e.g. warn")
+	fs.StringVar(&f.grep, "grep", "", "entries whose message contains this text")
+	fs.StringVar(&f.regexp, "regexp", "", "entries whose message matches this regular expression")
+	fs.Var(f.fields, "field", "entries with this field value, as key=value (repeatable)")
+	fs.StringVar(&f.timeFormat, "time-format", "", "Config.TimeFormat the log was written with, if not the default")
+	fs.BoolVar(&f.utc, "utc", false, "read timestamps without a zone as UTC instead of local time")
+	fs.StringVar(&f.keyEnv, "key-env", "", "environment variable holding the encryption key (hex or base64) of an encrypted log")
+	fs.StringVar(&f.output, "o", "pretty", "output format: pretty, text, json or logfmt")
+	fs.StringVar(&f.color, "color", "auto", "color pretty output: auto, always or never")
+}
+
+// options builds the reader options from the flags
+func (f *readFlags) options() (reader.Options, error) {
+	var opts reader.Options
+	opts.Archives = f.archives
+	opts.TimeFormat = f.timeFormat
+	if f.utc {
+		opts.TimeLocation = time.UTC
+	}
+
+	switch strings.ToLower(f.naming) {
+	case "numbered":
+		opts.ArchiveNaming = logger.ArchiveNumbered
+	case "lumberjack":
+		opts.ArchiveNaming = logger.ArchiveLumberjack
+	default:
+		return opts, fmt.Errorf("unknown archive naming %q", f.naming)
+	}
+
+	if f.keyEnv != "" {
+		key, err := logger.KeyFromEnv(f.keyEnv)
+		if err != nil {
+			return opts, err
+		}
+		opts.Key = key
+	}
+
+	var err error
+	if opts.Filter.Since, err = parseWhen(f.since); err != nil {
+		return opts, err
+	}
+	if opts.Filter.Until, err = parseWhen(f.until); err != nil {
+		return opts, err
+	}
+	if f.level != "" {
+		level, err := logger.ParseLevel(f.level)
+		if err != nil {
+			return opts, err
+		}
+		opts.Filter.Levels = logger.LevelsFrom(int(level))
+	}
+	opts.Filter.Contains = f.grep
+	if f.regexp != "" {
+		if opts.Filter.Match, err = regexp.Compile(f.regexp); err != nil {
+			return opts, fmt.Errorf("invalid -regexp: %v", err)
+		}
+	}
+	if len(f.fields) > 0 {
+		opts.Filter.Fields = f.fields
+	}
+	return opts, nil
+}
+
+// formatter returns the formatter of the -o and -color flags
+func (f *readFlags) formatter() (logger.Formatter, error) {
+	switch strings.ToLower(f.output) {
+	case "pretty":
+		color, err := colorOutput(f.color)
+		if err != nil {
+			return nil, err
+		}
+		return logger.PrettyFormatter{Color: color}, nil
+	case "text":
+		return logger.TextFormatter{}, nil
+	case "json":
+		return logger.JSONFormatter{}, nil
+	case "logfmt":
+		return logger.LogfmtFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", f.output)
+	}
+}
+
+// colorOutput resolves the -color flag; auto colors a terminal unless
+// NO_COLOR is set
+func colorOutput(mode string) (bool, error) {
+	switch strings.ToLower(mode) {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("unknown color mode %q", mode)
+	}
+}
+
+// parseWhen parses a -since or -until value: a duration before now, a date,
+// or an RFC 3339 time
+func parseWhen(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want a duration like 2h or a time like 2006-01-02T15:04:05", s)
+}
+
+// runCat prints the entries of the given files
+func runCat(args []string, out io.Writer) error {
+	var flags readFlags
+	fs := newFlagSet("cat", "FILE...")
+	flags.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	opts, err := flags.options()
+	if err != nil {
+		return err
+	}
+	format, err := flags.formatter()
+	if err != nil {
+		return err
+	}
+	if opts.Archives && fs.NArg() > 1 {
+		return fmt.Errorf("-archives reads one log at a time")
+	}
+
+	var r *reader.Reader
+	if opts.Archives {
+		if r, err = reader.Open(fs.Arg(0), opts); err != nil {
+			return err
+		}
+	} else {
+		r = reader.OpenFiles(fs.Args(), opts)
+	}
+	defer r.Close()
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	for r.Next() {
+		if _, err := w.Write(format.Format(r.Entry())); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
@@ -0,0 +1,119 @@
+// Command logctl reads the files written by github.com/jbarasa/logger: it
+// prints and filters entries across archives, converts between formats,
+// follows a live log, and unpacks compressed or encrypted archives.
+//
+// Usage:
+//
+//	logctl cat [flags] FILE...     Print the entries of log files
+//	logctl tail [flags] FILE       Print the last entries of a log, optionally following it
+//	logctl unpack [flags] FILE     Write the plain contents of a .gz or encrypted file
+//
+// Examples:
+//
+//	logctl cat -archives -since 2h -level warn storage/logs/app.log
+//	logctl cat -o json -field user_id=42 app.log > app.json
+//	logctl tail -f -grep timeout app.log
+//	logctl unpack -key-env LOG_KEY archive/app-3.log.gz > app-3.log
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errUsage reports invalid arguments, after the usage was printed
+var errUsage = errors.New("usage")
+
+// errHelp reports that help was asked for and printed
+var errHelp = errors.New("help")
+
+const usage = `Usage: logctl <command> [flags] FILE...
+
+Commands:
+  cat     Print the entries of log files, filtered and converted
+  tail    Print the last entries of a log file, optionally following it
+  unpack  Write the plain contents of a compressed or encrypted file
+
+Run "logctl <command> -h" for the flags of a command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		switch err {
+		case errHelp:
+		case errUsage:
+			os.Exit(2)
+		default:
+			fmt.Fprintf(os.Stderr, "logctl: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// run executes the command in args, writing entries to out
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "cat":
+		return runCat(args[1:], out)
+	case "tail":
+		return runTail(args[1:], out)
+	case "unpack":
+		return runUnpack(args[1:], out)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "logctl: unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+}
+
+// newFlagSet creates the flag set of a command with its usage line
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: logctl %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses a command's flags; the flag package has already printed
+// the usage when it fails
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return errHelp
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// fieldFlags collects repeated -field key=value flags
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("want key=value, got %q", s)
+	}
+	f[key] = value
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+	"github.com/jbarasa/logger/logger/reader"
+)
+
+// followInterval is how often a followed log is checked for new lines
+const followInterval = 250 * time.Millisecond
+
+// runTail prints the last entries of a log and, with -f, the entries
+// written to it afterwards, across rotations
+func runTail(args []string, out io.Writer) error {
+	var flags readFlags
+	var n int
+	var follow bool
+	fs := newFlagSet("tail", "FILE")
+	flags.register(fs)
+	fs.IntVar(&n, "n", 10, "number of entries to print")
+	fs.BoolVar(&follow, "f", false, "keep printing entries as they are written")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	opts, err := flags.options()
+	if err != nil {
+		return err
+	}
+	format, err := flags.formatter()
+	if err != nil {
+		return err
+	}
+	if follow && opts.Key != nil {
+		return fmt.Errorf("-f cannot follow an encrypted log")
+	}
+	path := fs.Arg(0)
+
+	t := &tail{n: n, format: format, out: bufio.NewWriter(out)}
+	defer t.out.Flush()
+	if !follow {
+		r, err := reader.Open(path, opts)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		for r.Next() {
+			t.keep(r.Entry())
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+		return t.flush()
+	}
+
+	// Archives are complete; the live file is read by the follower so that no
+	// line written while reading is missed or printed twice
+	if opts.Archives {
+		archives, err := logger.Archives(path, opts.ArchiveNaming)
+		if err != nil {
+			return err
+		}
+		r := reader.OpenFiles(archives, opts)
+		for r.Next() {
+			t.keep(r.Entry())
+		}
+		r.Close()
+		if err := r.Err(); err != nil {
+			return err
+		}
+	}
+	f := &follower{path: path, opts: opts}
+	defer f.close()
+	return f.run(t)
+}
+
+// tail keeps the last n entries until they are printed
+type tail struct {
+	n       int
+	last    []logger.Entry
+	printed bool // The backlog was printed; entries are now printed as they come
+	format  logger.Formatter
+	out     *bufio.Writer
+}
+
+// keep records an entry, printing it directly once the backlog is out
+func (t *tail) keep(e logger.Entry) error {
+	if t.printed {
+		_, err := t.out.Write(t.format.Format(e))
+		return err
+	}
+	if t.n <= 0 {
+		return nil
+	}
+	if len(t.last) == t.n {
+		copy(t.last, t.last[1:])
+		t.last = t.last[:t.n-1]
+	}
+	t.last = append(t.last, e)
+	return nil
+}
+
+// flush prints the kept entries and any buffered output
+func (t *tail) flush() error {
+	if !t.printed {
+		t.printed = true
+		for _, e := range t.last {
+			if _, err := t.out.Write(t.format.Format(e)); err != nil {
+				return err
+			}
+		}
+		t.last = nil
+	}
+	return t.out.Flush()
+}
+
+// follower reads a live log as it grows, reopening it after rotation
+type follower struct {
+	path    string
+	opts    reader.Options
+	file    *os.File
+	lines   *bufio.Reader
+	partial []byte // Start of a line whose end is not written yet
+	offset  int64
+}
+
+// run reads the log forever, printing the backlog at the first end of file
+func (f *follower) run(t *tail) error {
+	for {
+		if f.file == nil {
+			waited, err := f.open()
+			if err != nil {
+				return err
+			}
+			// A file that appeared while waiting holds only new entries
+			if waited {
+				if err := t.flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := f.drain(t); err != nil {
+			return err
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+		time.Sleep(followInterval)
+
+		rotated, err := f.rotated()
+		if err != nil {
+			return err
+		}
+		if rotated {
+			// Lines written before the rename are still in the old file
+			if err := f.drain(t); err != nil {
+				return err
+			}
+			f.close()
+		}
+	}
+}
+
+// open opens the log, waiting for it to appear while it is being rotated,
+// and reports whether it had to wait
+func (f *follower) open() (bool, error) {
+	for waited := false; ; waited = true {
+		file, err := os.Open(f.path)
+		if err == nil {
+			f.file, f.lines = file, bufio.NewReader(file)
+			f.partial, f.offset = nil, 0
+			return waited, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to open log file: %v", err)
+		}
+		time.Sleep(followInterval)
+	}
+}
+
+// drain processes every complete line up to the end of the file
+func (f *follower) drain(t *tail) error {
+	for {
+		line, err := f.lines.ReadBytes('\n')
+		f.offset += int64(len(line))
+		if err == io.EOF {
+			f.partial = append(f.partial, line...)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", f.path, err)
+		}
+		if len(f.partial) > 0 {
+			line = append(f.partial, line...)
+			f.partial = nil
+		}
+		entry, err := reader.ParseLine(line, f.opts)
+		if err != nil || !f.opts.Filter.Matches(&entry) {
+			continue
+		}
+		if err := t.keep(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// rotated reports whether the path now names another file, or the file was
+// truncated in place
+func (f *follower) rotated() (bool, error) {
+	onDisk, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get file info: %v", err)
+	}
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to get file info: %v", err)
+	}
+	return !os.SameFile(current, onDisk) || onDisk.Size() < f.offset, nil
+}
+
+// close closes the followed file
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// runUnpack writes the plain contents of a compressed or encrypted file, as
+// rotation left it, to -out or standard output
+func runUnpack(args []string, out io.Writer) error {
+	var keyEnv, outPath string
+	fs := newFlagSet("unpack", "FILE")
+	fs.StringVar(&keyEnv, "key-env", "", "environment variable holding the encryption key (hex or base64) of an encrypted file")
+	fs.StringVar(&outPath, "out", "", "write to this file instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := fs.Arg(0)
+
+	var key []byte
+	if keyEnv != "" {
+		var err error
+		if key, err = logger.KeyFromEnv(keyEnv); err != nil {
+			return err
+		}
+	}
+
+	var file *os.File
+	if outPath != "" {
+		var err error
+		file, err = os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	w := bufio.NewWriter(out)
+
+	var err error
+	if key != nil {
+		err = logger.DecryptFile(path, key, w)
+	} else {
+		err = gunzipFile(path, w)
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	if file != nil {
+		return file.Close()
+	}
+	return nil
+}
+
+// gunzipFile copies a file to w, decompressing it when it ends in .gz
+func gunzipFile(path string, w io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to open compressed log file: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to unpack %s: %v", path, err)
+	}
+	return nil
+}
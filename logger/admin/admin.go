@@ -0,0 +1,239 @@
+// Package admin provides an http.Handler for controlling a running logger:
+// view and change levels, stream entries as they are written, read the
+// counters, and trigger a rotation or flush. Mount it on an internal admin
+// mux, never on a public listener:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/debug/log/", http.StripPrefix("/debug/log", admin.Handler(admin.Options{})))
+//	go http.ListenAndServe("127.0.0.1:6060", mux)
+//
+// Endpoints, relative to the mount point:
+//
+//	GET  /level               {"level":"INFO"}; ?name=db for a named logger
+//	PUT  /level?level=debug   Change the level (POST and a JSON body {"level":"debug","name":"db"} also work)
+//	GET  /stats               Stats as JSON
+//	GET  /metrics             Stats in the Prometheus text format
+//	GET  /stream?level=warn   Server-sent events, one JSON entry per event
+//	POST /rotate              Rotate the log file
+//	POST /flush               Write queued entries and sync the file
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// streamHeartbeat is how often an idle stream sends a comment, so proxies
+// keep the connection open
+const streamHeartbeat = 15 * time.Second
+
+// Options configures the handler
+type Options struct {
+	Logger       *logger.Logger // Logger to control (default: the default logger at request time)
+	ReadOnly     bool           // Reject level changes, rotation and flushes
+	StreamBuffer int            // Entries buffered per stream before the oldest are dropped (default: 1024)
+}
+
+// handler serves the admin endpoints
+type handler struct {
+	opts Options
+}
+
+// Handler returns the admin handler
+func Handler(opts Options) http.Handler {
+	return &handler{opts: opts}
+}
+
+// ServeHTTP routes a request to its endpoint
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := h.opts.Logger
+	if l == nil {
+		l = logger.Default()
+	}
+	if l == nil {
+		http.Error(w, "logger not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "", "/":
+		h.index(w, r)
+	case "/level":
+		h.level(w, r, l)
+	case "/stats":
+		if allow(w, r, http.MethodGet) {
+			writeJSON(w, l.Stats())
+		}
+	case "/metrics":
+		if allow(w, r, http.MethodGet) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			l.WriteMetrics(w)
+		}
+	case "/stream":
+		if allow(w, r, http.MethodGet) {
+			h.stream(w, r, l)
+		}
+	case "/rotate":
+		h.action(w, r, l.Rotate)
+	case "/flush":
+		h.action(w, r, l.Flush)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// index lists the endpoints
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "level\nstats\nmetrics\nstream\nrotate\nflush\n")
+}
+
+// levelRequest is the JSON body of a level change
+type levelRequest struct {
+	Level string `json:"level"`
+	Name  string `json:"name"`
+}
+
+// level reports or changes the level of the logger or a named logger
+func (h *handler) level(w http.ResponseWriter, r *http.Request, l *logger.Logger) {
+	req := levelRequest{Level: r.URL.Query().Get("level"), Name: r.URL.Query().Get("name")}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		if h.opts.ReadOnly {
+			http.Error(w, "read-only", http.StatusForbidden)
+			return
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid JSON body: %v", err), http.StatusBadRequest)
+				return
+			}
+		} else if v := r.PostFormValue("level"); v != "" {
+			req.Level = v
+		}
+		level, err := logger.ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Name != "" {
+			l.SetNamedLevel(req.Name, int(level))
+		} else {
+			l.SetLevel(int(level))
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	current := l
+	if req.Name != "" {
+		current = l.Named(req.Name)
+	}
+	writeJSON(w, map[string]interface{}{"level": logger.Level(current.GetLevel())})
+}
+
+// action runs a mutating endpoint
+func (h *handler) action(w http.ResponseWriter, r *http.Request, fn func() error) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	if h.opts.ReadOnly {
+		http.Error(w, "read-only", http.StatusForbidden)
+		return
+	}
+	if err := fn(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+// stream sends written entries as server-sent events until the client
+// disconnects. A client that falls behind misses the oldest entries.
+func (h *handler) stream(w http.ResponseWriter, r *http.Request, l *logger.Logger) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	level := logger.TRACE
+	if v := r.URL.Query().Get("level"); v != "" {
+		parsed, err := logger.ParseLevel(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level = int(parsed)
+	}
+
+	entries := make(chan logger.Entry)
+	done := make(chan struct{})
+	defer close(done)
+	sub := l.SubscribeWith(level, func(e logger.Entry) {
+		select {
+		case entries <- e:
+		case <-done:
+		}
+	}, logger.SubscribeConfig{Buffer: h.opts.StreamBuffer, Policy: logger.SubscriberDropOldest})
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e := <-entries:
+			data, err := e.MarshalJSON()
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// allow reports whether the request uses method (or HEAD for GET), answering
+// 405 otherwise
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || method == http.MethodGet && r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// writeJSON writes v as an indented JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
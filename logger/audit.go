@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// auditMACPrefix separates the signed part of an audit line from its MAC
+const auditMACPrefix = `,"mac":"`
+
+// AuditConfig configures an AuditLogger
+type AuditConfig struct {
+	Path   string // Audit log file, opened append-only and never rotated
+	Key    []byte // HMAC-SHA256 key; keep it outside the log directory
+	NoSync bool   // Skip the fsync after every entry (faster, but entries may be lost on a crash)
+}
+
+// AuditLogger writes tamper-evident audit trails. Each line is a JSON object
+// with a sequence number and the MAC of the previous line, and is signed with
+// HMAC-SHA256 over its content, so editing, removing or reordering lines
+// breaks the chain (see VerifyAudit). Writes are synchronous: Log returns
+// only once the entry is in the file.
+type AuditLogger struct {
+	mu     sync.Mutex
+	file   *os.File
+	key    []byte
+	seq    uint64
+	prev   string // MAC of the last line, "" before the first
+	noSync bool
+}
+
+// AuditEntry is a verified line of an audit log
+type AuditEntry struct {
+	Seq    uint64                 `json:"seq"`
+	Time   time.Time              `json:"time"`
+	Caller string                 `json:"caller"`
+	Event  string                 `json:"event"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// NewAuditLogger opens or creates an audit log, continuing the chain of an
+// existing file from its last complete line
+func NewAuditLogger(config AuditConfig) (*AuditLogger, error) {
+	if len(config.Key) == 0 {
+		return nil, errors.New("audit logger requires a key")
+	}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+
+	a := &AuditLogger{key: config.Key, noSync: config.NoSync}
+	if err := a.resume(config.Path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	a.file = file
+	return a, nil
+}
+
+// resume picks up the sequence number and MAC of the last line of an existing
+// file. A last line left incomplete by a crash was never signed, so it is cut
+// off and the chain continues from the last complete line.
+func (a *AuditLogger) resume(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var last, tail []byte
+	var complete int64 // End of the last line with its newline
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			tail = line
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %v", err)
+		}
+		complete += int64(len(line))
+		if line = bytes.TrimSuffix(line, []byte("\n")); len(line) > 0 {
+			last = line
+		}
+	}
+	if len(tail) > 0 {
+		if err := a.repairTail(path, tail, complete); err != nil {
+			return err
+		}
+		if a.signedLine(tail) {
+			last = tail
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	signed, mac, err := splitAuditLine(last)
+	if err != nil {
+		return fmt.Errorf("failed to resume audit log: %v", err)
+	}
+	var head struct {
+		Seq uint64 `json:"seq"`
+	}
+	if err := json.Unmarshal(append(signed, '}'), &head); err != nil {
+		return fmt.Errorf("failed to resume audit log: %v", err)
+	}
+	a.seq, a.prev = head.Seq, mac
+	return nil
+}
+
+// repairTail ends a file whose last line has no newline. A signed line only
+// lost its newline and gets it back; anything else is cut off at size, the
+// end of the last complete line.
+func (a *AuditLogger) repairTail(path string, tail []byte, size int64) error {
+	if !a.signedLine(tail) {
+		if err := os.Truncate(path, size); err != nil {
+			return fmt.Errorf("failed to cut incomplete audit line: %v", err)
+		}
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write([]byte("\n")); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	return nil
+}
+
+// signedLine reports whether line carries a valid MAC for its content
+func (a *AuditLogger) signedLine(line []byte) bool {
+	signed, mac, err := splitAuditLine(line)
+	return err == nil && hmac.Equal([]byte(auditMAC(a.key, signed)), []byte(mac))
+}
+
+// Log appends an audit event with its fields
+func (a *AuditLogger) Log(event string, fields Fields) error {
+	_, file, line, _ := runtime.Caller(1)
+	caller := fmt.Sprintf("%s:%d", filepath.Base(file), line)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return errors.New("audit logger is closed")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `{"seq":%d,"time":`, a.seq+1)
+	appendJSONValue(&buf, time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"caller":`)
+	appendJSONValue(&buf, caller)
+	buf.WriteString(`,"event":`)
+	appendJSONValue(&buf, event)
+	if len(fields) > 0 {
+		buf.WriteString(`,"fields":`)
+		appendJSONObject(&buf, fields.sorted())
+	}
+	buf.WriteString(`,"prev":`)
+	appendJSONValue(&buf, a.prev)
+
+	mac := auditMAC(a.key, buf.Bytes())
+	buf.WriteString(auditMACPrefix)
+	buf.WriteString(mac)
+	buf.WriteString("\"}\n")
+
+	if _, err := a.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	if !a.noSync {
+		if err := a.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync audit log: %v", err)
+		}
+	}
+	a.seq++
+	a.prev = mac
+	return nil
+}
+
+// Close syncs and closes the audit log
+func (a *AuditLogger) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	var firstErr error
+	if err := a.file.Sync(); err != nil {
+		firstErr = fmt.Errorf("failed to sync audit log: %v", err)
+	}
+	if err := a.file.Close(); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("failed to close audit log: %v", err)
+	}
+	a.file = nil
+	return firstErr
+}
+
+// VerifyAudit checks the chain of an audit log written with key and returns
+// its entries. The error names the first line that was altered, removed,
+// inserted or reordered. Lines cut from the end of the file cannot be detected
+// from the file alone; compare the last sequence number with a copy kept elsewhere.
+func VerifyAudit(path string, key []byte) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+	return verifyAudit(file, key)
+}
+
+// verifyAudit checks every line read from r
+func verifyAudit(r io.Reader, key []byte) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	prev := ""
+	lineNum := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		signed, mac, err := splitAuditLine(line)
+		if err != nil {
+			return entries, fmt.Errorf("audit log line %d: %v", lineNum, err)
+		}
+		if !hmac.Equal([]byte(auditMAC(key, signed)), []byte(mac)) {
+			return entries, fmt.Errorf("audit log line %d: MAC mismatch, line was altered or signed with another key", lineNum)
+		}
+
+		var entry struct {
+			AuditEntry
+			Prev string `json:"prev"`
+		}
+		if err := json.Unmarshal(append(signed[:len(signed):len(signed)], '}'), &entry); err != nil {
+			return entries, fmt.Errorf("audit log line %d: %v", lineNum, err)
+		}
+		if entry.Prev != prev {
+			return entries, fmt.Errorf("audit log line %d: chain broken, a previous line was removed or reordered", lineNum)
+		}
+		if want := uint64(len(entries)) + 1; entry.Seq != want {
+			return entries, fmt.Errorf("audit log line %d: sequence %d, expected %d", lineNum, entry.Seq, want)
+		}
+
+		entries = append(entries, entry.AuditEntry)
+		prev = mac
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read audit log: %v", err)
+	}
+	return entries, nil
+}
+
+// splitAuditLine separates the signed part of a line from its MAC
+func splitAuditLine(line []byte) ([]byte, string, error) {
+	i := bytes.LastIndex(line, []byte(auditMACPrefix))
+	if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, "", errors.New("missing MAC")
+	}
+	return line[:i], string(line[i+len(auditMACPrefix) : len(line)-2]), nil
+}
+
+// auditMAC returns the hex HMAC-SHA256 of data
+func auditMAC(key, data []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeAudit(t *testing.T, path string, key []byte, events ...string) {
+	t.Helper()
+	a, err := NewAuditLogger(AuditConfig{Path: path, Key: key, NoSync: true})
+	if err != nil {
+		t.Fatalf("NewAuditLogger: %v", err)
+	}
+	for _, event := range events {
+		if err := a.Log(event, nil); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func checkAudit(t *testing.T, path string, key []byte, want ...string) {
+	t.Helper()
+	entries, err := VerifyAudit(path, key)
+	if err != nil {
+		t.Fatalf("VerifyAudit: %v", err)
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Seq != uint64(i+1) || entry.Event != want[i] {
+			t.Errorf("entry %d = seq %d %q, want seq %d %q", i, entry.Seq, entry.Event, i+1, want[i])
+		}
+	}
+}
+
+func TestAuditResumesAfterPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("secret")
+	writeAudit(t, path, key, "one", "two")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"seq":3,"time":"2024`)
+	file.Close()
+
+	writeAudit(t, path, key, "three")
+	checkAudit(t, path, key, "one", "two", "three")
+}
+
+func TestAuditResumesAfterMissingNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("secret")
+	writeAudit(t, path, key, "one", "two")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-1], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	writeAudit(t, path, key, "three")
+	checkAudit(t, path, key, "one", "two", "three")
+}
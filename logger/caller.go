@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	helpers     sync.Map     // Fully qualified names of functions marked with Helper
+	helperCount atomic.Int32 // Number of marked functions, so lookups skip the walk when there are none
+)
+
+// CallerPath selects how the caller's file is written
+type CallerPath int
+
+const (
+	CallerRelative CallerPath = iota // Relative to the working directory, e.g. internal/api/handler.go (default)
+	CallerShort                      // Directory and file name only, e.g. api/handler.go
+	CallerFull                       // Absolute path
+)
+
+// CallerConfig controls how the calling file and line are looked up and written
+type CallerConfig struct {
+	Disable  bool       // Skip the runtime.Caller lookup entirely; entries carry no caller
+	Function bool       // Also write the calling function, e.g. api.(*Server).Handle
+	Path     CallerPath // Form of the file path (default: CallerRelative)
+	Skip     int        // Extra frames to skip for every entry, for loggers only used through wrappers
+}
+
+// AddCallerSkip returns a child logger that reports the caller n frames
+// further up the stack. Use it in wrapper functions so the reported file:line
+// is the wrapper's caller rather than the wrapper itself.
+func (l *Logger) AddCallerSkip(n int) *Logger {
+	if l == nil {
+		return nil
+	}
+	child := l.withFields(nil)
+	child.callerSkip += n
+	return child
+}
+
+// AddCallerSkip returns a child of the default logger that skips n more frames
+func AddCallerSkip(n int) *Logger {
+	return defaultLogger.AddCallerSkip(n)
+}
+
+// Helper marks the calling function as a logging helper, like testing.T.Helper:
+// entries logged from it, or from other helpers it calls, report the caller
+// of the outermost helper. It works with every logger, including the
+// package-level functions, and is cheap to call on every invocation.
+//
+//	func logRequest(r *http.Request) {
+//	    logger.Helper()
+//	    logger.Info("%s %s", r.Method, r.URL.Path)
+//	}
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, loaded := helpers.LoadOrStore(frame.Function, struct{}{}); !loaded {
+		helperCount.Add(1)
+	}
+}
+
+// isHelper reports whether function was marked with Helper
+func isHelper(function string) bool {
+	if helperCount.Load() == 0 {
+		return false
+	}
+	_, ok := helpers.Load(function)
+	return ok
+}
+
+// caller looks up the call site skip frames above the caller of this method,
+// then past any functions marked with Helper
+func (l *Logger) caller(skip int) (uintptr, string, int) {
+	if l.callerOff {
+		return 0, "", 0
+	}
+	skip += 1 + l.callerDepth + l.callerSkip
+	if helperCount.Load() == 0 {
+		pc, file, line, _ := runtime.Caller(skip)
+		return pc, file, line
+	}
+
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !more || !isHelper(frame.Function) {
+			return frame.PC, frame.File, frame.Line
+		}
+	}
+}
+
+// callerFunction returns the calling function of an entry without its import
+// path, or "" when Config.Caller.Function is not set
+func (l *Logger) callerFunction(entry *logEntry) string {
+	if !l.callerFunc || entry.pc == 0 {
+		return ""
+	}
+	fn := runtime.FuncForPC(entry.pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// callerKey identifies a call site in the caller cache
+type callerKey struct {
+	file string
+	line int
+}
+
+// callerFile renders a file path in the configured form
+func (l *Logger) callerFile(file string) string {
+	switch l.callerPath {
+	case CallerFull:
+		return file
+	case CallerShort:
+		return filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)))
+	}
+
+	// Relative to the working directory at startup, for IDE integration
+	if abs, err := filepath.Abs(file); err == nil {
+		if rel, err := filepath.Rel(l.pwd, abs); err == nil {
+			return rel
+		}
+	}
+	return file
+}
+
+// callerSegment joins the caller and function for the text layouts
+func callerSegment(caller, function string) string {
+	if function == "" {
+		return caller
+	}
+	if caller == "" {
+		return function
+	}
+	return caller + " " + function
+}
+
+// formatCaller renders the caller segment of a log line, or "" when the entry
+// has no caller
+func (l *Logger) formatCaller(entry *logEntry) string {
+	if l.callerFormatter != nil {
+		function := ""
+		if fn := runtime.FuncForPC(entry.pc); fn != nil {
+			function = fn.Name()
+		}
+		return l.callerFormatter(entry.file, entry.line, function)
+	}
+	if entry.file == "" {
+		return ""
+	}
+
+	// A program logs from a bounded set of call sites, so each is rendered once
+	key := callerKey{entry.file, entry.line}
+	l.callersMu.RLock()
+	caller, ok := l.callers[key]
+	l.callersMu.RUnlock()
+	if ok {
+		return caller
+	}
+
+	caller = l.callerFile(entry.file) + ":" + strconv.Itoa(entry.line)
+	l.callersMu.Lock()
+	if l.callers == nil {
+		l.callers = make(map[callerKey]string)
+	}
+	l.callers[key] = caller
+	l.callersMu.Unlock()
+	return caller
+}
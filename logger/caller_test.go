@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCallerFormatter(t *testing.T) {
+	l := newTestLogger(t, Config{
+		CallerFormatter: func(file string, line int, function string) string {
+			return fmt.Sprintf("%s:%d (%s)", filepath.Base(file), line, function[strings.LastIndexByte(function, '.')+1:])
+		},
+	})
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("cache warmed")
+
+	lines := readLines(t, l)
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %v", len(lines), lines)
+	}
+	want := fmt.Sprintf("caller_test.go:%d (TestCallerFormatter)", line+1)
+	if !strings.Contains(lines[0], want) {
+		t.Errorf("line %q does not hold the custom caller %q", lines[0], want)
+	}
+}
+
+// BenchmarkFormatCaller compares the caller cache with resolving the relative
+// path for every entry
+func BenchmarkFormatCaller(b *testing.B) {
+	l := newTestLogger(b, Config{})
+	_, file, line, _ := runtime.Caller(0)
+	entry := &logEntry{file: file, line: line}
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.formatCaller(entry)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = l.callerFile(entry.file) + ":" + strconv.Itoa(entry.line)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode controls ANSI colors in development console output
+type ColorMode int
+
+// Color modes
+const (
+	ColorAuto   ColorMode = iota // Color when the console writer is a terminal, honoring NO_COLOR and FORCE_COLOR (default)
+	ColorAlways                  // Always color, even when the console writer is redirected
+	ColorNever                   // Never color
+)
+
+// ColorTheme maps levels to the ANSI escape sequences their console output
+// is painted with, e.g. {logger.WARN: logger.Color256(214), logger.FATAL:
+// logger.Bold(logger.TrueColor(200, 0, 120))}. Levels missing from a theme
+// keep their default color.
+type ColorTheme map[int]string
+
+// color returns the color of a level under the theme
+func (t ColorTheme) color(level int) string {
+	if c, ok := t[level]; ok {
+		return c
+	}
+	return levelColor(level)
+}
+
+// clone copies the theme so later changes to the caller's map have no effect
+func (t ColorTheme) clone() ColorTheme {
+	if t == nil {
+		return nil
+	}
+	c := make(ColorTheme, len(t))
+	for level, color := range t {
+		c[level] = color
+	}
+	return c
+}
+
+// DefaultTheme returns the default level colors: gray, blue, green, yellow,
+// red, bold red and purple from TRACE to FATAL
+func DefaultTheme() ColorTheme {
+	return ColorTheme(levelColors).clone()
+}
+
+// HighContrastTheme returns bright colors, bold from WARN up, and PANIC and
+// FATAL on red and magenta backgrounds, for low-contrast terminals and low
+// vision
+func HighContrastTheme() ColorTheme {
+	return ColorTheme{
+		TRACE: "\033[37m",
+		DEBUG: "\033[96m",
+		INFO:  "\033[92m",
+		WARN:  Bold("\033[93m"),
+		ERROR: Bold("\033[91m"),
+		PANIC: Bold("\033[97;41m"),
+		FATAL: Bold("\033[97;45m"),
+	}
+}
+
+// ColorblindTheme returns colors from the Okabe-Ito palette, which stay
+// distinct with red-green color blindness (needs a truecolor terminal)
+func ColorblindTheme() ColorTheme {
+	return ColorTheme{
+		TRACE: Color256(245),
+		DEBUG: TrueColor(86, 180, 233),
+		INFO:  TrueColor(0, 114, 178),
+		WARN:  TrueColor(230, 159, 0),
+		ERROR: TrueColor(213, 94, 0),
+		PANIC: Bold(TrueColor(213, 94, 0)),
+		FATAL: Bold(TrueColor(204, 121, 167)),
+	}
+}
+
+// ThemeByName returns a built-in theme by name: default, high-contrast or
+// colorblind
+func ThemeByName(name string) (ColorTheme, error) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return DefaultTheme(), nil
+	case "high-contrast":
+		return HighContrastTheme(), nil
+	case "colorblind":
+		return ColorblindTheme(), nil
+	default:
+		return nil, fmt.Errorf("unknown color theme %q", name)
+	}
+}
+
+// Color256 returns the foreground color n of the 256-color palette
+func Color256(n uint8) string {
+	return fmt.Sprintf("\033[38;5;%dm", n)
+}
+
+// TrueColor returns a 24-bit foreground color
+func TrueColor(r, g, b uint8) string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
+// Bold returns color in bold
+func Bold(color string) string {
+	return colorBold + color
+}
+
+// useColor decides whether console output written to w is colored. Explicit
+// modes win, then FORCE_COLOR and NO_COLOR (https://no-color.org), then
+// whether w is a terminal that understands ANSI sequences; writers other than
+// files are never terminals.
+func useColor(mode ColorMode, w io.Writer) bool {
+	f, _ := w.(*os.File)
+	switch mode {
+	case ColorAlways:
+		if f != nil {
+			enableVirtualTerminal(f)
+		}
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if v, ok := os.LookupEnv("FORCE_COLOR"); ok && v != "0" && v != "false" {
+		if f != nil {
+			enableVirtualTerminal(f)
+		}
+		return true
+	}
+	if os.Getenv("NO_COLOR") != "" || f == nil {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return enableVirtualTerminal(f)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package logger
+
+import "os"
+
+// enableVirtualTerminal reports true; terminals outside Windows understand ANSI sequences
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
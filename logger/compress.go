@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// compressedExt is appended to archives once they are gzipped
+const compressedExt = ".gz"
+
+// compressArchive gzips a rotated archive in the background and removes the
+// original once the compressed copy is safely in place
+func (l *Logger) compressArchive(path string) {
+	l.compressWG.Add(1)
+	l.busyArchives.Store(path, true)
+	go func() {
+		defer l.compressWG.Done()
+		defer l.busyArchives.Delete(path)
+		if err := gzipFile(path); err != nil {
+			l.reportError(OpCompress, err, "Error compressing archive %s: %v", path, err)
+			return
+		}
+		// Retention skipped the archive while it was compressed
+		l.busyArchives.Delete(path)
+		l.queueUpload(path + compressedExt)
+		l.requestCleanup()
+	}()
+}
+
+// gzipFile compresses path to path.gz and deletes path
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %v", err)
+	}
+	defer src.Close()
+
+	// Write to a temporary name so a partial .gz is never mistaken for a complete one
+	tmpPath := path + compressedExt + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed archive: %v", err)
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress archive: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to finish compressed archive: %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close compressed archive: %v", err)
+	}
+
+	// Keep the rotation time, which retention and readers date archives by
+	if info, err := src.Stat(); err == nil {
+		os.Chtimes(tmpPath, info.ModTime(), info.ModTime())
+	}
+
+	if err := os.Rename(tmpPath, path+compressedExt); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move compressed archive into place: %v", err)
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// claimArchiveFile creates an archive file exclusively, reporting false when
+// the name (or its compressed form) is already taken
+func claimArchiveFile(path string) (bool, error) {
+	if _, err := os.Stat(path + compressedExt); err == nil {
+		return false, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err == nil {
+		f.Close()
+		return true, nil
+	}
+	if os.IsExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to claim archive file: %v", err)
+}
@@ -0,0 +1,720 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileConfig is the serializable form of Config read by InitializeFromFile and
+// InitializeFromEnv. Levels, formats and policies are given by name, sizes as
+// bytes or strings such as "25MB", and durations as strings such as "720h".
+type FileConfig struct {
+	Path           string            `json:"path"`               // LogPath
+	StdoutOnly     bool              `json:"stdout_only"`        // StdoutOnly
+	Level          string            `json:"level"`              // trace, debug, info, warn, error, panic or fatal
+	Format         string            `json:"format"`             // text, json or logfmt
+	BufferSize     int               `json:"buffer_size"`        // BufferSize
+	Sync           bool              `json:"sync"`               // Sync
+	BatchSize      int               `json:"batch_size"`         // BatchSize
+	FlushInterval  Duration          `json:"flush_interval"`     // FlushInterval
+	FormatWorkers  int               `json:"format_workers"`     // FormatWorkers
+	Dev            bool              `json:"dev"`                // IsDev
+	Console        bool              `json:"console"`            // Console
+	ConsoleLevel   string            `json:"console_level"`      // Lowest level printed to the console (ConsoleLevels)
+	Color          string            `json:"color"`              // auto, always or never
+	ColorTheme     string            `json:"color_theme"`        // default, high-contrast or colorblind
+	TimeFormat     string            `json:"time_format"`        // TimeFormat
+	MaxFileSize    ByteSize          `json:"max_file_size"`      // MaxFileSize
+	RotateEvery    string            `json:"rotate_every"`       // none, hourly or daily
+	Compress       bool              `json:"compress"`           // Compress
+	ArchiveNaming  string            `json:"archive_naming"`     // numbered or lumberjack
+	ReopenOnSignal bool              `json:"reopen_on_signal"`   // ReopenOnSignal
+	ReopenCheck    Duration          `json:"reopen_check"`       // ReopenCheck
+	MaxAge         Duration          `json:"max_age"`            // MaxAge
+	MaxBackups     int               `json:"max_backups"`        // MaxBackups
+	MaxTotalSize   ByteSize          `json:"max_total_size"`     // MaxTotalSize
+	MaxMessageSize ByteSize          `json:"max_message_size"`   // MaxMessageSize
+	WriteBuffer    ByteSize          `json:"write_buffer_size"`  // WriteBufferSize
+	Preallocate    ByteSize          `json:"preallocate"`        // Preallocate
+	OversizePolicy string            `json:"oversize_policy"`    // truncate, split or drop
+	TimingLevel    string            `json:"timing_level"`       // Level of Span and TimeTrack entries
+	KeyEnv         string            `json:"encryption_key_env"` // Environment variable holding EncryptionKey (see KeyFromEnv)
+	ServiceName    string            `json:"service_name"`       // ServiceName
+	Environment    string            `json:"environment"`        // Environment
+	HostInfo       bool              `json:"host_info"`          // HostInfo
+	NamedLevels    map[string]string `json:"named_levels"`       // NamedLevels, by level name
+	Sinks          []SinkConfig      `json:"sinks"`              // Sinks, or Routes when a min_level is set
+}
+
+// SinkConfig describes a sink in a configuration file
+type SinkConfig struct {
+	Type     string   `json:"type"`      // file, stdout or stderr
+	Path     string   `json:"path"`      // File path (file sinks)
+	MaxSize  ByteSize `json:"max_size"`  // Size at which a file sink rotates (0 never rotates)
+	MinLevel string   `json:"min_level"` // Only entries at or above this level (default: all)
+}
+
+// ByteSize is a size in bytes that also unmarshals from strings like "25MB"
+type ByteSize int64
+
+// UnmarshalJSON accepts a number of bytes or a string with a KB, MB or GB suffix
+func (s *ByteSize) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid size %s", data)
+		}
+		*s = ByteSize(n)
+		return nil
+	}
+	n, err := parseByteSize(str)
+	if err != nil {
+		return err
+	}
+	*s = ByteSize(n)
+	return nil
+}
+
+// Duration is a time.Duration that unmarshals from strings like "720h"
+type Duration time.Duration
+
+// UnmarshalJSON accepts a time.ParseDuration string, or a number of seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		var secs float64
+		if err := json.Unmarshal(data, &secs); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	v, err := time.ParseDuration(str)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %v", str, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config converts the file form into a Config, opening any configured sinks
+func (fc FileConfig) Config() (Config, error) {
+	config := Config{
+		LogPath:       fc.Path,
+		StdoutOnly:    fc.StdoutOnly,
+		BufferSize:    fc.BufferSize,
+		Sync:          fc.Sync,
+		BatchSize:     fc.BatchSize,
+		FlushInterval: time.Duration(fc.FlushInterval),
+		FormatWorkers: fc.FormatWorkers,
+		IsDev:         fc.Dev,
+		Console:       fc.Console,
+		TimeFormat:    fc.TimeFormat,
+		MaxFileSize:   int64(fc.MaxFileSize),
+		Compress:      fc.Compress,
+		MaxAge:        time.Duration(fc.MaxAge),
+		MaxBackups:    fc.MaxBackups,
+		MaxTotalSize:  int64(fc.MaxTotalSize),
+
+		MaxMessageSize:  int(fc.MaxMessageSize),
+		WriteBufferSize: int(fc.WriteBuffer),
+		Preallocate:     int64(fc.Preallocate),
+
+		ReopenOnSignal: fc.ReopenOnSignal,
+		ReopenCheck:    time.Duration(fc.ReopenCheck),
+
+		ServiceName: fc.ServiceName,
+		Environment: fc.Environment,
+		HostInfo:    fc.HostInfo,
+	}
+
+	var err error
+	if fc.KeyEnv != "" {
+		if config.EncryptionKey, err = KeyFromEnv(fc.KeyEnv); err != nil {
+			return Config{}, err
+		}
+	}
+	if fc.Level != "" {
+		if config.Level, err = levelFromName(fc.Level); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if fc.TimingLevel != "" {
+		if config.TimingLevel, err = levelFromName(fc.TimingLevel); err != nil {
+			return Config{}, err
+		}
+	}
+
+	switch strings.ToLower(fc.Format) {
+	case "", "text":
+		config.Format = Text
+	case "json":
+		config.Format = JSON
+	case "logfmt":
+		config.Format = Logfmt
+	default:
+		return Config{}, fmt.Errorf("unknown log format %q", fc.Format)
+	}
+
+	switch strings.ToLower(fc.Color) {
+	case "", "auto":
+		config.Color = ColorAuto
+	case "always":
+		config.Color = ColorAlways
+	case "never":
+		config.Color = ColorNever
+	default:
+		return Config{}, fmt.Errorf("unknown color mode %q", fc.Color)
+	}
+
+	if fc.ColorTheme != "" {
+		if config.ColorTheme, err = ThemeByName(fc.ColorTheme); err != nil {
+			return Config{}, err
+		}
+	}
+
+	switch strings.ToLower(fc.RotateEvery) {
+	case "", "none":
+		config.RotateEvery = RotateNone
+	case "hourly":
+		config.RotateEvery = RotateHourly
+	case "daily":
+		config.RotateEvery = RotateDaily
+	default:
+		return Config{}, fmt.Errorf("unknown rotation %q", fc.RotateEvery)
+	}
+
+	switch strings.ToLower(fc.OversizePolicy) {
+	case "", "truncate":
+		config.OversizePolicy = OversizeTruncate
+	case "split":
+		config.OversizePolicy = OversizeSplit
+	case "drop":
+		config.OversizePolicy = OversizeDrop
+	default:
+		return Config{}, fmt.Errorf("unknown oversize policy %q", fc.OversizePolicy)
+	}
+
+	switch strings.ToLower(fc.ArchiveNaming) {
+	case "", "numbered":
+		config.ArchiveNaming = ArchiveNumbered
+	case "lumberjack":
+		config.ArchiveNaming = ArchiveLumberjack
+	default:
+		return Config{}, fmt.Errorf("unknown archive naming %q", fc.ArchiveNaming)
+	}
+
+	if fc.ConsoleLevel != "" {
+		min, err := levelFromName(fc.ConsoleLevel)
+		if err != nil {
+			return Config{}, err
+		}
+		config.ConsoleLevels = LevelsFrom(min)
+	}
+
+	if len(fc.NamedLevels) > 0 {
+		config.NamedLevels = make(map[string]int, len(fc.NamedLevels))
+		for name, level := range fc.NamedLevels {
+			if config.NamedLevels[name], err = levelFromName(level); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+
+	for i, sc := range fc.Sinks {
+		sink, err := sc.open()
+		if err != nil {
+			closeConfigSinks(config)
+			return Config{}, fmt.Errorf("failed to open sink %d: %v", i+1, err)
+		}
+		if sc.MinLevel == "" {
+			config.Sinks = append(config.Sinks, sink)
+			continue
+		}
+		min, err := levelFromName(sc.MinLevel)
+		if err != nil {
+			sink.Close()
+			closeConfigSinks(config)
+			return Config{}, fmt.Errorf("failed to open sink %d: %v", i+1, err)
+		}
+		config.Routes = append(config.Routes, Route{Levels: LevelsFrom(min), Sink: sink})
+	}
+	return config, nil
+}
+
+// open creates the sink described by sc
+func (sc SinkConfig) open() (Sink, error) {
+	switch strings.ToLower(sc.Type) {
+	case "file":
+		if sc.Path == "" {
+			return nil, fmt.Errorf("file sink needs a path")
+		}
+		return NewFileSink(sc.Path, int64(sc.MaxSize))
+	case "stdout":
+		return nopCloser{os.Stdout}, nil
+	case "stderr":
+		return nopCloser{os.Stderr}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sc.Type)
+	}
+}
+
+// closeConfigSinks closes the sinks opened for a config that failed to load
+func closeConfigSinks(config Config) {
+	for _, sink := range config.Sinks {
+		sink.Close()
+	}
+	for _, r := range config.Routes {
+		r.Sink.Close()
+	}
+}
+
+// nopCloser is a sink over a stream the logger must not close
+type nopCloser struct {
+	*os.File
+}
+
+// Close does nothing
+func (nopCloser) Close() error {
+	return nil
+}
+
+// LoadFile reads a JSON, YAML or TOML configuration file, chosen by extension.
+// YAML and TOML support the subset needed for FileConfig: scalars, one level of
+// nested tables and lists of tables.
+func LoadFile(path string) (FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var raw interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return FileConfig{}, fmt.Errorf("failed to parse config file: %v", err)
+		}
+	case ".yaml", ".yml":
+		if raw, err = parseYAML(string(data)); err != nil {
+			return FileConfig{}, fmt.Errorf("failed to parse config file: %v", err)
+		}
+	case ".toml":
+		if raw, err = parseTOML(string(data)); err != nil {
+			return FileConfig{}, fmt.Errorf("failed to parse config file: %v", err)
+		}
+	default:
+		return FileConfig{}, fmt.Errorf("unsupported config file type %q (use .json, .yaml or .toml)", filepath.Ext(path))
+	}
+
+	// Every format decodes through JSON so they share one schema
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("failed to parse config file: %v", err)
+	}
+	var fc FileConfig
+	if err := json.Unmarshal(normalized, &fc); err != nil {
+		return FileConfig{}, fmt.Errorf("failed to parse config file: %v", err)
+	}
+	return fc, nil
+}
+
+// InitializeFromFile creates the default logger from a JSON, YAML or TOML file
+func InitializeFromFile(path string) error {
+	fc, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	config, err := fc.Config()
+	if err != nil {
+		return err
+	}
+	return Initialize(config)
+}
+
+// InitializeFromEnv creates the default logger from LOG_* environment
+// variables. LOG_CONFIG names a configuration file loaded first; the other
+// variables override its values:
+//
+//	LOG_PATH, LOG_STDOUT_ONLY, LOG_LEVEL, LOG_FORMAT, LOG_BUFFER_SIZE, LOG_DEV,
+//	LOG_CONSOLE, LOG_CONSOLE_LEVEL, LOG_COLOR, LOG_COLOR_THEME,
+//	LOG_SYNC, LOG_BATCH_SIZE, LOG_FLUSH_INTERVAL, LOG_FORMAT_WORKERS, LOG_TIME_FORMAT,
+//	LOG_MAX_FILE_SIZE, LOG_ROTATE_EVERY, LOG_ARCHIVE_NAMING, LOG_COMPRESS,
+//	LOG_MAX_AGE, LOG_MAX_BACKUPS, LOG_MAX_TOTAL_SIZE, LOG_REOPEN_ON_SIGNAL,
+//	LOG_REOPEN_CHECK, LOG_MAX_MESSAGE_SIZE, LOG_OVERSIZE_POLICY,
+//	LOG_WRITE_BUFFER_SIZE, LOG_PREALLOCATE,
+//	LOG_SERVICE_NAME, LOG_ENVIRONMENT, LOG_HOST_INFO,
+//	LOG_NAMED_LEVELS (e.g. "db=debug,http=warn"),
+//	LOG_ENCRYPTION_KEY (hex or base64, see KeyFromEnv)
+func InitializeFromEnv() error {
+	fc, err := EnvConfig()
+	if err != nil {
+		return err
+	}
+	config, err := fc.Config()
+	if err != nil {
+		return err
+	}
+	return Initialize(config)
+}
+
+// EnvConfig returns the FileConfig described by the LOG_* environment variables
+func EnvConfig() (FileConfig, error) {
+	var fc FileConfig
+	if path := os.Getenv("LOG_CONFIG"); path != "" {
+		var err error
+		if fc, err = LoadFile(path); err != nil {
+			return FileConfig{}, err
+		}
+	}
+
+	var err error
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = v
+		}
+	}
+	num := func(name string, dst *int) {
+		if v, ok := os.LookupEnv(name); ok && err == nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				err = fmt.Errorf("invalid %s: %v", name, err)
+			}
+		}
+	}
+	flag := func(name string, dst *bool) {
+		if v, ok := os.LookupEnv(name); ok && err == nil {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				err = fmt.Errorf("invalid %s: %v", name, err)
+			}
+		}
+	}
+
+	str("LOG_PATH", &fc.Path)
+	str("LOG_LEVEL", &fc.Level)
+	str("LOG_CONSOLE_LEVEL", &fc.ConsoleLevel)
+	str("LOG_FORMAT", &fc.Format)
+	str("LOG_COLOR", &fc.Color)
+	str("LOG_COLOR_THEME", &fc.ColorTheme)
+	str("LOG_TIME_FORMAT", &fc.TimeFormat)
+	str("LOG_ROTATE_EVERY", &fc.RotateEvery)
+	str("LOG_ARCHIVE_NAMING", &fc.ArchiveNaming)
+	str("LOG_OVERSIZE_POLICY", &fc.OversizePolicy)
+	str("LOG_TIMING_LEVEL", &fc.TimingLevel)
+	str("LOG_SERVICE_NAME", &fc.ServiceName)
+	str("LOG_ENVIRONMENT", &fc.Environment)
+	if _, ok := os.LookupEnv("LOG_ENCRYPTION_KEY"); ok {
+		fc.KeyEnv = "LOG_ENCRYPTION_KEY"
+	}
+	num("LOG_BUFFER_SIZE", &fc.BufferSize)
+	num("LOG_BATCH_SIZE", &fc.BatchSize)
+	num("LOG_FORMAT_WORKERS", &fc.FormatWorkers)
+	num("LOG_MAX_BACKUPS", &fc.MaxBackups)
+	flag("LOG_DEV", &fc.Dev)
+	flag("LOG_CONSOLE", &fc.Console)
+	flag("LOG_STDOUT_ONLY", &fc.StdoutOnly)
+	flag("LOG_SYNC", &fc.Sync)
+	flag("LOG_COMPRESS", &fc.Compress)
+	flag("LOG_REOPEN_ON_SIGNAL", &fc.ReopenOnSignal)
+	flag("LOG_HOST_INFO", &fc.HostInfo)
+	if err != nil {
+		return FileConfig{}, err
+	}
+
+	if v, ok := os.LookupEnv("LOG_MAX_FILE_SIZE"); ok {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_MAX_FILE_SIZE: %v", err)
+		}
+		fc.MaxFileSize = ByteSize(n)
+	}
+	if v, ok := os.LookupEnv("LOG_MAX_TOTAL_SIZE"); ok {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_MAX_TOTAL_SIZE: %v", err)
+		}
+		fc.MaxTotalSize = ByteSize(n)
+	}
+	if v, ok := os.LookupEnv("LOG_MAX_MESSAGE_SIZE"); ok {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_MAX_MESSAGE_SIZE: %v", err)
+		}
+		fc.MaxMessageSize = ByteSize(n)
+	}
+	if v, ok := os.LookupEnv("LOG_WRITE_BUFFER_SIZE"); ok {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_WRITE_BUFFER_SIZE: %v", err)
+		}
+		fc.WriteBuffer = ByteSize(n)
+	}
+	if v, ok := os.LookupEnv("LOG_PREALLOCATE"); ok {
+		n, err := parseByteSize(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_PREALLOCATE: %v", err)
+		}
+		fc.Preallocate = ByteSize(n)
+	}
+	if v, ok := os.LookupEnv("LOG_FLUSH_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_FLUSH_INTERVAL: %v", err)
+		}
+		fc.FlushInterval = Duration(d)
+	}
+	if v, ok := os.LookupEnv("LOG_MAX_AGE"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_MAX_AGE: %v", err)
+		}
+		fc.MaxAge = Duration(d)
+	}
+	if v, ok := os.LookupEnv("LOG_REOPEN_CHECK"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_REOPEN_CHECK: %v", err)
+		}
+		fc.ReopenCheck = Duration(d)
+	}
+	if v, ok := os.LookupEnv("LOG_NAMED_LEVELS"); ok {
+		levels, err := parseNamedLevels(v)
+		if err != nil {
+			return FileConfig{}, fmt.Errorf("invalid LOG_NAMED_LEVELS: %v", err)
+		}
+		if fc.NamedLevels == nil {
+			fc.NamedLevels = make(map[string]string, len(levels))
+		}
+		for name, level := range levels {
+			fc.NamedLevels[name] = LevelName(level)
+		}
+	}
+	return fc, nil
+}
+
+// parseByteSize parses "1048576", "512KB", "25MB" or "1GB" (powers of 1024)
+func parseByteSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(str, unit.suffix) {
+			str, mult = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix)), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
+// parseYAML parses the YAML subset used by configuration files: "key: value"
+// scalars, nested mappings and lists of mappings, distinguished by indentation
+func parseYAML(src string) (interface{}, error) {
+	type line struct {
+		indent int
+		text   string
+		num    int
+	}
+	var lines []line
+	for i, raw := range strings.Split(src, "\n") {
+		text := stripComment(strings.TrimRight(raw, " \t\r"))
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		lines = append(lines, line{indent: len(text) - len(trimmed), text: trimmed, num: i + 1})
+	}
+
+	pos := 0
+	var parseBlock func(indent int) (interface{}, error)
+	parseBlock = func(indent int) (interface{}, error) {
+		if pos < len(lines) && strings.HasPrefix(lines[pos].text, "- ") {
+			var list []interface{}
+			for pos < len(lines) && lines[pos].indent == indent && strings.HasPrefix(lines[pos].text, "- ") {
+				// "- key: value" starts a mapping whose remaining keys are indented past the dash
+				item := lines[pos].text[2:]
+				if !strings.Contains(item, ":") || strings.HasPrefix(strings.TrimSpace(item), `"`) {
+					list = append(list, yamlScalar(item))
+					pos++
+					continue
+				}
+				lines[pos] = line{indent: indent + 2, text: strings.TrimLeft(item, " "), num: lines[pos].num}
+				value, err := parseBlock(indent + 2)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			return list, nil
+		}
+
+		m := make(map[string]interface{})
+		for pos < len(lines) && lines[pos].indent == indent {
+			l := lines[pos]
+			if strings.HasPrefix(l.text, "- ") {
+				break
+			}
+			key, value, ok := strings.Cut(l.text, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key: value", l.num)
+			}
+			key = strings.Trim(strings.TrimSpace(key), `"'`)
+			value = strings.TrimSpace(value)
+			pos++
+			if value != "" {
+				m[key] = yamlScalar(value)
+				continue
+			}
+			if pos < len(lines) && (lines[pos].indent > indent || (lines[pos].indent == indent && strings.HasPrefix(lines[pos].text, "- "))) {
+				child, err := parseBlock(lines[pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = child
+			} else {
+				m[key] = nil
+			}
+		}
+		if pos < len(lines) && lines[pos].indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", lines[pos].num)
+		}
+		return m, nil
+	}
+
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	return parseBlock(lines[0].indent)
+}
+
+// yamlScalar converts a YAML scalar into a bool, number or string
+func yamlScalar(s string) interface{} {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		if s[0] == '"' {
+			if unquoted, err := strconv.Unquote(s); err == nil {
+				return unquoted
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	switch s {
+	case "true", "yes", "on":
+		return true
+	case "false", "no", "off":
+		return false
+	case "null", "~":
+		return nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// parseTOML parses the TOML subset used by configuration files: key = value
+// pairs, [tables] and [[arrays of tables]]
+func parseTOML(src string) (interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	for i, raw := range strings.Split(src, "\n") {
+		text := strings.TrimSpace(stripComment(raw))
+		if text == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "[[") && strings.HasSuffix(text, "]]"):
+			name := strings.TrimSpace(text[2 : len(text)-2])
+			list, _ := root[name].([]interface{})
+			current = make(map[string]interface{})
+			root[name] = append(list, current)
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			table, ok := root[name].(map[string]interface{})
+			if !ok {
+				table = make(map[string]interface{})
+				root[name] = table
+			}
+			current = table
+		default:
+			key, value, ok := strings.Cut(text, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key = value", i+1)
+			}
+			v, err := tomlValue(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			current[strings.Trim(strings.TrimSpace(key), `"`)] = v
+		}
+	}
+	return root, nil
+}
+
+// tomlValue converts a TOML value into a bool, number, string or list
+func tomlValue(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2:
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]"):
+		var list []interface{}
+		for _, item := range strings.Split(s[1:len(s)-1], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			v, err := tomlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", s)
+}
+
+// stripComment removes a trailing # comment outside of quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return s[:i]
+		}
+	}
+	return s
+}
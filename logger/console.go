@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// writeConsole writes rendered lines in console mode: ERROR and above to
+// stderr, everything else to stdout. The caller must hold l.mu.
+func (l *Logger) writeConsole(out []byte, ends []int, entries []*logEntry) {
+	var stdout, stderr []byte
+	start := 0
+	for i, entry := range entries {
+		line := out[start:ends[i]]
+		start = ends[i]
+		if l.fileLevels != nil && !l.fileLevels[entry.level] {
+			continue
+		}
+		if !levelBelow(entry.level, ERROR) {
+			stderr = append(stderr, line...)
+		} else {
+			stdout = append(stdout, line...)
+		}
+	}
+
+	if len(stdout) > 0 {
+		l.writeStream(os.Stdout, stdout)
+	}
+	if len(stderr) > 0 {
+		l.writeStream(os.Stderr, stderr)
+	}
+}
+
+// writeStream writes to a standard stream, falling back like a file write
+// when it fails. The caller must hold l.mu.
+func (l *Logger) writeStream(w io.Writer, p []byte) {
+	n, err := w.Write(p)
+	if err != nil {
+		l.writeFallback(p[n:], err)
+		return
+	}
+	if l.stats.fallbackLevel.Load() != 0 {
+		l.stats.fallbackLevel.Store(0)
+	}
+	l.stats.bytesWritten.Add(uint64(n))
+}
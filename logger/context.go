@@ -0,0 +1,93 @@
+package logger
+
+import "context"
+
+// contextKey namespaces values this package stores in a context
+type contextKey int
+
+const (
+	fieldsKey contextKey = iota // Request-scoped []Field
+	loggerKey                   // *Logger stored with NewContext
+)
+
+// ContextWithFields returns a copy of ctx carrying fields in addition to any
+// already present. A key set again replaces the earlier value.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	added := fields.sorted()
+	existing := contextFields(ctx)
+
+	merged := make([]Field, 0, len(existing)+len(added))
+	for _, f := range existing {
+		if _, replaced := fields[f.Key]; !replaced {
+			merged = append(merged, f)
+		}
+	}
+	merged = append(merged, added...)
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// ContextExtractor derives fields from a context, such as the trace and span
+// IDs of a tracing library's span (see the otellog module)
+type ContextExtractor func(ctx context.Context) []Field
+
+// contextFields returns the request-scoped fields of ctx followed by those of
+// the configured extractors
+func (l *Logger) contextFields(ctx context.Context) []Field {
+	fields := contextFields(ctx)
+	if ctx == nil || len(l.extractors) == 0 {
+		return fields
+	}
+	fields = fields[:len(fields):len(fields)] // Never append into the stored slice
+	for _, extract := range l.extractors {
+		fields = append(fields, extract(ctx)...)
+	}
+	return fields
+}
+
+// contextFields returns the request-scoped fields stored in ctx
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey).([]Field)
+	return fields
+}
+
+// NewContext returns a copy of ctx carrying l, retrieved later with FromContext
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext (or the default
+// logger) with the request-scoped fields of ctx attached
+func FromContext(ctx context.Context) *Logger {
+	l, _ := ctx.Value(loggerKey).(*Logger)
+	if l == nil {
+		l = defaultLogger
+	}
+	return l.WithContext(ctx)
+}
+
+// WithContext returns a child logger that attaches the request-scoped fields
+// of ctx, and those of Config.ContextExtractors, to every entry. Hooks see ctx
+// as Entry.Context. The child shares the parent's file and settings.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if l == nil || ctx == nil {
+		return l
+	}
+	child := l.withFields(l.contextFields(ctx))
+	child.ctx = ctx
+	return child
+}
+
+// WithContext returns a child of the default logger carrying the fields of ctx
+func WithContext(ctx context.Context) *Logger {
+	return defaultLogger.WithContext(ctx)
+}
+
+// withFields returns a child logger with fields bound after the parent's own
+func (l *Logger) withFields(fields []Field) *Logger {
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(append(merged, l.fields...), fields...)
+	return &Logger{loggerCore: l.loggerCore, fields: merged, name: l.name, named: l.named, callerSkip: l.callerSkip, prefix: l.prefix, ctx: l.ctx}
+}
@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelDef describes a custom level, or new name and color for a built-in
+// level, for RegisterLevel
+type LevelDef struct {
+	Level    int     // Number passed to Log, LogKV and Config.Level, e.g. 10
+	Name     string  // Name written in the output, e.g. "NOTICE"
+	Color    string  // ANSI escape sequence for console output, e.g. "\033[36m" (default: uncolored)
+	Severity float64 // Position among the levels; built-in levels rank by their value, so 1.5 sits between INFO and WARN
+}
+
+// levelTable holds the names, colors and severities of the levels. It is
+// replaced as a whole by RegisterLevel, so loggers read it without a lock.
+type levelTable struct {
+	names    map[int]string
+	colors   map[int]string
+	severity map[int]float64 // Custom levels only; built-in levels rank by their value
+}
+
+// currentLevels is the level table in use
+var currentLevels atomic.Pointer[levelTable]
+
+// levelsMu serializes RegisterLevel
+var levelsMu sync.Mutex
+
+func init() {
+	currentLevels.Store(&levelTable{names: levelNames, colors: levelColors, severity: map[int]float64{}})
+}
+
+// RegisterLevel adds a level with its own name, color and severity, e.g.
+// NOTICE between INFO and WARN or AUDIT above ERROR, or renames and recolors
+// a built-in level (its Severity is then ignored). Custom levels are
+// filtered, routed and mapped onto sinks by their severity, as the built-in
+// level at or below it (see BaseLevel). Register levels at startup, before
+// configuration naming them is parsed:
+//
+//	const NOTICE = 10
+//
+//	func init() {
+//	    logger.RegisterLevel(logger.LevelDef{Level: NOTICE, Name: "NOTICE", Color: "\033[36m", Severity: 1.5})
+//	}
+//
+//	logger.Log(NOTICE, "certificate expires in %d days", days)
+func RegisterLevel(def LevelDef) error {
+	name := strings.ToUpper(strings.TrimSpace(def.Name))
+	if name == "" || strings.ContainsAny(name, " \t\r\n[]=\"") {
+		return fmt.Errorf("invalid level name %q", def.Name)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	old := currentLevels.Load()
+	for level, n := range old.names {
+		if n == name && level != def.Level {
+			return fmt.Errorf("level name %q is already used by level %d", name, level)
+		}
+	}
+
+	// Copy on write so loggers keep reading the old table meanwhile
+	t := &levelTable{
+		names:    make(map[int]string, len(old.names)+1),
+		colors:   make(map[int]string, len(old.colors)+1),
+		severity: make(map[int]float64, len(old.severity)+1),
+	}
+	for level, n := range old.names {
+		t.names[level] = n
+	}
+	for level, c := range old.colors {
+		t.colors[level] = c
+	}
+	for level, s := range old.severity {
+		t.severity[level] = s
+	}
+	t.names[def.Level] = name
+	t.colors[def.Level] = def.Color
+	if !isBuiltinLevel(def.Level) {
+		t.severity[def.Level] = def.Severity
+	}
+	currentLevels.Store(t)
+	return nil
+}
+
+// isBuiltinLevel reports whether level is one of TRACE to FATAL
+func isBuiltinLevel(level int) bool {
+	return level >= TRACE && level <= FATAL
+}
+
+// knownLevel reports whether level is built in or registered
+func knownLevel(level int) bool {
+	_, ok := currentLevels.Load().names[level]
+	return ok
+}
+
+// levelColor returns the console color of a level
+func levelColor(level int) string {
+	return currentLevels.Load().colors[level]
+}
+
+// LevelSeverity returns the position of a level among the others: a built-in
+// level's value, or a custom level's Severity
+func LevelSeverity(level int) float64 {
+	if !isBuiltinLevel(level) {
+		if s, ok := currentLevels.Load().severity[level]; ok {
+			return s
+		}
+	}
+	return float64(level)
+}
+
+// BaseLevel returns the most severe built-in level at or below level, e.g.
+// INFO for a NOTICE level of severity 1.5, so sinks can map custom levels
+// onto the severities of their protocol
+func BaseLevel(level int) int {
+	if isBuiltinLevel(level) {
+		return level
+	}
+	s := LevelSeverity(level)
+	for base := FATAL; base > TRACE; base-- {
+		if float64(base) <= s {
+			return base
+		}
+	}
+	return TRACE
+}
+
+// levelBelow reports whether level ranks below min
+func levelBelow(level, min int) bool {
+	if isBuiltinLevel(level) && isBuiltinLevel(min) {
+		return level < min
+	}
+	return LevelSeverity(level) < LevelSeverity(min)
+}
+
+// allLevels returns the built-in and registered levels, least severe first
+func allLevels() []int {
+	names := currentLevels.Load().names
+	all := make([]int, 0, len(names))
+	for level := range names {
+		all = append(all, level)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		si, sj := LevelSeverity(all[i]), LevelSeverity(all[j])
+		if si != sj {
+			return si < sj
+		}
+		return all[i] < all[j]
+	})
+	return all
+}
+
+// Log logs a formatted message at any level, including custom levels. At
+// FATAL it closes the logger and exits like Fatal; PANIC entries are flushed
+// but do not panic.
+func (l *Logger) Log(level int, format string, args ...interface{}) {
+	l.log(level, checkedFlags(level), format, args...)
+}
+
+// LogKV logs a message with structured fields at any level
+func (l *Logger) LogKV(level int, msg string, fields Fields) {
+	l.logFields(level, checkedFlags(level), msg, fields.sorted())
+}
+
+// Log logs a formatted message at any level with the default logger
+func Log(level int, format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(level, checkedFlags(level), format, args...)
+	}
+}
+
+// LogKV logs a message with structured fields at any level with the default logger
+func LogKV(level int, msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(level, checkedFlags(level), msg, fields.sorted())
+	}
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// DedupKey selects what makes consecutive entries identical
+type DedupKey int
+
+// Deduplication keys
+const (
+	DedupMessage       DedupKey = iota // Same level, message and fields (default)
+	DedupMessageCaller                 // Also the same calling file and line
+)
+
+// DedupConfig collapses runs of identical consecutive entries, as syslog
+// does: the first entry is written, the repeats are counted, and a
+// "last message repeated N times" entry follows when a different entry
+// arrives or the window ends
+type DedupConfig struct {
+	Window time.Duration // Longest run collapsed into one summary; a repeat after it is written again (default: 10s)
+	Key    DedupKey      // What must match: DedupMessage or DedupMessageCaller (default: DedupMessage)
+}
+
+// deduper tracks the current run of repeats. It is used under batchMu.
+type deduper struct {
+	window int64
+	key    DedupKey
+	last   logEntry    // Last entry written, with its own copy of the message
+	active bool        // Whether last is set
+	start  int64       // Timestamp of last, when the run began
+	count  int         // Repeats collapsed since
+	lastAt int64       // Timestamp of the latest repeat
+	out    []*logEntry // Filtered batch, reused between batches
+}
+
+// newDeduper returns a deduper for cfg, or nil when cfg is nil
+func newDeduper(cfg *DedupConfig) *deduper {
+	if cfg == nil {
+		return nil
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	return &deduper{window: int64(window), key: cfg.Key}
+}
+
+// filter removes repeats of the previous entry from batch, returning them to
+// the pool, and inserts summaries where runs end. Priority entries are never
+// collapsed; they end the current run. It returns the entries to write and
+// the number removed.
+func (d *deduper) filter(batch []*logEntry) ([]*logEntry, int) {
+	out := d.out[:0]
+	removed := 0
+	for _, e := range batch {
+		if e.flags&flagPriority != 0 {
+			if s := d.summary(); s != nil {
+				out = append(out, s)
+			}
+			d.active = false
+			out = append(out, e)
+			continue
+		}
+		if d.active && e.timestamp-d.start < d.window && d.same(e) {
+			d.count++
+			d.lastAt = e.timestamp
+			releaseEntry(e)
+			removed++
+			continue
+		}
+		if s := d.summary(); s != nil {
+			out = append(out, s)
+		}
+		d.remember(e)
+		out = append(out, e)
+	}
+	d.out = out
+	return out, removed
+}
+
+// same reports whether e repeats the last entry written
+func (d *deduper) same(e *logEntry) bool {
+	if e.level != d.last.level || !bytes.Equal(e.msg, d.last.msg) {
+		return false
+	}
+	if d.key == DedupMessageCaller && (e.file != d.last.file || e.line != d.last.line) {
+		return false
+	}
+	return sameFields(e.fields, d.last.fields)
+}
+
+// remember makes e the start of a new run
+func (d *deduper) remember(e *logEntry) {
+	d.last.level = e.level
+	d.last.msg = append(d.last.msg[:0], e.msg...)
+	d.last.fields = e.fields
+	d.last.pc, d.last.file, d.last.line = e.pc, e.file, e.line
+	d.start = e.timestamp
+	d.active = true
+}
+
+// summary returns the entry reporting the current run of repeats and ends
+// the run, or nil when nothing was collapsed
+func (d *deduper) summary() *logEntry {
+	if d.count == 0 {
+		return nil
+	}
+	s := entryPool.Get().(*logEntry)
+	s.level = d.last.level
+	s.flags = 0
+	s.msg = fmt.Appendf(s.msg[:0], "last message repeated %d times", d.count)
+	s.fields = []Field{{Key: "repeated", Value: d.count}}
+	s.pc, s.file, s.line = d.last.pc, d.last.file, d.last.line
+	s.timestamp = d.lastAt
+	d.count = 0
+	return s
+}
+
+// due returns when the summary of the current run should be written, or 0
+// when there are no repeats
+func (d *deduper) due() int64 {
+	if d.count == 0 {
+		return 0
+	}
+	return d.start + d.window
+}
+
+// sameFields compares field lists. Values are compared only for the common
+// comparable types; anything else counts as different.
+func sameFields(a, b []Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key {
+			return false
+		}
+		switch va := a[i].Value.(type) {
+		case nil:
+			if b[i].Value != nil {
+				return false
+			}
+		case string, bool, int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration:
+			if va != b[i].Value {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// dedupDue returns when the pending summary of repeats is due, or 0
+func (l *Logger) dedupDue() int64 {
+	l.batchMu.Lock()
+	defer l.batchMu.Unlock()
+	return l.dedup.due()
+}
+
+// flushDedup writes the summary of the current run of repeats if its window
+// has passed, or regardless when force is set. It returns when a summary
+// still pending is due, or 0.
+func (l *Logger) flushDedup(force bool) int64 {
+	l.batchMu.Lock()
+	defer l.batchMu.Unlock()
+
+	due := l.dedup.due()
+	if due == 0 || (!force && time.Now().UnixNano() < due) {
+		return due
+	}
+	s := l.dedup.summary()
+	batch := [1]*logEntry{s}
+	l.writeBatch(batch[:])
+	releaseEntry(s)
+	return 0
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultDropReport is how often entries dropped on a full buffer are summarized
+const defaultDropReport = 10 * time.Second
+
+// watchDrops logs a summary of entries dropped on a full buffer every
+// interval, so the loss shows up in the log itself
+func (l *Logger) watchDrops(interval time.Duration) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := make(map[int]uint64)
+		for {
+			select {
+			case <-ticker.C:
+				l.reportDrops(interval, last)
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
+
+// reportDrops logs one WARN entry when entries were dropped since the counts
+// in last, e.g. "Dropped 1234 entries in the last 10s because the log buffer
+// was full" with the count per level as fields, and updates last. The
+// summary waits for buffer space rather than being dropped itself.
+func (l *Logger) reportDrops(interval time.Duration, last map[int]uint64) {
+	var total uint64
+	var fields []Field
+	count := func(level int, n uint64) {
+		if d := n - last[level]; d > 0 {
+			total += d
+			fields = append(fields, Field{Key: strings.ToLower(LevelName(level)), Value: d})
+		}
+		last[level] = n
+	}
+	for i := range l.stats.droppedLevel {
+		count(TRACE+i, l.stats.droppedLevel[i].Load())
+	}
+	l.stats.custom.Range(func(level, c interface{}) bool {
+		count(level.(int), c.(*levelCounts).dropped.Load())
+		return true
+	})
+	if total == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("Dropped %d entries in the last %s because the log buffer was full", total, interval)
+	fields = append([]Field{{Key: "dropped", Value: total}}, fields...)
+	l.dispatch(WARN, flagPriority, msg, fields, 0, "", 0)
+}
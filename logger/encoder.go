@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// The encoders below write the common value types straight into the output
+// buffer. The writer reuses that buffer between batches, so in the steady
+// state rendering an entry allocates nothing; other types go through fmt or
+// encoding/json as before.
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONValue writes v as JSON without HTML escaping, falling back to its
+// string form when it cannot be marshaled
+func appendJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendJSONString(buf, val)
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
+	case int:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int8:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int16:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint8:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint16:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), val, 10))
+	case float64:
+		appendJSONFloat(buf, val, 64)
+	case float32:
+		appendJSONFloat(buf, float64(val), 32)
+	case time.Time:
+		if y := val.Year(); y < 0 || y > 9999 {
+			appendJSONReflect(buf, v)
+			return
+		}
+		buf.WriteByte('"')
+		buf.Write(val.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+		buf.WriteByte('"')
+	default:
+		appendJSONReflect(buf, v)
+	}
+}
+
+// appendJSONReflect marshals v with encoding/json
+func appendJSONReflect(buf *bytes.Buffer, v interface{}) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	start := buf.Len()
+	if err := enc.Encode(v); err != nil {
+		buf.Truncate(start)
+		enc.Encode(formatValue(v))
+	}
+	// Encode terminates each value with a newline
+	buf.Truncate(buf.Len() - 1)
+}
+
+// appendJSONFloat formats a float the way encoding/json does; NaN and
+// infinities, which JSON cannot represent, are written as strings
+func appendJSONFloat(buf *bytes.Buffer, f float64, bits int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bits))
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b := strconv.AppendFloat(buf.AvailableBuffer(), f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	buf.Write(b)
+}
+
+// appendJSONString writes s as a quoted JSON string without HTML escaping
+func appendJSONString[S string | []byte](buf *bytes.Buffer, s S) {
+	b := append(buf.AvailableBuffer(), '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := decodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 break JavaScript parsers; encoding/json escapes them too
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	buf.Write(append(b, '"'))
+}
+
+// appendTextValue writes a field value for text and logfmt output, quoting
+// it when it would be ambiguous unquoted
+func appendTextValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case string:
+		appendQuoted(buf, val)
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
+	case int:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(val), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), val, 10))
+	case float64:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'g', -1, 64))
+	case float32:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), float64(val), 'g', -1, 32))
+	default:
+		appendQuoted(buf, formatValue(v))
+	}
+}
+
+// needsQuote reports whether a rendered value would be ambiguous unquoted
+func needsQuote[S string | []byte](s S) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '=', '"', '\t', '\r', '\n':
+			return true
+		}
+	}
+	return false
+}
+
+// appendQuoted writes s, quoted as strconv.Quote does when it would be
+// ambiguous unquoted
+func appendQuoted[S string | []byte](buf *bytes.Buffer, s S) {
+	if !needsQuote(s) {
+		buf.Write(append(buf.AvailableBuffer(), s...))
+		return
+	}
+
+	b := append(buf.AvailableBuffer(), '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c >= 0x20 && c < 0x7f:
+				b = append(b, c)
+			case c == '\a':
+				b = append(b, '\\', 'a')
+			case c == '\b':
+				b = append(b, '\\', 'b')
+			case c == '\f':
+				b = append(b, '\\', 'f')
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c == '\v':
+				b = append(b, '\\', 'v')
+			default:
+				b = append(b, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			continue
+		}
+
+		r, size := decodeRune(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+		case strconv.IsPrint(r):
+			b = append(b, s[i:i+size]...)
+		case r < 0x10000:
+			b = append(b, '\\', 'u')
+			for shift := 12; shift >= 0; shift -= 4 {
+				b = append(b, hexDigits[r>>uint(shift)&0xf])
+			}
+		default:
+			b = append(b, '\\', 'U')
+			for shift := 28; shift >= 0; shift -= 4 {
+				b = append(b, hexDigits[r>>uint(shift)&0xf])
+			}
+		}
+		i += size
+	}
+	buf.Write(append(b, '"'))
+}
+
+// decodeRune decodes the first rune of s without converting it
+func decodeRune[S string | []byte](s S) (rune, int) {
+	var p [utf8.UTFMax]byte
+	n := copy(p[:], s)
+	return utf8.DecodeRune(p[:n])
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// benchEntry returns a typical entry with a few structured fields
+func benchEntry() *logEntry {
+	_, file, line, _ := runtime.Caller(0)
+	return &logEntry{
+		level:     INFO,
+		msg:       []byte("request served"),
+		fields:    []Field{{Key: "method", Value: "GET"}, {Key: "status", Value: 200}, {Key: "latency", Value: 12.5}, {Key: "cached", Value: true}},
+		file:      file,
+		line:      line,
+		timestamp: time.Now().UnixNano(),
+	}
+}
+
+// BenchmarkAppendEntry renders one entry per format into a reused buffer, as
+// the writer does; the steady state should not allocate
+func BenchmarkAppendEntry(b *testing.B) {
+	for _, bm := range []struct {
+		name   string
+		format Format
+	}{{"text", Text}, {"json", JSON}, {"logfmt", Logfmt}} {
+		b.Run(bm.name, func(b *testing.B) {
+			l := newTestLogger(b, Config{Format: bm.format})
+			entry := benchEntry()
+			caller := l.formatCaller(entry)
+			var buf bytes.Buffer
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				l.appendEntry(&buf, entry, caller)
+			}
+		})
+	}
+}
+
+// BenchmarkRenderEntries renders a batch the way writeBatch does before
+// writing it to the file
+func BenchmarkRenderEntries(b *testing.B) {
+	l := newTestLogger(b, Config{})
+	entries := make([]*logEntry, 100)
+	for i := range entries {
+		entries[i] = benchEntry()
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 64*1024))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		l.renderEntries(buf, entries, nil, false)
+	}
+}
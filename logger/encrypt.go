@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// encMagic starts every encrypted record
+const encMagic = "LGE1"
+
+// maxEncRecord bounds the record length accepted by Decrypt
+const maxEncRecord = 1 << 30
+
+// encrypter seals batches written to the log file with AES-GCM. Each batch
+// becomes a record: magic, 4-byte big-endian length, 12-byte nonce, ciphertext.
+type encrypter struct {
+	aead cipher.AEAD
+}
+
+// newEncrypter creates an encrypter for a 16, 24 or 32 byte key
+func newEncrypter(key []byte) (*encrypter, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypter{aead: aead}, nil
+}
+
+// newGCM returns AES-GCM for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts p into a self-contained record
+func (e *encrypter) seal(p []byte) []byte {
+	nonceSize := e.aead.NonceSize()
+	record := make([]byte, len(encMagic)+4+nonceSize, len(encMagic)+4+nonceSize+len(p)+e.aead.Overhead())
+	copy(record, encMagic)
+	nonce := record[len(encMagic)+4:]
+	rand.Read(nonce)
+	record = e.aead.Seal(record, nonce, p, []byte(encMagic))
+	binary.BigEndian.PutUint32(record[len(encMagic):], uint32(len(record)-len(encMagic)-4))
+	return record
+}
+
+// Decrypt writes the plaintext of an encrypted log stream to w
+func Decrypt(r io.Reader, w io.Writer, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	header := make([]byte, len(encMagic)+4)
+	for record := 1; ; record++ {
+		if _, err := io.ReadFull(br, header); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read record %d: %v", record, err)
+		}
+		if string(header[:len(encMagic)]) != encMagic {
+			return fmt.Errorf("failed to read record %d: not an encrypted log", record)
+		}
+		size := binary.BigEndian.Uint32(header[len(encMagic):])
+		if size < uint32(aead.NonceSize()+aead.Overhead()) || size > maxEncRecord {
+			return fmt.Errorf("failed to read record %d: invalid length %d", record, size)
+		}
+
+		body := make([]byte, size)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return fmt.Errorf("failed to read record %d: %v", record, err)
+		}
+		nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+		plain, err := aead.Open(ciphertext[:0], nonce, ciphertext, []byte(encMagic))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt record %d: wrong key or corrupted data", record)
+		}
+		if _, err := w.Write(plain); err != nil {
+			return fmt.Errorf("failed to write decrypted log: %v", err)
+		}
+	}
+}
+
+// DecryptFile writes the plaintext of an encrypted log file or compressed
+// archive (.gz) to w
+func DecryptFile(path string, key []byte, w io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to open compressed log file: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return Decrypt(r, w, key)
+}
+
+// KeyFromEnv reads an encryption key from an environment variable holding
+// 16, 24 or 32 bytes encoded as hex or base64
+func KeyFromEnv(name string) ([]byte, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil, fmt.Errorf("encryption key variable %s is not set", name)
+	}
+	key, err := hex.DecodeString(value)
+	if err != nil {
+		if key, err = base64.StdEncoding.DecodeString(value); err != nil {
+			return nil, errors.New("encryption key must be hex or base64 encoded")
+		}
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxErrorChain bounds the number of causes recorded by Err
+const maxErrorChain = 32
+
+// ErrorCause is one error in a chain recorded by Err
+type ErrorCause struct {
+	Message string `json:"msg"`             // Error() of this error
+	Type    string `json:"type"`            // Go type, e.g. *fs.PathError
+	Stack   string `json:"stack,omitempty"` // Stack recorded by the error itself (pkg/errors and compatible)
+}
+
+// ErrorChain is the value of a field created by Err: the error followed by
+// every error it wraps. JSON output renders it as an array; text output
+// writes the message inline and the chain as an indented block below the line.
+type ErrorChain []ErrorCause
+
+// String returns the message of the outermost error
+func (c ErrorChain) String() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0].Message
+}
+
+// Err returns an "error" field recording err and its full Unwrap chain,
+// including errors combined with errors.Join and stack traces carried by
+// errors from github.com/pkg/errors or any type with a StackTrace method
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Value: nil}
+	}
+	var chain ErrorChain
+	walkErrors(err, &chain)
+	return Field{Key: "error", Value: chain}
+}
+
+// walkErrors appends err and everything it wraps, depth first
+func walkErrors(err error, chain *ErrorChain) {
+	for err != nil && len(*chain) < maxErrorChain {
+		*chain = append(*chain, ErrorCause{
+			Message: err.Error(),
+			Type:    fmt.Sprintf("%T", err),
+			Stack:   errorStack(err),
+		})
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range multi.Unwrap() {
+				walkErrors(e, chain)
+			}
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// errorStack renders the stack of errors with a StackTrace method returning
+// program counters, as pkg/errors does, without depending on those packages
+func errorStack(err error) string {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+	out := m.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr {
+		return ""
+	}
+
+	pcs := make([]uintptr, out.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(out.Index(i).Uint())
+	}
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			buf.WriteString(frame.Function)
+			buf.WriteString("\n\t")
+			buf.WriteString(frame.File)
+			buf.WriteByte(':')
+			buf.WriteString(strconv.Itoa(frame.Line))
+			buf.WriteByte('\n')
+		}
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
+
+// appendErrorBlocks writes the chain of every ErrorChain field with more than
+// the message to show as indented lines following a text entry
+func appendErrorBlocks(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		chain, ok := f.Value.(ErrorChain)
+		if !ok || (len(chain) == 1 && chain[0].Stack == "") {
+			continue
+		}
+		fmt.Fprintf(buf, "    %s chain:\n", f.Key)
+		for i, cause := range chain {
+			// Joined errors have multi-line messages; keep one line per cause
+			fmt.Fprintf(buf, "      [%d] %s: %s\n", i, cause.Type, strings.ReplaceAll(cause.Message, "\n", "; "))
+			for _, line := range strings.Split(strings.TrimRight(cause.Stack, "\n"), "\n") {
+				if line != "" {
+					buf.WriteString("          ")
+					buf.WriteString(strings.Replace(line, "\t", "    ", 1))
+					buf.WriteByte('\n')
+				}
+			}
+		}
+	}
+}
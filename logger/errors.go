@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorOp identifies the operation that failed inside the logger
+type ErrorOp string
+
+// Operations reported to error hooks
+const (
+	OpWrite     ErrorOp = "write"     // Writing to the log file or a fallback sink
+	OpRotate    ErrorOp = "rotate"    // Rotating the log file
+	OpCompress  ErrorOp = "compress"  // Compressing an archive
+	OpRetention ErrorOp = "retention" // Removing old archives
+	OpSink      ErrorOp = "sink"      // Writing to a sink
+	OpHook      ErrorOp = "hook"      // Running an entry hook
+	OpDump      ErrorOp = "dump"      // Dumping the ring buffer
+	OpDrop      ErrorOp = "drop"      // Dropping an entry because the buffer was full
+	OpReload    ErrorOp = "reload"    // Reloading the configuration
+	OpReopen    ErrorOp = "reopen"    // Reopening the log file after external rotation
+	OpUpload    ErrorOp = "upload"    // Uploading an archive
+	OpSpill     ErrorOp = "spill"     // Writing or reading the spill file of OverflowSpill
+)
+
+// OpError is the error passed to error hooks
+type OpError struct {
+	Op  ErrorOp // What failed
+	Err error   // Underlying error
+}
+
+// Error implements error
+func (e *OpError) Error() string {
+	return fmt.Sprintf("logger %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorHook is called with an *OpError whenever the logger fails to write,
+// rotate, compress or deliver entries, or drops an entry. It runs on the
+// goroutine that hit the failure, often the writer, so it must not block or
+// log through the same logger at a level that could fail again.
+type ErrorHook func(err error)
+
+// errBufferFull is reported with OpDrop
+var errBufferFull = errors.New("log buffer full")
+
+// AddErrorHook registers a hook for the logger's operational errors, e.g. to
+// increment a metric or page someone when the disk fills up
+func (l *Logger) AddErrorHook(h ErrorHook) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+
+	// Copy on write so reportError can iterate without holding the lock
+	hooks := make([]ErrorHook, len(l.errHooks), len(l.errHooks)+1)
+	copy(hooks, l.errHooks)
+	l.errHooks = append(hooks, h)
+}
+
+// AddErrorHook registers an error hook on the default logger
+func AddErrorHook(h ErrorHook) {
+	if defaultLogger != nil {
+		defaultLogger.AddErrorHook(h)
+	}
+}
+
+// reportError prints an operational error to the internal error writer and
+// passes it to the error hooks
+func (l *Logger) reportError(op ErrorOp, err error, format string, args ...interface{}) {
+	l.internalError(format, args...)
+	l.notifyError(op, err)
+}
+
+// notifyError passes an operational error to the error hooks only
+func (l *Logger) notifyError(op ErrorOp, err error) {
+	l.hooksMu.RLock()
+	hooks := l.errHooks
+	l.hooksMu.RUnlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+	opErr := &OpError{Op: op, Err: err}
+	for _, h := range hooks {
+		h(opErr)
+	}
+}
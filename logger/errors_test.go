@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInternalErrorWriterGetsWriteFailures(t *testing.T) {
+	var internal bytes.Buffer
+	l := newTestLogger(t, Config{InternalErrorWriter: &internal})
+
+	restore := failPrimary(t, l)
+	defer restore()
+	l.Info("order shipped")
+	l.flush()
+
+	if !strings.Contains(internal.String(), "Error writing to log file") {
+		t.Errorf("InternalErrorWriter got %q, want the write failure", internal.String())
+	}
+}
@@ -0,0 +1,259 @@
+// Package errtrack forwards ERROR and FATAL entries to Sentry or any other
+// error tracker, batched on a background goroutine so logging never waits
+// for the tracker.
+//
+// Any client implementing Tracker can be used. For Sentry:
+//
+//	type sentryTracker struct{}
+//
+//	func (sentryTracker) Send(events []errtrack.Event) error {
+//	    for _, ev := range events {
+//	        sentry.WithScope(func(scope *sentry.Scope) {
+//	            scope.SetLevel(sentry.LevelError)
+//	            scope.SetExtras(ev.FieldMap())
+//	            scope.SetExtra("stack", ev.Stack)
+//	            sentry.CaptureMessage(ev.Message)
+//	        })
+//	    }
+//	    return nil
+//	}
+//
+//	f, err := errtrack.New(errtrack.Options{Tracker: sentryTracker{}})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.AddHook(f.Hook)
+//	defer f.Close()
+//
+// Events are queued without blocking; when the queue is full they are dropped
+// and counted (see Dropped).
+package errtrack
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Event is an entry as delivered to a Tracker
+type Event struct {
+	Time     time.Time      // When the entry was logged
+	Level    int            // Severity of the entry
+	Message  string         // Rendered message
+	File     string         // Absolute path of the calling file
+	Line     int            // Line number of the call
+	Function string         // Fully qualified name of the calling function
+	Stack    string         // Stack trace of the logging goroutine, or the entry's "stack" field
+	Fields   []logger.Field // Structured fields attached to the entry
+}
+
+// FieldMap returns the event fields as a map, as most tracker SDKs expect
+func (e *Event) FieldMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields))
+	for _, f := range e.Fields {
+		m[f.Key] = f.Value
+	}
+	return m
+}
+
+// Tracker delivers events to an error tracking service
+type Tracker interface {
+	Send(events []Event) error
+}
+
+// TrackerFunc adapts a function to the Tracker interface
+type TrackerFunc func(events []Event) error
+
+// Send calls f(events)
+func (f TrackerFunc) Send(events []Event) error {
+	return f(events)
+}
+
+// Options configures the forwarder
+type Options struct {
+	Tracker       Tracker         // Destination of the events (required)
+	MinLevel      int             // Lowest level forwarded (default: logger.ERROR)
+	BatchSize     int             // Events per Send call (default: 20)
+	FlushInterval time.Duration   // Longest time an event waits for a batch to fill (default: 1s)
+	QueueSize     int             // Events held while the tracker is busy (default: 1000)
+	StackDepth    int             // Frames kept in captured stack traces (default: 32)
+	OnError       func(err error) // Called when Send fails (default: ignore)
+}
+
+// Forwarder queues entries and sends them to a Tracker in batches
+type Forwarder struct {
+	tracker       Tracker
+	minLevel      int
+	batchSize     int
+	flushInterval time.Duration
+	stackDepth    int
+	onError       func(err error)
+
+	queue     chan Event
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	dropped   atomic.Uint64
+}
+
+// New starts a forwarder for opts.Tracker
+func New(opts Options) (*Forwarder, error) {
+	if opts.Tracker == nil {
+		return nil, errors.New("errtrack: Tracker is required")
+	}
+	if opts.MinLevel == 0 {
+		opts.MinLevel = logger.ERROR
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1000
+	}
+	if opts.StackDepth <= 0 {
+		opts.StackDepth = 32
+	}
+
+	f := &Forwarder{
+		tracker:       opts.Tracker,
+		minLevel:      opts.MinLevel,
+		batchSize:     opts.BatchSize,
+		flushInterval: opts.FlushInterval,
+		stackDepth:    opts.StackDepth,
+		onError:       opts.OnError,
+		queue:         make(chan Event, opts.QueueSize),
+		done:          make(chan struct{}),
+	}
+	f.wg.Add(1)
+	go f.run()
+	return f, nil
+}
+
+// Hook queues entries at or above the minimum level; it matches logger.Hook.
+// It runs on the logging goroutine, so the captured stack is the caller's.
+func (f *Forwarder) Hook(e *logger.Entry) error {
+	if logger.LevelSeverity(e.Level) < logger.LevelSeverity(f.minLevel) {
+		return nil
+	}
+
+	ev := Event{
+		Time:     e.Time,
+		Level:    e.Level,
+		Message:  e.Message,
+		File:     e.File,
+		Line:     e.Line,
+		Function: e.Function,
+		Fields:   e.Fields,
+	}
+	for _, field := range e.Fields {
+		if field.Key == "stack" {
+			ev.Stack = fmt.Sprint(field.Value)
+			break
+		}
+	}
+	if ev.Stack == "" {
+		ev.Stack = f.captureStack()
+	}
+
+	select {
+	case <-f.done:
+		return nil
+	default:
+	}
+	select {
+	case f.queue <- ev:
+	default:
+		f.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped returns the number of events discarded because the queue was full
+func (f *Forwarder) Dropped() uint64 {
+	return f.dropped.Load()
+}
+
+// Close sends the queued events and stops the forwarder
+func (f *Forwarder) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.done)
+		f.wg.Wait()
+	})
+	return nil
+}
+
+// run collects queued events into batches and sends them
+func (f *Forwarder) run() {
+	defer f.wg.Done()
+
+	batch := make([]Event, 0, f.batchSize)
+	ticker := time.NewTicker(f.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-f.queue:
+			batch = append(batch, ev)
+			if len(batch) >= f.batchSize {
+				batch = f.send(batch)
+			}
+		case <-ticker.C:
+			batch = f.send(batch)
+		case <-f.done:
+			for {
+				select {
+				case ev := <-f.queue:
+					batch = append(batch, ev)
+					if len(batch) >= f.batchSize {
+						batch = f.send(batch)
+					}
+				default:
+					f.send(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers a batch and returns the emptied slice for reuse
+func (f *Forwarder) send(batch []Event) []Event {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := f.tracker.Send(batch); err != nil && f.onError != nil {
+		f.onError(fmt.Errorf("failed to send %d events to error tracker: %v", len(batch), err))
+	}
+	// The tracker may keep the slice, so start a new one
+	return make([]Event, 0, f.batchSize)
+}
+
+// captureStack renders the calling goroutine's stack, skipping logger frames
+func (f *Forwarder) captureStack() string {
+	pcs := make([]uintptr, f.stackDepth+16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	kept := 0
+	for kept < f.stackDepth {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/jbarasa/logger/logger.") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			kept++
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
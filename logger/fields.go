@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Field is a single structured key/value pair attached to a log entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields is a convenience map for attaching several structured fields at once
+type Fields map[string]interface{}
+
+// sorted returns the fields as a slice ordered by key so output is stable
+func (f Fields) sorted() []Field {
+	if len(f) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, len(keys))
+	for i, k := range keys {
+		fields[i] = Field{Key: k, Value: f[k]}
+	}
+	return fields
+}
+
+// Typed field constructors, shorter than Field literals and checked by the
+// compiler:
+//
+//	l.With(logger.String("user", name), logger.Int("attempt", n), logger.Dur("took", d))
+
+// String returns a field with a string value
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a field with an int value
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 returns a field with an int64 value
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 returns a field with a uint64 value
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 returns a field with a float64 value
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a field with a bool value
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time returns a field with a time value, rendered as RFC 3339
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Dur returns a field with a duration value, rendered like "1.5s". (Duration
+// is the duration type of configuration files.)
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bytes returns a field with a size in bytes, written as a number and shown
+// as e.g. 1.5MB by ConsolePretty
+func Bytes(key string, n int64) Field {
+	return Field{Key: key, Value: ByteSize(n)}
+}
+
+// Any returns a field with a value of any type
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// LazyValue is a field value computed only when an entry carrying it is
+// logged, so expensive values cost nothing in entries below the level. It
+// runs on the logging goroutine for every such entry, including when bound
+// with With:
+//
+//	l.DebugKV("cache state", logger.Fields{"dump": logger.LazyValue(cache.Dump)})
+type LazyValue func() interface{}
+
+// Lazy returns a field whose value is computed by fn only when the entry is
+// logged (see LazyValue)
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, Value: LazyValue(fn)}
+}
+
+// resolveLazy returns fields with Lazy values replaced by their results,
+// copying the slice first so bound and caller-owned fields are left as they were
+func resolveLazy(fields []Field) []Field {
+	for i := range fields {
+		if _, ok := fields[i].Value.(LazyValue); !ok {
+			continue
+		}
+		resolved := append([]Field(nil), fields...)
+		for j := i; j < len(resolved); j++ {
+			if fn, ok := resolved[j].Value.(LazyValue); ok {
+				resolved[j].Value = fn()
+			}
+		}
+		return resolved
+	}
+	return fields
+}
+
+// With returns a child logger that appends fields to every entry it logs,
+// after any fields already bound to l. The child shares the parent's file,
+// settings and pipeline, so creating one is cheap:
+//
+//	db := log.With(Field{Key: "component", Value: "db"})
+//	db.Info("connected") // ... connected component=db
+func (l *Logger) With(fields ...Field) *Logger {
+	if l == nil || len(fields) == 0 {
+		return l
+	}
+	return l.withFields(fields)
+}
+
+// With returns a child of the default logger carrying fields
+func With(fields ...Field) *Logger {
+	return defaultLogger.With(fields...)
+}
+
+// appendFields writes fields to buf in key=value form, quoting values when needed
+func appendFields(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		appendTextValue(buf, f.Value)
+	}
+}
+
+// formatValue renders a field value the same way for every output
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case error:
+		return val.Error()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case []byte:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// checkFields reports structured fields with an empty key or a nil value
+func (l *Logger) checkFields(fields []Field, file string, line int) {
+	for i, f := range fields {
+		if f.Key == "" {
+			l.internalError("WARNING: Log field %d has an empty key [%s:%d]", i, file, line)
+		}
+		if f.Value == nil {
+			l.internalError("WARNING: Log field %q has a nil value [%s:%d]", f.Key, file, line)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestValidateFieldsWarnsAboutEmptyKey(t *testing.T) {
+	var internal bytes.Buffer
+	l := newTestLogger(t, Config{
+		IsDev:               true,
+		ValidateFields:      true,
+		ConsoleWriter:       io.Discard,
+		InternalErrorWriter: &internal,
+	})
+
+	l.InfoKV("user signed in", Fields{"": "alice"})
+	if !strings.Contains(internal.String(), "has an empty key") {
+		t.Errorf("InternalErrorWriter got %q, want an empty key warning", internal.String())
+	}
+
+	// Outside development mode the fields are not checked
+	internal.Reset()
+	prod := newTestLogger(t, Config{ValidateFields: true, InternalErrorWriter: &internal})
+	prod.InfoKV("user signed in", Fields{"": "alice"})
+	if internal.Len() != 0 {
+		t.Errorf("InternalErrorWriter got %q outside development mode, want nothing", internal.String())
+	}
+}
+
+func TestMapFieldsInSortedOrder(t *testing.T) {
+	l := newTestLogger(t, Config{Level: TRACE})
+	fields := map[string]interface{}{"zone": "eu-west", "attempt": 2, "method": "GET", "bytes": 512}
+	l.Infom("request served", fields)
+	l.Tracem("request traced", fields)
+
+	lines := readLines(t, l)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %v", len(lines), lines)
+	}
+	for _, line := range lines {
+		last := -1
+		for _, key := range []string{"attempt=", "bytes=", "method=", "zone="} {
+			i := strings.Index(line, key)
+			if i < 0 {
+				t.Errorf("line %q is missing %s", line, key)
+				continue
+			}
+			if i < last {
+				t.Errorf("line %q does not hold its fields in sorted key order", line)
+			}
+			last = i
+		}
+	}
+}
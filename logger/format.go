@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format selects how entries are written to the log file
+type Format int
+
+// Output formats
+const (
+	Text   Format = iota // time [LEVEL] [file:line] message key=value ...
+	JSON                 // One JSON object per line
+	Logfmt               // ts=... level=info caller=file:line msg="..." key=value ...
+)
+
+// appendJSONEntry renders a log entry as a single-line JSON object:
+// {"time":...,"level":...,"caller":...,"msg":...,"service":{...},"fields":{...}}
+func (l *Logger) appendJSONEntry(buf *bytes.Buffer, entry *logEntry, caller string) {
+	buf.WriteString(`{"time":`)
+	if l.isEpochFormat() {
+		l.appendTime(buf, entry.timestamp, defaultJSONTimeFormat)
+	} else {
+		buf.WriteByte('"')
+		l.appendTime(buf, entry.timestamp, defaultJSONTimeFormat)
+		buf.WriteByte('"')
+	}
+	buf.WriteString(`,"level":`)
+	appendJSONString(buf, LevelName(entry.level))
+	if caller != "" {
+		buf.WriteString(`,"caller":`)
+		appendJSONString(buf, caller)
+	}
+	if function := l.callerFunction(entry); function != "" {
+		buf.WriteString(`,"func":`)
+		appendJSONString(buf, function)
+	}
+	buf.WriteString(`,"msg":`)
+	appendJSONString(buf, entry.msg)
+	if len(l.serviceFields) > 0 {
+		buf.WriteString(`,"service":`)
+		appendJSONObject(buf, l.serviceJSON)
+	}
+	if len(entry.fields) > 0 {
+		buf.WriteString(`,"fields":`)
+		appendJSONObject(buf, entry.fields)
+	}
+	buf.WriteString("}\n")
+}
+
+// LevelName returns the name a level is written with, e.g. "WARN"
+func LevelName(level int) string {
+	if name, ok := currentLevels.Load().names[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", level)
+}
+
+// MarshalJSON renders an entry in the same shape as JSON file output, so sinks
+// can ship entries the way they are stored locally. The caller is Caller when
+// set, otherwise the file's directory and base name with the line, e.g.
+// "api/handler.go:42".
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	appendJSONValue(&buf, e.Time)
+	buf.WriteString(`,"level":`)
+	appendJSONString(&buf, LevelName(e.Level))
+	if caller := e.caller(); caller != "" {
+		buf.WriteString(`,"caller":`)
+		appendJSONString(&buf, caller)
+	}
+	buf.WriteString(`,"msg":`)
+	appendJSONString(&buf, e.Message)
+	if len(e.Fields) > 0 {
+		buf.WriteString(`,"fields":`)
+		appendJSONObject(&buf, e.Fields)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// appendJSONObject writes fields as a JSON object, keeping their order
+func appendJSONObject(buf *bytes.Buffer, fields []Field) {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		appendJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		appendJSONValue(buf, jsonValue(f.Value))
+	}
+	buf.WriteByte('}')
+}
+
+// jsonValue converts values without a useful JSON form into strings
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Marshaler:
+		return val
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	case []byte:
+		return string(val)
+	default:
+		return val
+	}
+}
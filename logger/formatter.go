@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+)
+
+// Formatter renders an entry as one line of output, including the trailing
+// newline (one is added if missing). Set Config.Formatter to replace the
+// built-in layouts, e.g. to match existing Splunk extraction rules.
+//
+// The entry's Fields start with the service metadata fields, and Caller holds
+// the caller as the built-in formats render it. With Config.FormatWorkers
+// above 1, Format is called from several goroutines at once.
+type Formatter interface {
+	Format(entry Entry) []byte
+}
+
+// TextFormatter is the default layout: time [LEVEL] [caller] message key=value ...
+// Setting it in Config.Formatter is the same as Format: Text.
+type TextFormatter struct{}
+
+// Format renders e with the default time layout
+func (TextFormatter) Format(e Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(e.Time.Format(defaultTextTimeFormat))
+	buf.WriteString(" [")
+	buf.WriteString(LevelName(e.Level))
+	buf.WriteString("] ")
+	if caller := e.caller(); caller != "" {
+		buf.WriteByte('[')
+		buf.WriteString(caller)
+		buf.WriteString("] ")
+	}
+	buf.WriteString(e.Message)
+	appendFields(&buf, e.Fields)
+	buf.WriteByte('\n')
+	appendBlocks(&buf, e.Fields)
+	return buf.Bytes()
+}
+
+// JSONFormatter writes one JSON object per line (see Entry.MarshalJSON).
+// Setting it in Config.Formatter is the same as Format: JSON.
+type JSONFormatter struct{}
+
+// Format renders e as a JSON object
+func (JSONFormatter) Format(e Entry) []byte {
+	b, _ := e.MarshalJSON()
+	return append(b, '\n')
+}
+
+// caller returns the rendered caller, or the file's directory and base name
+// with the line when the entry was not produced by the logger's writer
+func (e *Entry) caller() string {
+	if e.Caller != "" || e.File == "" {
+		return e.Caller
+	}
+	short := filepath.Join(filepath.Base(filepath.Dir(e.File)), filepath.Base(e.File))
+	return filepath.ToSlash(short) + ":" + strconv.Itoa(e.Line)
+}
+
+// appendFormatted renders an entry with a custom formatter
+func (l *Logger) appendFormatted(buf *bytes.Buffer, entry *logEntry, caller string) {
+	e := entry.export()
+	e.Caller = caller
+	if len(l.serviceFields) > 0 {
+		e.Fields = append(append(make([]Field, 0, len(l.serviceFields)+len(e.Fields)), l.serviceFields...), e.Fields...)
+	}
+
+	out := l.formatter.Format(*e)
+	buf.Write(out)
+	if len(out) == 0 || out[len(out)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+}
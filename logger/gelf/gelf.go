@@ -0,0 +1,356 @@
+// Package gelf sends log entries to Graylog (or any GELF input) as GELF 1.1
+// messages over UDP or TCP, without shipping the log files.
+//
+//	s, err := gelf.New(gelf.Options{
+//	    Network: "udp",
+//	    Address: "graylog.internal:12201",
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{s}})
+//
+// Entry fields become GELF additional fields ("_" + key), and levels map to
+// syslog severities. UDP messages are compressed and split into GELF chunks
+// when larger than ChunkSize; TCP messages are null-byte delimited.
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Compression selects how UDP messages are compressed
+type Compression int
+
+const (
+	Gzip Compression = iota // gzip (default)
+	Zlib                    // zlib
+	None                    // Uncompressed
+)
+
+// Chunking limits defined by the GELF specification
+const (
+	DefaultChunkSize = 1420 // Fits a typical 1500 byte MTU
+	maxChunks        = 128
+	chunkHeaderSize  = 12
+)
+
+// chunkMagic starts every chunk of a chunked UDP message
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// Options configures the GELF sink
+type Options struct {
+	Network     string            // "udp" (default) or "tcp"
+	Address     string            // host:port of the GELF input (required)
+	Host        string            // "host" of every message (default: os.Hostname)
+	Extra       map[string]string // Additional fields sent with every message, e.g. service and env
+	Compression Compression       // UDP compression (default: Gzip); TCP is never compressed
+	ChunkSize   int               // Largest UDP datagram before chunking (default: DefaultChunkSize)
+}
+
+// Sink forwards entries to a GELF input
+type Sink struct {
+	mu          sync.Mutex
+	conn        net.Conn
+	network     string
+	address     string
+	stream      bool
+	host        string
+	extra       map[string]interface{}
+	compression Compression
+	chunkSize   int
+}
+
+// New connects to the GELF input described by opts
+func New(opts Options) (*Sink, error) {
+	if opts.Address == "" {
+		return nil, errors.New("gelf: Address is required")
+	}
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+	if opts.ChunkSize <= chunkHeaderSize {
+		opts.ChunkSize = DefaultChunkSize
+	}
+
+	s := &Sink{
+		network:     opts.Network,
+		address:     opts.Address,
+		stream:      strings.HasPrefix(opts.Network, "tcp"),
+		host:        opts.Host,
+		extra:       make(map[string]interface{}, len(opts.Extra)),
+		compression: opts.Compression,
+		chunkSize:   opts.ChunkSize,
+	}
+	for k, v := range opts.Extra {
+		if name := fieldName(k); name != "" {
+			s.extra[name] = v
+		}
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Level maps a logger level to the syslog severity used by GELF
+func Level(level int) int {
+	switch level = logger.BaseLevel(level); {
+	case level <= logger.DEBUG:
+		return 7
+	case level == logger.INFO:
+		return 6
+	case level == logger.WARN:
+		return 4
+	case level == logger.ERROR:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Hook sends an entry with its fields; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.send(e)
+}
+
+// WriteEntries sends a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	for _, e := range entries {
+		if err := s.send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write sends each line of formatted output as an INFO message, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		e := &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)}
+		if err := s.send(e); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the GELF input
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// connect dials the GELF input
+func (s *Sink) connect() error {
+	conn, err := net.Dial(s.network, s.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to GELF input: %v", err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// send encodes an entry and writes it, reconnecting once if the connection was lost
+func (s *Sink) send(e *logger.Entry) error {
+	msg, err := s.encode(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if err := s.write(msg); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	if err := s.write(msg); err != nil {
+		return fmt.Errorf("failed to write to GELF input: %v", err)
+	}
+	return nil
+}
+
+// write frames a message for the transport: null-terminated for TCP,
+// compressed and chunked as needed for UDP
+func (s *Sink) write(msg []byte) error {
+	if s.stream {
+		_, err := s.conn.Write(append(msg, 0))
+		return err
+	}
+
+	packet, err := s.compress(msg)
+	if err != nil {
+		return err
+	}
+	if len(packet) <= s.chunkSize {
+		_, err := s.conn.Write(packet)
+		return err
+	}
+
+	size := s.chunkSize - chunkHeaderSize
+	count := (len(packet) + size - 1) / size
+	if count > maxChunks {
+		return fmt.Errorf("message of %d bytes needs %d chunks, more than the %d GELF allows", len(packet), count, maxChunks)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate message id: %v", err)
+	}
+	chunk := make([]byte, 0, s.chunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(packet) {
+			end = len(packet)
+		}
+		chunk = append(chunk[:0], chunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, packet[i*size:end]...)
+		if _, err := s.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compress applies the configured UDP compression
+func (s *Sink) compress(msg []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+	switch s.compression {
+	case None:
+		return msg, nil
+	case Zlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		w = gzip.NewWriter(&buf)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, fmt.Errorf("failed to compress GELF message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress GELF message: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encode renders an entry as a GELF 1.1 JSON document
+func (s *Sink) encode(e *logger.Entry) ([]byte, error) {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	msg := make(map[string]interface{}, 8+len(s.extra)+len(e.Fields))
+	for k, v := range s.extra {
+		msg[k] = v
+	}
+	for _, f := range e.Fields {
+		name := fieldName(f.Key)
+		if name == "" {
+			continue
+		}
+		if f.Key == "stack" {
+			msg["full_message"] = fmt.Sprint(f.Value)
+			continue
+		}
+		msg[name] = fieldValue(f.Value)
+	}
+
+	msg["version"] = "1.1"
+	msg["host"] = s.host
+	msg["short_message"] = e.Message
+	msg["timestamp"] = float64(t.UnixNano()/int64(time.Millisecond)) / 1000
+	msg["level"] = Level(e.Level)
+	msg["_level_name"] = logger.LevelName(e.Level)
+	if e.File != "" {
+		msg["_file"] = e.File
+		msg["_line"] = e.Line
+	}
+	if e.Function != "" {
+		msg["_function"] = e.Function
+	}
+	if e.Message == "" {
+		// short_message is mandatory and must not be empty
+		msg["short_message"] = "-"
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GELF message: %v", err)
+	}
+	return data, nil
+}
+
+// fieldValue keeps numbers and strings as they are, since Graylog only
+// indexes those, and renders anything else as a string
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fieldName converts a key into a GELF additional field name: "_" followed by
+// word characters, dots and dashes. "_id" is reserved, so "id" becomes "_id_".
+func fieldName(key string) string {
+	if key == "" {
+		return ""
+	}
+	name := "_" + strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+	if name == "_id" {
+		name = "_id_"
+	}
+	return name
+}
@@ -0,0 +1,20 @@
+module github.com/jbarasa/logger/logger/grpclog
+
+go 1.21
+
+require (
+	github.com/jbarasa/logger v1.0.2
+	google.golang.org/grpc v1.60.1
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.16.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+)
+
+// Build against the logger in this repository
+replace github.com/jbarasa/logger => ../..
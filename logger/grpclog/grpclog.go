@@ -0,0 +1,201 @@
+// Package grpclog provides gRPC server and client interceptors that log one
+// entry per RPC through the logger's async pipeline.
+//
+//	srv := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(grpclog.UnaryServerInterceptor(grpclog.Options{})),
+//	    grpc.ChainStreamInterceptor(grpclog.StreamServerInterceptor(grpclog.Options{})),
+//	)
+//
+//	conn, err := grpc.Dial(addr,
+//	    grpc.WithUnaryInterceptor(grpclog.UnaryClientInterceptor(grpclog.Options{})),
+//	    grpc.WithStreamInterceptor(grpclog.StreamClientInterceptor(grpclog.Options{})),
+//	)
+//
+// Each entry carries the full method name, status code, duration and peer
+// address. Request and response payloads can be added, capped in size.
+//
+// The package is a separate module so the logger itself does not depend on gRPC.
+package grpclog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures the interceptors
+type Options struct {
+	Logger         *logger.Logger               // Logger to write to (default: the logger in the call context, else the default logger)
+	Level          func(code codes.Code) int    // Level for a status code (default: DefaultLevel)
+	LogPayloads    bool                         // Log request and response messages of unary calls
+	MaxPayloadSize int                          // Payload characters kept before truncation (default: 1024)
+	Skip           func(fullMethod string) bool // Methods not to log, e.g. health checks
+}
+
+// withDefaults fills in unset options
+func (o Options) withDefaults() Options {
+	if o.Level == nil {
+		o.Level = DefaultLevel
+	}
+	if o.MaxPayloadSize <= 0 {
+		o.MaxPayloadSize = 1024
+	}
+	return o
+}
+
+// DefaultLevel logs OK as INFO, client-caused codes as WARN and server
+// failures as ERROR
+func DefaultLevel(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return logger.INFO
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return logger.WARN
+	default:
+		return logger.ERROR
+	}
+}
+
+// UnaryServerInterceptor logs every unary RPC handled by the server
+func UnaryServerInterceptor(opts Options) grpc.UnaryServerInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if opts.Skip != nil && opts.Skip(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		fields := callFields(ctx, "server", info.FullMethod, err, time.Since(start))
+		if opts.LogPayloads {
+			fields["request"] = payload(req, opts.MaxPayloadSize)
+			if err == nil {
+				fields["response"] = payload(resp, opts.MaxPayloadSize)
+			}
+		}
+		write(ctx, opts, "grpc request", err, fields)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every streaming RPC handled by the server when the stream ends
+func StreamServerInterceptor(opts Options) grpc.StreamServerInterceptor {
+	opts = opts.withDefaults()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if opts.Skip != nil && opts.Skip(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+
+		ctx := ss.Context()
+		write(ctx, opts, "grpc stream", err, callFields(ctx, "server", info.FullMethod, err, time.Since(start)))
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs every unary RPC made by the client
+func UnaryClientInterceptor(opts Options) grpc.UnaryClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if opts.Skip != nil && opts.Skip(method) {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+
+		fields := callFields(ctx, "client", method, err, time.Since(start))
+		fields["target"] = cc.Target()
+		if opts.LogPayloads {
+			fields["request"] = payload(req, opts.MaxPayloadSize)
+			if err == nil {
+				fields["response"] = payload(reply, opts.MaxPayloadSize)
+			}
+		}
+		write(ctx, opts, "grpc call", err, fields)
+		return err
+	}
+}
+
+// StreamClientInterceptor logs the opening of every streaming RPC made by the client
+func StreamClientInterceptor(opts Options) grpc.StreamClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if opts.Skip != nil && opts.Skip(method) {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+
+		fields := callFields(ctx, "client", method, err, time.Since(start))
+		fields["target"] = cc.Target()
+		write(ctx, opts, "grpc stream opened", err, fields)
+		return cs, err
+	}
+}
+
+// callFields collects the attributes common to every RPC entry
+func callFields(ctx context.Context, kind, method string, err error, duration time.Duration) logger.Fields {
+	fields := logger.Fields{
+		"grpc_kind": kind,
+		"method":    method,
+		"code":      status.Code(err).String(),
+		"duration":  duration,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer"] = p.Addr.String()
+	}
+	if err != nil {
+		fields["error"] = status.Convert(err).Message()
+	}
+	return fields
+}
+
+// write logs an RPC entry at the level chosen for its status code
+func write(ctx context.Context, opts Options, msg string, err error, fields logger.Fields) {
+	log := opts.Logger
+	if log == nil {
+		log = logger.FromContext(ctx)
+	} else {
+		log = log.WithContext(ctx)
+	}
+	if log == nil {
+		return
+	}
+
+	switch level := opts.Level(status.Code(err)); {
+	case logger.BaseLevel(level) != level:
+		log.LogKV(level, msg, fields) // Custom level
+	case level >= logger.ERROR:
+		log.ErrorKV(msg, fields)
+	case level == logger.WARN:
+		log.WarnKV(msg, fields)
+	case level == logger.INFO:
+		log.InfoKV(msg, fields)
+	case level == logger.DEBUG:
+		log.DebugKV(msg, fields)
+	default:
+		log.TraceKV(msg, fields)
+	}
+}
+
+// payload renders a message for logging, truncated to max characters
+func payload(msg interface{}, max int) string {
+	s := fmt.Sprint(msg)
+	if len(s) > max {
+		s = s[:max] + "...(truncated)"
+	}
+	return s
+}
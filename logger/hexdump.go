@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxHexDump is the number of payload bytes a Hex field keeps
+const maxHexDump = 1024
+
+// HexData is the value of a field created by Hex: a copy of the start of a
+// payload and the payload's full length. Text output writes the length
+// inline and a hexdump -C style block below the line; JSON renders
+// {"len":...,"hex":"..."} and logfmt the hex digits.
+type HexData struct {
+	Data []byte // First bytes of the payload, at most 1KB
+	Len  int    // Length of the whole payload
+}
+
+// Hex returns a field dumping binary data, e.g. a network packet. Only the
+// first 1KB is kept, copied so the caller may reuse data.
+func Hex(key string, data []byte) Field {
+	kept := data
+	if len(kept) > maxHexDump {
+		kept = kept[:maxHexDump]
+	}
+	return Field{Key: key, Value: HexData{Data: append([]byte(nil), kept...), Len: len(data)}}
+}
+
+// String returns the payload's length, e.g. "1500 bytes"
+func (h HexData) String() string {
+	if len(h.Data) < h.Len {
+		return fmt.Sprintf("%d bytes, first %d shown", h.Len, len(h.Data))
+	}
+	return fmt.Sprintf("%d bytes", h.Len)
+}
+
+// MarshalJSON renders the length and the kept bytes as hex digits
+func (h HexData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Len int    `json:"len"`
+		Hex string `json:"hex"`
+	}{h.Len, hex.EncodeToString(h.Data)})
+}
+
+// appendHexBlocks writes every HexData field as an indented hexdump -C
+// style block following a text entry, e.g.
+//
+//	packet (18 bytes):
+//	  00000000  47 45 54 20 2f 20 48 54  54 50 2f 31 2e 31 0d 0a  |GET / HTTP/1.1..|
+//	  00000010  0d 0a                                             |..|
+func appendHexBlocks(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		h, ok := f.Value.(HexData)
+		if !ok || len(h.Data) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "    %s (%s):\n", f.Key, h)
+		for _, line := range strings.Split(strings.TrimRight(hex.Dump(h.Data), "\n"), "\n") {
+			buf.WriteString("      ")
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+}
+
+// appendBlocks writes the multi-line blocks of error chains and hex dumps
+// following a text entry
+func appendBlocks(buf *bytes.Buffer, fields []Field) {
+	appendErrorBlocks(buf, fields)
+	appendHexBlocks(buf, fields)
+}
+
+// DebugHex logs msg at DEBUG with a hex dump of data in a "data" field, for
+// protocol debugging. Data is only copied when DEBUG is enabled.
+func (l *Logger) DebugHex(msg string, data []byte) {
+	if l != nil && (l.IsLevelEnabled(DEBUG) || l.ring != nil) {
+		l.logFields(DEBUG, 0, msg, []Field{Hex("data", data)})
+	}
+}
+
+// TraceHex logs msg at TRACE with a hex dump of data in a "data" field
+func (l *Logger) TraceHex(msg string, data []byte) {
+	if l != nil && (l.IsLevelEnabled(TRACE) || l.ring != nil) {
+		l.logFields(TRACE, 0, msg, []Field{Hex("data", data)})
+	}
+}
+
+// DebugHex logs msg at DEBUG with a hex dump of data using the default logger
+func DebugHex(msg string, data []byte) {
+	if defaultLogger != nil && (defaultLogger.IsLevelEnabled(DEBUG) || defaultLogger.ring != nil) {
+		defaultLogger.logFields(DEBUG, 0, msg, []Field{Hex("data", data)})
+	}
+}
+
+// TraceHex logs msg at TRACE with a hex dump of data using the default logger
+func TraceHex(msg string, data []byte) {
+	if defaultLogger != nil && (defaultLogger.IsLevelEnabled(TRACE) || defaultLogger.ring != nil) {
+		defaultLogger.logFields(TRACE, 0, msg, []Field{Hex("data", data)})
+	}
+}
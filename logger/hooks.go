@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// Entry is the exported view of a log entry handed to hooks
+type Entry struct {
+	Time     time.Time // When the entry was logged
+	Level    int       // Severity of the entry
+	Message  string    // Rendered message
+	File     string    // Absolute path of the calling file
+	Line     int       // Line number of the call
+	Function string    // Fully qualified name of the calling function
+	Caller   string    // Caller as rendered in log lines; set for formatters and subscribers, empty in hooks
+	Fields   []Field   // Structured fields attached to the entry
+
+	Context context.Context // Context of a logger made with WithContext (or of a slog call), nil otherwise
+}
+
+// Hook is called synchronously for every entry before it is queued for writing.
+// Hooks run in the order they were added and may change the entry's Message and
+// Fields, which later hooks and the written line see. Returning ErrDropEntry
+// discards the entry; any other error is reported to the error hooks and does
+// not stop the entry.
+type Hook func(*Entry) error
+
+// ErrDropEntry is returned by a hook to discard the entry
+var ErrDropEntry = errors.New("drop entry")
+
+// hook is a registered hook with its level filter
+type hook struct {
+	fn     Hook
+	levels map[int]bool // nil runs the hook for every level
+}
+
+// AddHook registers a hook that runs for every entry passing the level filter
+func (l *Logger) AddHook(h Hook) {
+	l.AddLevelHook(h)
+}
+
+// AddHook registers a hook on the default logger
+func AddHook(h Hook) {
+	if defaultLogger != nil {
+		defaultLogger.AddHook(h)
+	}
+}
+
+// AddLevelHook registers a hook that runs only for entries at the given
+// levels, or for every entry when none are given
+func (l *Logger) AddLevelHook(h Hook, levels ...int) {
+	l.AddRemovableHook(h, levels...)
+}
+
+// AddRemovableHook is AddLevelHook returning a function that removes the
+// hook again, e.g. at the end of a test
+func (l *Logger) AddRemovableHook(h Hook, levels ...int) (remove func()) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+
+	var set map[int]bool
+	if len(levels) > 0 {
+		set = levelSet(levels)
+	}
+	added := &hook{fn: h, levels: set}
+
+	// Copy on write so runHooks can iterate without holding the lock
+	hooks := make([]*hook, len(l.hooks), len(l.hooks)+1)
+	copy(hooks, l.hooks)
+	l.hooks = append(hooks, added)
+
+	return func() {
+		l.hooksMu.Lock()
+		defer l.hooksMu.Unlock()
+		hooks := make([]*hook, 0, len(l.hooks))
+		for _, other := range l.hooks {
+			if other != added {
+				hooks = append(hooks, other)
+			}
+		}
+		l.hooks = hooks
+	}
+}
+
+// AddLevelHook registers a level-filtered hook on the default logger
+func AddLevelHook(h Hook, levels ...int) {
+	if defaultLogger != nil {
+		defaultLogger.AddLevelHook(h, levels...)
+	}
+}
+
+// AddRemovableHook registers a removable hook on the default logger
+func AddRemovableHook(h Hook, levels ...int) (remove func()) {
+	if defaultLogger == nil {
+		return func() {}
+	}
+	return defaultLogger.AddRemovableHook(h, levels...)
+}
+
+// hasHooks reports whether any entry hook is registered
+func (l *Logger) hasHooks() bool {
+	l.hooksMu.RLock()
+	defer l.hooksMu.RUnlock()
+	return len(l.hooks) > 0
+}
+
+// runHooks passes an entry through the hook chain, applying changes made by
+// the hooks. It returns false when a hook dropped the entry.
+func (l *Logger) runHooks(entry *logEntry) bool {
+	l.hooksMu.RLock()
+	hooks := l.hooks
+	l.hooksMu.RUnlock()
+
+	var e *Entry
+	for _, h := range hooks {
+		if h.levels != nil && !h.levels[entry.level] {
+			continue
+		}
+		if e == nil {
+			e = entry.export()
+		}
+		if err := h.fn(e); err == ErrDropEntry {
+			return false
+		} else if err != nil {
+			l.reportError(OpHook, err, "Error running log hook: %v", err)
+		}
+	}
+
+	if e != nil {
+		entry.msg = append(entry.msg[:0], e.Message...)
+		entry.fields = e.Fields
+	}
+	return true
+}
+
+// export converts an internal entry into its public form
+func (e *logEntry) export() *Entry {
+	function := ""
+	if fn := runtime.FuncForPC(e.pc); fn != nil {
+		function = fn.Name()
+	}
+	return &Entry{
+		Time:     time.Unix(0, e.timestamp),
+		Level:    e.level,
+		Message:  string(e.msg),
+		File:     e.file,
+		Line:     e.line,
+		Function: function,
+		Fields:   e.fields[:len(e.fields):len(e.fields)], // Appends by hooks must not touch shared arrays
+		Context:  e.ctx,
+	}
+}
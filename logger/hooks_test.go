@@ -0,0 +1,29 @@
+package logger
+
+import "testing"
+
+func TestRemovableHook(t *testing.T) {
+	l := newTestLogger(t, Config{})
+	var first, second int
+	removeFirst := l.AddRemovableHook(func(*Entry) error {
+		first++
+		return nil
+	})
+	removeSecond := l.AddRemovableHook(func(*Entry) error {
+		second++
+		return nil
+	})
+
+	l.Info("both")
+	removeFirst()
+	l.Info("second only")
+	removeSecond()
+	l.Info("none")
+
+	if first != 1 || second != 2 {
+		t.Errorf("hooks ran %d and %d times, want 1 and 2", first, second)
+	}
+	if l.hasHooks() {
+		t.Error("hooks still registered after removal")
+	}
+}
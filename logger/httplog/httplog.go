@@ -0,0 +1,252 @@
+// Package httplog provides net/http middleware that writes one access log
+// entry per request through the logger's async pipeline.
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/users", listUsers)
+//	http.ListenAndServe(":8080", httplog.Middleware(httplog.Options{})(mux))
+//
+// Each entry carries the method, path, status, latency, response size, remote
+// IP and request ID. The request ID is also added to the request context with
+// logger.ContextWithFields, so handlers logging through logger.FromContext
+// share it with the access log entry.
+package httplog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// FieldSet selects which request attributes are logged
+type FieldSet uint
+
+// Request attributes
+const (
+	FieldMethod    FieldSet = 1 << iota // method
+	FieldPath                           // path
+	FieldQuery                          // query (raw query string)
+	FieldStatus                         // status
+	FieldLatency                        // latency
+	FieldBytes                          // bytes (response body size)
+	FieldRemoteIP                       // remote_ip
+	FieldRequestID                      // request_id (also added to the request context)
+	FieldUserAgent                      // user_agent
+	FieldReferer                        // referer
+	FieldProto                          // proto
+
+	// DefaultFields is used when Options.Fields is zero
+	DefaultFields = FieldMethod | FieldPath | FieldStatus | FieldLatency | FieldBytes | FieldRemoteIP | FieldRequestID
+)
+
+// Options configures the middleware
+type Options struct {
+	Logger          *logger.Logger             // Logger to write to (default: the logger in the request context, else the default logger)
+	Message         string                     // Message of every entry (default: "http request")
+	Fields          FieldSet                   // Attributes to log (default: DefaultFields)
+	Level           func(status int) int       // Level for a response status (default: DefaultLevel)
+	RequestIDHeader string                     // Header carrying the request ID (default: "X-Request-ID")
+	GenerateID      bool                       // Generate a request ID when the header is missing and echo it in the response
+	TrustProxy      bool                       // Take the remote IP from X-Forwarded-For / X-Real-IP
+	Skip            func(r *http.Request) bool // Requests not to log, e.g. health checks
+}
+
+// DefaultLevel logs 5xx responses as ERROR, 4xx as WARN and everything else as INFO
+func DefaultLevel(status int) int {
+	switch {
+	case status >= 500:
+		return logger.ERROR
+	case status >= 400:
+		return logger.WARN
+	default:
+		return logger.INFO
+	}
+}
+
+// Middleware returns middleware that logs every request passed to the wrapped handler
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	if opts.Message == "" {
+		opts.Message = "http request"
+	}
+	if opts.Fields == 0 {
+		opts.Fields = DefaultFields
+	}
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = "X-Request-ID"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if opts.Skip != nil && opts.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			if opts.Fields&FieldRequestID != 0 {
+				id := r.Header.Get(opts.RequestIDHeader)
+				if id == "" && opts.GenerateID {
+					id = newRequestID()
+					w.Header().Set(opts.RequestIDHeader, id)
+				}
+				if id != "" {
+					r = r.WithContext(logger.ContextWithFields(r.Context(), logger.Fields{"request_id": id}))
+				}
+			}
+
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			log := opts.Logger
+			if log == nil {
+				log = logger.FromContext(r.Context())
+			} else {
+				log = log.WithContext(r.Context())
+			}
+			if log == nil {
+				return
+			}
+
+			fields := requestFields(opts, r, rw, time.Since(start))
+			switch level := opts.Level(rw.status); {
+			case logger.BaseLevel(level) != level:
+				log.LogKV(level, opts.Message, fields) // Custom level
+			case level >= logger.ERROR:
+				log.ErrorKV(opts.Message, fields)
+			case level == logger.WARN:
+				log.WarnKV(opts.Message, fields)
+			case level == logger.INFO:
+				log.InfoKV(opts.Message, fields)
+			case level == logger.DEBUG:
+				log.DebugKV(opts.Message, fields)
+			default:
+				log.TraceKV(opts.Message, fields)
+			}
+		})
+	}
+}
+
+// requestFields collects the configured attributes of a finished request
+func requestFields(opts Options, r *http.Request, rw *responseWriter, latency time.Duration) logger.Fields {
+	fields := make(logger.Fields, 8)
+	set := opts.Fields
+	if set&FieldMethod != 0 {
+		fields["method"] = r.Method
+	}
+	if set&FieldPath != 0 {
+		fields["path"] = r.URL.Path
+	}
+	if set&FieldQuery != 0 && r.URL.RawQuery != "" {
+		fields["query"] = r.URL.RawQuery
+	}
+	if set&FieldStatus != 0 {
+		fields["status"] = rw.status
+	}
+	if set&FieldLatency != 0 {
+		fields["latency"] = latency
+	}
+	if set&FieldBytes != 0 {
+		fields["bytes"] = rw.bytes
+	}
+	if set&FieldRemoteIP != 0 {
+		fields["remote_ip"] = remoteIP(r, opts.TrustProxy)
+	}
+	if set&FieldUserAgent != 0 {
+		fields["user_agent"] = r.UserAgent()
+	}
+	if set&FieldReferer != 0 && r.Referer() != "" {
+		fields["referer"] = r.Referer()
+	}
+	if set&FieldProto != 0 {
+		fields["proto"] = r.Proto
+	}
+	return fields
+}
+
+// remoteIP returns the client address, consulting proxy headers only when trusted
+func remoteIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			// The left-most address is the original client
+			if i := strings.IndexByte(fwd, ','); i >= 0 {
+				fwd = fwd[:i]
+			}
+			return strings.TrimSpace(fwd)
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// newRequestID returns a random 16-byte hex ID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status code and body size of a response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status code
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write counts body bytes
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer does
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer does
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httplog: underlying ResponseWriter does not support hijacking")
+	}
+	if !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return h.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
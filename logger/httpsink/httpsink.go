@@ -0,0 +1,412 @@
+// Package httpsink POSTs batches of log entries to an HTTP(S) collector.
+//
+// Entries are queued in memory and sent in batches from a background
+// goroutine, so a slow collector never blocks the logger's writer:
+//
+//	h, err := httpsink.New(httpsink.Options{
+//	    URL:         "https://logs.internal/ingest",
+//	    Headers:     map[string]string{"Authorization": "Bearer " + token},
+//	    Gzip:        true,
+//	    OverflowDir: "storage/logs/overflow",
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{h}})
+//
+// Each entry is encoded with logger.Entry's JSON form. Failed batches are
+// retried with exponential backoff; batches that still fail are written to
+// OverflowDir and resent once the collector accepts requests again.
+package httpsink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Encoding selects the request body layout
+type Encoding int
+
+const (
+	NDJSON    Encoding = iota // One JSON object per line (default)
+	JSONArray                 // A single JSON array of objects
+)
+
+// overflowExt marks batches spilled to disk
+const overflowExt = ".ndjson"
+
+// Options configures the HTTP sink
+type Options struct {
+	URL      string            // Collector endpoint (required)
+	Encoding Encoding          // NDJSON (default) or JSONArray
+	Headers  map[string]string // Extra request headers, e.g. Authorization
+	Gzip     bool              // Compress request bodies
+	Client   *http.Client      // HTTP client (default: 10s timeout)
+
+	BatchSize     int           // Entries per request (default: 500)
+	FlushInterval time.Duration // Maximum time an entry waits before being sent (default: 1s)
+	MaxPending    int           // Entries queued in memory before new ones are spilled or dropped (default: 50000)
+
+	MaxRetries   int           // Retries after a failed request (default: 3)
+	RetryBackoff time.Duration // Delay before the first retry, doubled for each one (default: 500ms)
+
+	OverflowDir     string // Directory for batches that could not be delivered (empty drops them)
+	MaxOverflowSize int64  // Total bytes kept in OverflowDir (default: 100MB)
+
+	OnError func(err error) // Called when a batch is spilled or dropped (default: print to stderr)
+}
+
+// Sink sends entries to an HTTP collector
+type Sink struct {
+	opts    Options
+	mu      sync.Mutex
+	pending [][]byte // Encoded entries waiting to be sent
+	kick    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// New validates opts and starts the background sender
+func New(opts Options) (*Sink, error) {
+	if opts.URL == "" {
+		return nil, errors.New("httpsink: URL is required")
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 50000
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	if opts.MaxOverflowSize <= 0 {
+		opts.MaxOverflowSize = 100 * 1024 * 1024 // 100MB default
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) { fmt.Fprintf(os.Stderr, "httpsink: %v\n", err) }
+	}
+	if opts.OverflowDir != "" {
+		if err := os.MkdirAll(opts.OverflowDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create overflow directory: %v", err)
+		}
+	}
+
+	s := &Sink{
+		opts: opts,
+		kick: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// WriteEntries queues a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	lines := make([][]byte, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode entry: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	return s.queue(lines)
+}
+
+// Write queues each line of formatted output as an INFO message, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	var entries []*logger.Entry
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		entries = append(entries, &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)})
+	}
+	if err := s.WriteEntries(entries); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends everything still queued and stops the background sender
+func (s *Sink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// queue adds encoded entries, spilling the oldest queued ones when the queue is full
+func (s *Sink) queue(lines [][]byte) error {
+	s.mu.Lock()
+	s.pending = append(s.pending, lines...)
+	var spill [][]byte
+	if over := len(s.pending) - s.opts.MaxPending; over > 0 {
+		spill = s.pending[:over:over]
+		s.pending = s.pending[over:]
+	}
+	full := len(s.pending) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	if spill != nil {
+		s.overflow(spill, errors.New("queue full"))
+	}
+	return nil
+}
+
+// run sends batches when one is full or the flush interval passes
+func (s *Sink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.kick:
+			s.sendPending(false)
+		case <-ticker.C:
+			if s.sendPending(true) {
+				s.replayOverflow()
+			}
+		case <-s.done:
+			s.sendPending(true)
+			return
+		}
+	}
+}
+
+// sendPending sends queued entries in batches. Unless all is set only full
+// batches are sent. It reports whether every request succeeded.
+func (s *Sink) sendPending(all bool) bool {
+	ok := true
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n == 0 || (!all && n < s.opts.BatchSize) {
+			s.mu.Unlock()
+			return ok
+		}
+		if n > s.opts.BatchSize {
+			n = s.opts.BatchSize
+		}
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if err := s.send(batch); err != nil {
+			ok = false
+			s.overflow(batch, err)
+		}
+	}
+}
+
+// send posts a batch, retrying with exponential backoff on network errors,
+// 429 and 5xx responses
+func (s *Sink) send(batch [][]byte) error {
+	body, err := s.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// Shutting down: one immediate last attempt instead of waiting
+			if _, err := s.post(body); err != nil {
+				return err
+			}
+			return nil
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single request and reports whether a failure is worth retrying
+func (s *Sink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %v", err)
+	}
+	if s.opts.Encoding == JSONArray {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if s.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send logs: %v", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("failed to send logs: %s", resp.Status)
+}
+
+// encode builds a request body from encoded entries
+func (s *Sink) encode(batch [][]byte) ([]byte, error) {
+	var raw bytes.Buffer
+	if s.opts.Encoding == JSONArray {
+		raw.WriteByte('[')
+		raw.Write(bytes.Join(batch, []byte{','}))
+		raw.WriteByte(']')
+	} else {
+		for _, line := range batch {
+			raw.Write(line)
+			raw.WriteByte('\n')
+		}
+	}
+	if !s.opts.Gzip {
+		return raw.Bytes(), nil
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to compress logs: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress logs: %v", err)
+	}
+	return gz.Bytes(), nil
+}
+
+// overflow writes a batch that could not be delivered to OverflowDir, or
+// reports it as dropped when there is no room
+func (s *Sink) overflow(batch [][]byte, cause error) {
+	if s.opts.OverflowDir == "" {
+		s.opts.OnError(fmt.Errorf("dropped %d entries: %v", len(batch), cause))
+		return
+	}
+
+	data := append(bytes.Join(batch, []byte{'\n'}), '\n')
+	if s.overflowSize()+int64(len(data)) > s.opts.MaxOverflowSize {
+		s.opts.OnError(fmt.Errorf("dropped %d entries, overflow directory full: %v", len(batch), cause))
+		return
+	}
+
+	name := filepath.Join(s.opts.OverflowDir, fmt.Sprintf("%d%s", time.Now().UnixNano(), overflowExt))
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		s.opts.OnError(fmt.Errorf("dropped %d entries, failed to write overflow file: %v", len(batch), err))
+		return
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		s.opts.OnError(fmt.Errorf("dropped %d entries, failed to write overflow file: %v", len(batch), err))
+		return
+	}
+	s.opts.OnError(fmt.Errorf("spilled %d entries to %s: %v", len(batch), name, cause))
+}
+
+// overflowFiles lists spilled batches, oldest first
+func (s *Sink) overflowFiles() []string {
+	files, err := os.ReadDir(s.opts.OverflowDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), overflowExt) {
+			names = append(names, filepath.Join(s.opts.OverflowDir, f.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// overflowSize returns the bytes currently held in OverflowDir
+func (s *Sink) overflowSize() int64 {
+	var size int64
+	for _, name := range s.overflowFiles() {
+		if info, err := os.Stat(name); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// replayOverflow resends spilled batches oldest first, stopping at the first failure
+func (s *Sink) replayOverflow() {
+	if s.opts.OverflowDir == "" {
+		return
+	}
+	for _, name := range s.overflowFiles() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte{'\n'})
+		for len(lines) > 0 {
+			n := len(lines)
+			if n > s.opts.BatchSize {
+				n = s.opts.BatchSize
+			}
+			if err := s.send(lines[:n]); err != nil {
+				// Keep what is left for the next attempt
+				os.WriteFile(name, append(bytes.Join(lines, []byte{'\n'}), '\n'), 0644)
+				return
+			}
+			lines = lines[n:]
+		}
+		os.Remove(name)
+
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package logger
+
+// Interface is the logging surface a library needs. Accepting it instead of
+// calling the package-level functions lets applications pass their own
+// *Logger, and tests pass Nop() or a mock:
+//
+//	type Client struct {
+//	    log logger.Interface
+//	}
+//
+//	func NewClient(log logger.Interface) *Client {
+//	    if log == nil {
+//	        log = logger.Nop()
+//	    }
+//	    return &Client{log: log}
+//	}
+type Interface interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+
+	DebugKV(msg string, fields Fields)
+	InfoKV(msg string, fields Fields)
+	WarnKV(msg string, fields Fields)
+	ErrorKV(msg string, fields Fields)
+}
+
+var _ Interface = (*Logger)(nil)
+
+// nopLogger discards everything
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) DebugKV(string, Fields)       {}
+func (nopLogger) InfoKV(string, Fields)        {}
+func (nopLogger) WarnKV(string, Fields)        {}
+func (nopLogger) ErrorKV(string, Fields)       {}
+
+// Nop returns an Interface that discards every entry without formatting it
+func Nop() Interface {
+	return nopLogger{}
+}
+
+// Default returns the logger set up by Initialize, for passing the global
+// logger where an Interface or *Logger is expected. Before Initialize it
+// returns nil, which logs nothing.
+func Default() *Logger {
+	return defaultLogger
+}
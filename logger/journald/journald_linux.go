@@ -0,0 +1,105 @@
+//go:build linux
+
+package journald
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Sink forwards entries to the systemd journal
+type Sink struct {
+	mu         sync.Mutex
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	identifier string
+}
+
+// New connects to the journald socket
+func New(opts Options) (*Sink, error) {
+	if opts.Socket == "" {
+		opts.Socket = DefaultSocket
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journald connection: %v", err)
+	}
+
+	return &Sink{
+		conn:       conn,
+		addr:       &net.UnixAddr{Name: opts.Socket, Net: "unixgram"},
+		identifier: opts.Identifier,
+	}, nil
+}
+
+// Hook sends an entry with its structured fields; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.send(encodeEntry(e, s.identifier))
+}
+
+// WriteEntries sends a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	for _, e := range entries {
+		if err := s.send(encodeEntry(e, s.identifier)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write sends each line of formatted output as an INFO message, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		e := &logger.Entry{Level: logger.INFO, Message: string(line)}
+		if err := s.send(encodeEntry(e, s.identifier)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the journald connection
+func (s *Sink) Close() error {
+	return s.conn.Close()
+}
+
+// send writes a datagram, passing it through a file descriptor when it is
+// too large for the socket, as the native protocol allows
+func (s *Sink) send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _, err := s.conn.WriteMsgUnix(data, nil, s.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return fmt.Errorf("failed to write to journald: %v", err)
+	}
+
+	// Oversized entry: hand journald an unlinked temporary file instead
+	f, err := os.CreateTemp("/dev/shm", "journal.*")
+	if err != nil {
+		return fmt.Errorf("failed to create journald spill file: %v", err)
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write journald spill file: %v", err)
+	}
+	rights := syscall.UnixRights(int(f.Fd()))
+	if _, _, err := s.conn.WriteMsgUnix(nil, rights, s.addr); err != nil {
+		return fmt.Errorf("failed to write to journald: %v", err)
+	}
+	return nil
+}
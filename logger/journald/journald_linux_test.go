@@ -0,0 +1,99 @@
+//go:build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// parseDatagram decodes a native protocol datagram into its fields
+func parseDatagram(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		nl := bytes.IndexByte(data, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field in datagram %q", data)
+		}
+		if eq := bytes.IndexByte(data[:nl], '='); eq >= 0 {
+			fields[string(data[:eq])] = string(data[eq+1 : nl])
+			data = data[nl+1:]
+			continue
+		}
+		// Binary form: name, newline, 64-bit little endian size, value, newline
+		name := string(data[:nl])
+		data = data[nl+1:]
+		if len(data) < 8 {
+			t.Fatalf("field %s has no size", name)
+		}
+		size := int(binary.LittleEndian.Uint64(data))
+		data = data[8:]
+		if len(data) < size+1 || data[size] != '\n' {
+			t.Fatalf("field %s of size %d is not framed correctly", name, size)
+		}
+		fields[name] = string(data[:size])
+		data = data[size+1:]
+	}
+	return fields
+}
+
+func TestSinkSendsDatagrams(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	journal, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to create fake journald socket: %v", err)
+	}
+	defer journal.Close()
+
+	sink, err := New(Options{Socket: socket, Identifier: "api"})
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer sink.Close()
+
+	entries := []*logger.Entry{
+		{Level: logger.TRACE, Message: "query planned"},
+		{Level: logger.DEBUG, Message: "cache miss"},
+		{Level: logger.INFO, Message: "user signed in", File: "/src/auth.go", Line: 42, Fields: []logger.Field{{Key: "user.id", Value: 7}}},
+		{Level: logger.WARN, Message: "slow response"},
+		{Level: logger.ERROR, Message: "payment declined"},
+		{Level: logger.PANIC, Message: "invariant broken"},
+		{Level: logger.FATAL, Message: "cannot start\nport 80 in use"},
+	}
+	if err := sink.WriteEntries(entries); err != nil {
+		t.Fatalf("failed to write entries: %v", err)
+	}
+
+	wantPriority := []int{PriDebug, PriDebug, PriInfo, PriWarning, PriErr, PriCrit, PriCrit}
+	buf := make([]byte, 64*1024)
+	journal.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i, e := range entries {
+		n, err := journal.Read(buf)
+		if err != nil {
+			t.Fatalf("failed to read datagram %d: %v", i+1, err)
+		}
+		fields := parseDatagram(t, buf[:n])
+		if fields["MESSAGE"] != e.Message {
+			t.Errorf("datagram %d MESSAGE = %q, want %q", i+1, fields["MESSAGE"], e.Message)
+		}
+		if fields["PRIORITY"] != strconv.Itoa(wantPriority[i]) {
+			t.Errorf("datagram %d PRIORITY = %s for level %d, want %d", i+1, fields["PRIORITY"], e.Level, wantPriority[i])
+		}
+		if fields["SYSLOG_IDENTIFIER"] != "api" {
+			t.Errorf("datagram %d SYSLOG_IDENTIFIER = %q, want api", i+1, fields["SYSLOG_IDENTIFIER"])
+		}
+		if e.File != "" {
+			if fields["CODE_FILE"] != e.File || fields["CODE_LINE"] != "42" || fields["USER_ID"] != "7" {
+				t.Errorf("datagram %d has fields %v, want CODE_FILE, CODE_LINE and USER_ID", i+1, fields)
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package journald
+
+import (
+	"errors"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Sink forwards entries to the systemd journal
+type Sink struct{}
+
+// New reports that journald is unavailable on this platform
+func New(opts Options) (*Sink, error) {
+	return nil, errors.New("journald is only supported on linux")
+}
+
+// Hook is a no-op on this platform
+func (s *Sink) Hook(e *logger.Entry) error { return nil }
+
+// WriteEntries is a no-op on this platform
+func (s *Sink) WriteEntries(entries []*logger.Entry) error { return nil }
+
+// Write is a no-op on this platform
+func (s *Sink) Write(p []byte) (int, error) { return len(p), nil }
+
+// Close is a no-op on this platform
+func (s *Sink) Close() error { return nil }
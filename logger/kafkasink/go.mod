@@ -0,0 +1,16 @@
+module github.com/jbarasa/logger/logger/kafkasink
+
+go 1.21
+
+require (
+	github.com/jbarasa/logger v1.0.2
+	github.com/segmentio/kafka-go v0.4.47
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
+
+// Build against the logger in this repository
+replace github.com/jbarasa/logger => ../..
@@ -0,0 +1,171 @@
+// Package kafkasink publishes log entries to a Kafka topic, so services can
+// stream logs straight into a Kafka-based pipeline.
+//
+//	k, err := kafkasink.New(kafkasink.Options{
+//	    Brokers:     []string{"kafka-1:9092", "kafka-2:9092"},
+//	    Topic:       "app-logs",
+//	    KeyField:    "request_id",
+//	    Compression: "zstd",
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{k}})
+//
+// Each entry is published as one message holding logger.Entry's JSON form,
+// with a "level" header. Messages are batched and sent asynchronously by the
+// Kafka writer, so a slow broker never blocks the logger's writer.
+//
+// The package is a separate module so the logger itself does not depend on a
+// Kafka client.
+package kafkasink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+// Options configures the Kafka sink
+type Options struct {
+	Brokers      []string           // Bootstrap brokers, host:port (required)
+	Topic        string             // Topic entries are published to (required)
+	KeyField     string             // Field whose value is the message key, so related entries share a partition (default: no key, round-robin)
+	BatchSize    int                // Messages per produce request (default: 100)
+	BatchTimeout time.Duration      // Longest time a message waits for a batch to fill (default: 1s)
+	Compression  string             // none, gzip, snappy, lz4 or zstd (default: none)
+	RequiredAcks kafka.RequiredAcks // Acknowledgements required from the brokers (default: kafka.RequireOne)
+	OnError      func(err error)    // Called when a batch cannot be delivered (default: ignore)
+}
+
+// Sink publishes entries to Kafka
+type Sink struct {
+	writer   *kafka.Writer
+	keyField string
+}
+
+// New creates a sink publishing to opts.Topic
+func New(opts Options) (*Sink, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, errors.New("kafkasink: at least one broker is required")
+	}
+	if opts.Topic == "" {
+		return nil, errors.New("kafkasink: Topic is required")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.BatchTimeout <= 0 {
+		opts.BatchTimeout = time.Second
+	}
+	if opts.RequiredAcks == 0 {
+		opts.RequiredAcks = kafka.RequireOne
+	}
+
+	var compression kafka.Compression
+	switch strings.ToLower(opts.Compression) {
+	case "", "none":
+	case "gzip":
+		compression = kafka.Gzip
+	case "snappy":
+		compression = kafka.Snappy
+	case "lz4":
+		compression = kafka.Lz4
+	case "zstd":
+		compression = kafka.Zstd
+	default:
+		return nil, fmt.Errorf("kafkasink: unknown compression %q", opts.Compression)
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(opts.Brokers...),
+		Topic:        opts.Topic,
+		BatchSize:    opts.BatchSize,
+		BatchTimeout: opts.BatchTimeout,
+		Compression:  compression,
+		RequiredAcks: opts.RequiredAcks,
+		Async:        true,
+	}
+	if opts.KeyField != "" {
+		// Hashing the key keeps entries with the same key on one partition
+		w.Balancer = &kafka.Hash{}
+	}
+	if opts.OnError != nil {
+		onError := opts.OnError
+		w.Completion = func(messages []kafka.Message, err error) {
+			if err != nil {
+				onError(fmt.Errorf("failed to publish %d log entries to kafka: %v", len(messages), err))
+			}
+		}
+	}
+	return &Sink{writer: w, keyField: opts.KeyField}, nil
+}
+
+// WriteEntries publishes a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	messages := make([]kafka.Message, 0, len(entries))
+	for _, e := range entries {
+		msg, err := s.message(e)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+	}
+	return s.writer.WriteMessages(context.Background(), messages...)
+}
+
+// Hook publishes a single entry; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.WriteEntries([]*logger.Entry{e})
+}
+
+// Write publishes each line of formatted output as an INFO entry, so the
+// sink can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	var entries []*logger.Entry
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		entries = append(entries, &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)})
+	}
+	if err := s.WriteEntries(entries); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends the messages still buffered and closes the connections
+func (s *Sink) Close() error {
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close kafka writer: %v", err)
+	}
+	return nil
+}
+
+// message encodes an entry as a Kafka message
+func (s *Sink) message(e *logger.Entry) (kafka.Message, error) {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to encode log entry: %v", err)
+	}
+	msg := kafka.Message{
+		Value:   value,
+		Time:    e.Time,
+		Headers: []kafka.Header{{Key: "level", Value: []byte(logger.LevelName(e.Level))}},
+	}
+	if s.keyField != "" {
+		for _, f := range e.Fields {
+			if f.Key == s.keyField {
+				msg.Key = []byte(fmt.Sprint(f.Value))
+				break
+			}
+		}
+	}
+	return msg, nil
+}
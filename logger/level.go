@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Level is a log level that converts to and from its name, for command-line
+// flags and configuration files. Convert it with int() where a level is taken:
+//
+//	var level logger.Level = logger.INFO
+//	flag.Var(&level, "log-level", "minimum log level")
+//	flag.Parse()
+//	logger.Initialize(logger.Config{Level: int(level)})
+type Level int
+
+// ParseLevel converts a level name such as "info", "WARN" or "warning" into a Level
+func ParseLevel(name string) (Level, error) {
+	level, err := levelFromName(strings.TrimSpace(name))
+	return Level(level), err
+}
+
+// String returns the level's name, e.g. "WARN"
+func (l Level) String() string {
+	return LevelName(int(l))
+}
+
+// Set parses a level name, making *Level a flag.Value
+func (l *Level) Set(name string) error {
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// MarshalText renders the level's name
+func (l Level) MarshalText() ([]byte, error) {
+	if !knownLevel(int(l)) {
+		return nil, fmt.Errorf("unknown log level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText parses a level name
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// MarshalJSON renders the level's name as a JSON string
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON parses a level name, or a number as used by Config.Level
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '"' && !bytes.Equal(data, []byte("null")) {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("failed to parse log level: %v", err)
+		}
+		if !knownLevel(n) {
+			return fmt.Errorf("unknown log level %d", n)
+		}
+		*l = Level(n)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("failed to parse log level: %v", err)
+	}
+	if name == "" {
+		return nil
+	}
+	return l.Set(name)
+}
+
+// SetLevel changes the minimum level recorded by the logger and all of its
+// children. It is safe to call while other goroutines are logging, e.g. from
+// an admin endpoint that raises verbosity during an incident. On a named
+// logger it sets that name's level (see SetNamedLevel).
+func (l *Logger) SetLevel(level int) {
+	if l.named != nil {
+		l.named.level.Store(int64(level))
+		return
+	}
+	l.level.Store(int64(level))
+}
+
+// GetLevel returns the current minimum level
+func (l *Logger) GetLevel() int {
+	return l.minLevel()
+}
+
+// minLevel loads the current minimum level: the closest named override, else
+// the root level
+func (l *Logger) minLevel() int {
+	for n := l.named; n != nil; n = n.parent {
+		if level := n.level.Load(); level != levelUnset {
+			return int(level)
+		}
+	}
+	return int(l.level.Load())
+}
+
+// SetLevel changes the minimum level of the default logger
+func SetLevel(level int) {
+	if defaultLogger != nil {
+		defaultLogger.SetLevel(level)
+	}
+}
+
+// GetLevel returns the minimum level of the default logger
+func GetLevel() int {
+	if defaultLogger != nil {
+		return defaultLogger.GetLevel()
+	}
+	return DEBUG
+}
+
+// IsLevelEnabled reports whether entries at level are written, so callers can
+// skip preparing arguments that would be discarded
+func (l *Logger) IsLevelEnabled(level int) bool {
+	return l != nil && !levelBelow(level, l.minLevel())
+}
+
+// DebugEnabled reports whether DEBUG entries are written
+func (l *Logger) DebugEnabled() bool {
+	return l.IsLevelEnabled(DEBUG)
+}
+
+// TraceEnabled reports whether TRACE entries are written
+func (l *Logger) TraceEnabled() bool {
+	return l.IsLevelEnabled(TRACE)
+}
+
+// IsLevelEnabled reports whether the default logger writes entries at level
+func IsLevelEnabled(level int) bool {
+	return defaultLogger.IsLevelEnabled(level)
+}
+
+// DebugEnabled reports whether the default logger writes DEBUG entries
+func DebugEnabled() bool {
+	return defaultLogger.IsLevelEnabled(DEBUG)
+}
+
+// TraceEnabled reports whether the default logger writes TRACE entries
+func TraceEnabled() bool {
+	return defaultLogger.IsLevelEnabled(TRACE)
+}
+
+// CheckedEntry logs at a level Check found enabled
+type CheckedEntry struct {
+	l     *Logger
+	level int
+}
+
+// Check returns a CheckedEntry for level when entries at it are written, and
+// nil otherwise, so expensive arguments are only built when needed:
+//
+//	if ce := l.Check(logger.DEBUG); ce != nil {
+//	    ce.LogKV("cache state", logger.Fields{"dump": cache.Dump()})
+//	}
+func (l *Logger) Check(level int) *CheckedEntry {
+	if !l.IsLevelEnabled(level) {
+		return nil
+	}
+	return &CheckedEntry{l: l, level: level}
+}
+
+// Check returns a CheckedEntry of the default logger for level, or nil
+func Check(level int) *CheckedEntry {
+	return defaultLogger.Check(level)
+}
+
+// Log logs a formatted message. At PANIC and FATAL it behaves like Panic and Fatal.
+func (ce *CheckedEntry) Log(format string, args ...interface{}) {
+	if ce.level == PANIC {
+		msg := fmt.Sprintf(format, args...)
+		ce.l.log(PANIC, flagPriority, "%s", msg)
+		panic(msg)
+	}
+	ce.l.log(ce.level, checkedFlags(ce.level), format, args...)
+}
+
+// LogKV logs a message with structured fields. At PANIC and FATAL it behaves
+// like PanicKV and FatalKV.
+func (ce *CheckedEntry) LogKV(msg string, fields Fields) {
+	ce.l.logFields(ce.level, checkedFlags(ce.level), msg, fields.sorted())
+	if ce.level == PANIC {
+		panic(msg)
+	}
+}
+
+// checkedFlags returns the flags the level's own methods log with
+func checkedFlags(level int) entryFlags {
+	if !levelBelow(level, PANIC) {
+		return flagPriority
+	}
+	return 0
+}
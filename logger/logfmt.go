@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// defaultLogfmtTimeFormat is the ts layout of logfmt output
+const defaultLogfmtTimeFormat = time.RFC3339Nano
+
+// appendLogfmtEntry renders a log entry as a logfmt line:
+// ts=... level=info caller=main.go:42 msg="server started" key=value ...
+func (l *Logger) appendLogfmtEntry(buf *bytes.Buffer, entry *logEntry, caller string) {
+	buf.WriteString("ts=")
+	if l.isEpochFormat() {
+		l.appendTime(buf, entry.timestamp, defaultLogfmtTimeFormat)
+	} else {
+		start := buf.Len()
+		l.appendTime(buf, entry.timestamp, defaultLogfmtTimeFormat)
+		if needsQuote(buf.Bytes()[start:]) {
+			// Only custom layouts with spaces get here
+			ts := string(buf.Bytes()[start:])
+			buf.Truncate(start)
+			appendQuoted(buf, ts)
+		}
+	}
+	appendLogfmtHeader(buf, entry.level, caller, entry.msg)
+	if function := l.callerFunction(entry); function != "" {
+		buf.WriteString(" func=")
+		appendQuoted(buf, function)
+	}
+	appendLogfmtFields(buf, l.serviceFields)
+	appendLogfmtFields(buf, entry.fields)
+	buf.WriteByte('\n')
+}
+
+// appendLogfmtHeader writes the level, caller and msg pairs
+func appendLogfmtHeader[S string | []byte](buf *bytes.Buffer, level int, caller string, msg S) {
+	buf.WriteString(" level=")
+	start := buf.Len()
+	buf.WriteString(LevelName(level))
+	for i, c := range buf.Bytes()[start:] {
+		if c >= 'A' && c <= 'Z' {
+			buf.Bytes()[start+i] = c + 'a' - 'A'
+		}
+	}
+	if caller != "" {
+		buf.WriteString(" caller=")
+		appendQuoted(buf, caller)
+	}
+	buf.WriteString(" msg=")
+	appendQuoted(buf, msg)
+}
+
+// appendLogfmtFields writes fields as key=value pairs with logfmt-safe keys
+func appendLogfmtFields(buf *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(logfmtKey(f.Key))
+		buf.WriteByte('=')
+		if h, ok := f.Value.(HexData); ok {
+			// Single-line output has no room for a dump; keep the bytes
+			buf.WriteString(hex.EncodeToString(h.Data))
+			continue
+		}
+		appendTextValue(buf, f.Value)
+	}
+}
+
+// logfmtKey replaces characters that would break a logfmt key
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// LogfmtFormatter writes entries as logfmt, which Loki and many other tools
+// parse natively. Setting it in Config.Formatter is the same as Format: Logfmt.
+type LogfmtFormatter struct{}
+
+// Format renders e as a logfmt line
+func (LogfmtFormatter) Format(e Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("ts=")
+	buf.WriteString(e.Time.Format(defaultLogfmtTimeFormat))
+	appendLogfmtHeader(&buf, e.Level, e.caller(), e.Message)
+	appendLogfmtFields(&buf, e.Fields)
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
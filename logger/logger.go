@@ -4,13 +4,53 @@
 // Version: 1.0.2
 //
 // Features:
-// - Automatic cleanup of logs older than 1 month
-// - Multiple log levels with color-coded console output
-// - Asynchronous logging with buffered channels
-// - Stack trace support for error debugging
+// - Automatic cleanup of archives by age (MaxAge), count (MaxBackups) and total size (MaxTotalSize)
+// - Multiple log levels with color-coded console output, adjustable at runtime
+// - Custom levels with their own severity, name and color, and renaming of built-in levels
+// - Asynchronous logging with buffered channels, with Flush for durable checkpoints
+// - Synchronous mode that writes each entry before the log call returns
+// - Configurable fsync policy: never, every batch, every N bytes or on errors
+// - Panic recovery that logs the panic, flushes queued entries and re-panics
+// - Stack trace support for error debugging, attached automatically from a chosen level
 // - Thread-safe operations
+// - Configurable caller reporting: path form, function name, wrapper skips, or off
 // - Configurable buffer sizes
-// - Log file rotation with numbered backup files
+// - Log file rotation by size (numbered backups) or time (dated backups), or lumberjack-style names
+// - Reopening of the log file after external rotation (logrotate), on SIGHUP or by polling
+// - Optional gzip compression of rotated archives and AES-GCM encryption at rest
+// - Structured key/value fields with typed constructors, lazily computed values and optional validation
+// - Plain text, JSON or logfmt file output, or a custom Formatter
+// - Service name, version, environment, hostname and PID attached to every entry
+// - Stdout/stderr-only mode for containers, with no file or directories
+// - Point-in-time snapshots of the current log file
+// - In-memory ring buffer of recent entries, dumped on demand or on SIGUSR1
+// - Level-filtered hook chain to enrich, drop or forward entries, and error hooks for internal failures
+// - Live subscriptions streaming written entries to in-process consumers
+// - Independent logger instances alongside the package-level default logger
+// - Child loggers with bound fields (With) or message prefixes (WithPrefix), and named loggers with their own levels
+// - Request-scoped fields carried in a context.Context
+// - log/slog handler and io.Writer adapters for third-party libraries
+// - net/http access logging middleware (httplog) and gRPC interceptors (grpclog module)
+// - OpenTelemetry trace correlation and span events (otellog module)
+// - Sampling of repeated messages per level
+// - Token-bucket rate limiting, globally, per level or per call site, with summaries of suppressed entries
+// - Collapsing of consecutive identical entries into "last message repeated N times"
+// - Pluggable sinks for extra destinations, plus fallbacks used while the file is unwritable
+// - Level-based routing of entries to separate files or sinks
+// - Journald and syslog (RFC 5424/3164) sinks in the journald and syslog subpackages
+// - Batched HTTP(S) sink with gzip, retries and an on-disk overflow buffer (httpsink)
+// - Kafka sink with keyed partitioning and compression (kafkasink module)
+// - Grafana Loki push sink with stream labels, batching and retries (lokisink)
+// - GELF sink for Graylog over chunked UDP or TCP (gelf)
+// - OpenTelemetry collector sink over OTLP/HTTP or OTLP/gRPC (otlpsink module)
+// - Asynchronous forwarding of errors to Sentry or other trackers (errtrack)
+// - Tamper-evident audit logs with an HMAC chain (AuditLogger, VerifyAudit)
+// - Regex-based PII masking and redaction of sensitive fields by name
+// - Adaptive load shedding under sustained buffer pressure
+// - Internal metrics via Stats, also in Prometheus text format
+// - Configuration from JSON, YAML or TOML files and LOG_* variables, reloaded on change or SIGHUP
+// - Ordered shutdown, optionally bounded by a timeout or context
+// - A small Interface for libraries, with Nop for silencing them in tests
 //
 // Example usage:
 //
@@ -28,27 +68,41 @@
 //
 //	logger.Info("Server started on port %d", 8080)
 //	logger.Error("Database error: %v", err)
+//	logger.InfoKV("User logged in", logger.Fields{"user_id": 42})
+//
+// Independent instances can be created with New:
+//
+//	access, err := logger.New(logger.Config{LogPath: "storage/logs/access.log"})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer access.Close()
+//	access.Info("GET /health 200")
 package logger
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
-	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Log levels define the severity of the log message
 const (
-	DEBUG = iota // Detailed information for debugging
-	INFO         // General information about program execution
-	WARN         // Warning messages for potentially harmful situations
-	ERROR        // Error messages for serious problems
-	FATAL        // Critical errors that require immediate attention (exits program)
+	TRACE = iota - 1 // Very verbose internals such as per-packet or per-query logging
+	DEBUG            // Detailed information for debugging
+	INFO             // General information about program execution
+	WARN             // Warning messages for potentially harmful situations
+	ERROR            // Error messages for serious problems
+	PANIC            // Errors that leave the caller unable to continue (flushes, then panics)
+	FATAL            // Critical errors that require immediate attention (exits program)
 )
 
 // ANSI color codes for console output
@@ -59,26 +113,50 @@ const (
 	colorYellow = "\033[33m" // Warning messages
 	colorBlue   = "\033[34m" // Debug messages
 	colorPurple = "\033[35m" // Fatal messages
+	colorBold   = "\033[1m"  // Combined with a color for panic messages
+	colorGray   = "\033[90m" // Trace messages
 )
 
-// Level names for log output
+// Built-in level names for log output (see RegisterLevel)
 var levelNames = map[int]string{
+	TRACE: "TRACE",
 	DEBUG: "DEBUG",
 	INFO:  "INFO",
 	WARN:  "WARN",
 	ERROR: "ERROR",
+	PANIC: "PANIC",
 	FATAL: "FATAL",
 }
 
-// Color mapping for log levels
+// Built-in color mapping for log levels (see RegisterLevel)
 var levelColors = map[int]string{
+	TRACE: colorGray,
 	DEBUG: colorBlue,
 	INFO:  colorGreen,
 	WARN:  colorYellow,
 	ERROR: colorRed,
+	PANIC: colorBold + colorRed,
 	FATAL: colorPurple,
 }
 
+// maxBatchBuffer is the largest output buffer kept for the next batch
+const maxBatchBuffer = 4 * 1024 * 1024
+
+// msgPool holds buffers for rendering messages before they are copied into entries
+var msgPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 1024)) // 1KB for messages
+	},
+}
+
+// releaseMsg returns a message buffer to the pool unless a huge message grew it
+func releaseMsg(buf *bytes.Buffer) {
+	if buf.Cap() <= 64*1024 {
+		buf.Reset()
+		msgPool.Put(buf)
+	}
+}
+
 var entryPool = sync.Pool{
 	New: func() interface{} {
 		return &logEntry{
@@ -90,90 +168,562 @@ var entryPool = sync.Pool{
 // logEntry represents a single log message
 type logEntry struct {
 	level     int
+	flags     entryFlags
 	msg       []byte
+	pc        uintptr
 	file      string
 	line      int
 	timestamp int64
+	fields    []Field
+	ctx       context.Context
+	done      chan error // Strict caller waiting for the outcome
 }
 
+// entryFlags adjust how an individual entry is treated by load-shedding policies
+type entryFlags uint8
+
+const (
+	flagPriority entryFlags = 1 << iota // Must be logged: bypasses sampling, rate limiting, dedup and load shedding, waits on a full buffer
+	flagNoExit                          // FATAL entry that flushes instead of closing the logger and exiting
+)
+
 // Config defines the configuration options for the logger
 type Config struct {
-	LogPath     string // Path for log file (with extension)
-	Level       int    // Minimum log level to record
-	BufferSize  int    // Size of the log buffer channel
-	IsDev       bool   // Development mode (enables console output)
-	MaxFileSize int64  // Maximum file size in bytes before rotation (default: 25MB)
+	LogPath     string     // Path for log file (with extension)
+	StdoutOnly  bool       // Write to stdout, ERROR and above to stderr, instead of a file; no directories or file are created
+	Level       int        // Minimum log level to record
+	BufferSize  int        // Size of the log buffer channel
+	Sync        bool       // Write each entry before the log call returns instead of queueing it
+	IsDev       bool       // Development mode (enables console output)
+	Color       ColorMode  // Console colors: ColorAuto, ColorAlways or ColorNever (default: ColorAuto)
+	ColorTheme  ColorTheme // Level colors of the console output, e.g. HighContrastTheme() (default: DefaultTheme())
+	MaxFileSize int64      // Maximum file size in bytes before rotation (default: 25MB)
+	Format      Format     // File output format: Text, JSON or Logfmt (default: Text)
+	Formatter   Formatter  // Custom line layout for the file and sinks, replacing Format
+
+	ConsoleWriter io.Writer    // Destination of the console output of IsDev and Console, kept apart from the program's stdout (default: os.Stderr)
+	ConsoleStyle  ConsoleStyle // Layout of console output: ConsoleStandard or ConsolePretty (default: ConsoleStandard)
+
+	TimeFormat   string         // Timestamp layout, or TimeFormatUnix/TimeFormatUnixMilli/TimeFormatUnixNano (default: "2006/01/02 15:04:05" for text, RFC3339Nano for JSON and logfmt)
+	TimeLocation *time.Location // Time zone of timestamps, e.g. time.UTC (default: time.Local)
+
+	BatchSize     int           // Entries written together in one write (default: 50000)
+	FlushInterval time.Duration // Longest time an entry waits for its batch to fill before it is written (default: 1ms)
+	FormatWorkers int           // Goroutines rendering large batches in parallel, their lines still written in order (default: 1)
+
+	RotateEvery   Rotation      // Time-based rotation: RotateNone, RotateHourly or RotateDaily (default: RotateNone)
+	Compress      bool          // Gzip archives after rotation (archive/app-1.log becomes archive/app-1.log.gz)
+	ArchiveNaming ArchiveNaming // How archives are named: ArchiveNumbered or ArchiveLumberjack (default: ArchiveNumbered)
+
+	ReopenOnSignal bool          // Reopen LogPath on SIGHUP, for logrotate's postrotate (unix only)
+	ReopenCheck    time.Duration // How often to check whether LogPath was moved or truncated by another tool, reopening it if so (0 disables)
+
+	SyncPolicy SyncPolicy // When the log file is synced to disk: SyncNever, SyncEveryBatch, SyncEveryBytes or SyncOnError (default: SyncNever)
+	SyncBytes  int64      // Bytes written between syncs under SyncEveryBytes (default: 1MB)
+
+	WriteBufferSize int   // Bytes of batches collected in memory for one write to the log file, written as soon as the buffer goes idle (0 writes each batch)
+	Preallocate     int64 // Disk space reserved ahead of the end of the log file with fallocate, in bytes (linux only; 0 disables)
+
+	EncryptionKey []byte // AES-GCM key (16, 24 or 32 bytes) encrypting the log file at rest; see KeyFromEnv and Decrypt
+
+	MaxAge       time.Duration // Remove archives older than this (0 keeps them forever)
+	MaxBackups   int           // Keep at most this many archives, removing the oldest (0 keeps all)
+	MaxTotalSize int64         // Cap in bytes on the log file plus its archives, removing the oldest archives (0: no cap)
+
+	Upload *UploadConfig // Ship archives to object storage such as S3 or GCS after rotation (nil disables)
+
+	ValidateFields bool // Warn in development mode about empty keys or nil values in structured fields
+
+	// CallerFormatter renders the caller segment of each line from the absolute
+	// file path, line number and fully qualified function name (default: relative-path:line)
+	CallerFormatter func(file string, line int, function string) string
+
+	Caller CallerConfig // Caller lookup: disable it, add the function name, choose the path form or skip wrapper frames
+
+	RingBufferSize int  // Number of recent entries (all levels) kept in memory for dumps (0 disables)
+	DumpOnSignal   bool // Write the ring buffer to the log file on SIGUSR1 (unix only)
+
+	Sampling *SamplingConfig // Thin out repeated messages per level: first N per tick, then every Mth (nil disables)
+	Dedup    *DedupConfig    // Collapse consecutive identical entries into one "last message repeated N times" entry (nil disables)
+
+	NamedLevels map[string]int // Levels of named loggers (see Named), e.g. {"db": logger.DEBUG}
+
+	Stacktrace *StacktraceConfig // Attach a "stack" field to entries at or above a level (nil disables)
+
+	RateLimit       float64       // Steady-state entries per second allowed through, per RateLimitBy key (0 disables rate limiting)
+	RateBurst       int           // Entries allowed in a burst before RateLimit applies (default: RateLimit)
+	RateLimitBy     RateLimitKey  // What the limit applies to: RateLimitGlobal, RateLimitPerLevel or RateLimitPerCallSite (default: RateLimitGlobal)
+	RateLimitReport time.Duration // How often a WARN summary of suppressed entries is logged (default: 10s; negative disables)
+
+	InternalErrorWriter io.Writer // Destination for the logger's own errors (default: os.Stderr)
+
+	OnError ErrorHook // Called with an *OpError on write, rotation, sink and other internal failures, and for dropped entries
+
+	ExitFunc func(code int) // Called by Fatal after the logger is closed (default: os.Exit)
+
+	Service     ServiceMetadata // Service name, version, environment and instance attached to every entry
+	ServiceName string          // Shorthand for Service.Name
+	Environment string          // Shorthand for Service.Environment
+	HostInfo    bool            // Attach the hostname and process ID to every entry as "host" and "pid"
+
+	ContextExtractors []ContextExtractor // Derive fields from the context given to WithContext, e.g. otellog.TraceFields
+
+	Sinks         []Sink  // Additional destinations that receive every entry alongside the file
+	Routes        []Route // Destinations that receive only entries at selected levels (e.g. an error-only file)
+	FileLevels    []int   // Levels written to LogPath (default: all); see LevelsFrom
+	Console       bool    // Also print entries to stdout as in development mode, without its field warnings
+	ConsoleLevels []int   // Levels printed to stdout by IsDev or Console alongside the file (default: all); see LevelsFrom
+	FallbackSinks []Sink  // Sinks tried in order when the log file cannot be written
+
+	PIIPatterns []string // Regular expressions masked in messages and string field values (see PIIEmail, PIICreditCard, PIISSN)
+	PIIMask     string   // Replacement for PII matches (default: "[REDACTED]")
+	RedactKeys  []string // Field names whose values are always masked, also as key=value in messages (see SensitiveKeys)
+
+	MaxMessageSize int            // Longest message in bytes, as protection against logging a huge payload (default: 0, unlimited)
+	OversizePolicy OversizePolicy // What to do with longer messages: OversizeTruncate, OversizeSplit or OversizeDrop (default: OversizeTruncate)
+
+	TimingLevel int // Level of the entries of Span and TimeTrack (default: DEBUG)
+
+	OverflowPolicy OverflowPolicy // What to do when the buffer is full: OverflowDrop, OverflowBlock, OverflowBlockWithTimeout or OverflowSpill (default: OverflowDrop)
+	BlockTimeout   time.Duration  // Longest wait for buffer space under OverflowBlockWithTimeout (default: 100ms)
+	SpillPath      string         // File OverflowSpill queues entries in (default: LogPath with ".spill" appended)
+	SpillMaxSize   int64          // Largest spill file in bytes; entries that do not fit are dropped (default: 100MB)
+	DropReport     time.Duration  // How often a WARN summary of entries dropped on a full buffer is logged (default: 10s; negative disables)
+
+	AdaptiveShedding  bool    // Drop a growing share of non-priority entries while the buffer is under pressure
+	ShedHighWatermark float64 // Buffer usage (0-1) at which shedding starts (default: 0.8)
+	ShedLowWatermark  float64 // Buffer usage (0-1) at which shedding stops (default: 0.5)
 }
 
-// Logger represents the core logger structure
+// Logger represents the core logger structure. Child loggers (see WithContext)
+// share the parent's core and add their own bound fields.
 type Logger struct {
-	file       *os.File       // Current log file handle
-	level      int            // Current minimum log level
-	logPath    string         // Path for log file
-	logChan    chan *logEntry // Channel for async logging
-	done       chan struct{}  // Channel for shutdown signaling
-	wg         sync.WaitGroup // Wait group for graceful shutdown
-	bufferSize int            // Size of the log buffer
-	isDev      bool           // Development mode flag
-	maxSize    int64          // Maximum file size before rotation
-	currSize   int64          // Current file size
-	mu         sync.Mutex     // Mutex for file operations
+	*loggerCore
+	fields     []Field         // Fields bound to this logger, attached to every entry
+	name       string          // Name given with Named
+	named      *namedLevel     // Level override of the name, nil for unnamed loggers
+	callerSkip int             // Frames added with AddCallerSkip
+	prefix     string          // Message prefix added with WithPrefix
+	ctx        context.Context // Context given to WithContext, passed on to hooks
+	strict     *strictCall     // Outcome of the entry of a Strict call
+	at         time.Time       // Time of a slog record, zero for the time of the call
+}
+
+// loggerCore holds the state shared by a logger and its children
+type loggerCore struct {
+	file       *os.File           // Current log file handle, nil in console mode
+	console    bool               // StdoutOnly: entries go to stdout and stderr
+	level      atomic.Int64       // Current minimum log level, changed with SetLevel
+	logPath    string             // Path for log file
+	logChan    chan *logEntry     // Channel for async logging
+	done       chan struct{}      // Channel for shutdown signaling
+	abort      chan struct{}      // Closed when a shutdown deadline passes
+	flushReq   chan chan struct{} // Requests to write out all queued entries
+	reloadReq  chan func()        // Changes applied on the writer goroutine between batches
+	wg         sync.WaitGroup     // Wait group for graceful shutdown
+	bufferSize int                // Size of the log buffer
+	syncWrite  bool               // Sync mode: callers write their own entries
+	batchMu    sync.Mutex         // Serializes writeBatch and writer-side reloads
+	batchSize  int                // Entries written together
+	workers    int                // FormatWorkers
+	workerBufs []*workerBuf       // Output of the formatting workers, reused across batches
+	flushDelay time.Duration      // Longest wait for a batch to fill
+	isDev      bool               // Development mode flag
+	tee        bool               // Console output alongside the file (IsDev or Console)
+	consoleOut io.Writer          // Destination of tee output
+	pretty     bool               // ConsolePretty layout for console output
+	color      bool               // Color console output
+	theme      ColorTheme         // Level colors overriding the defaults
+	maxSize    int64              // Maximum file size before rotation
+	currSize   int64              // Current file size
+	unsynced   int64              // Bytes written since the file was last synced
+	writeBuf   []byte             // Lines waiting for one write under WriteBufferSize
+	writeCap   int                // WriteBufferSize, 0 when writes are not collected
+	prealloc   int64              // Space reserved ahead of the file's end, 0 when disabled
+	allocEnd   int64              // Offset up to which space is reserved
+	mu         sync.Mutex         // Mutex for file operations
+	writeErr   error              // Set by writeFallback when no destination took a write, guarded by mu
+
+	validateFields  bool                                                // Check structured fields before enqueueing
+	callerFormatter func(file string, line int, function string) string // Custom caller rendering
+	callerOff       bool                                                // Skip caller lookup
+	callerFunc      bool                                                // Write the calling function
+	callerPath      CallerPath                                          // Form of the caller's file path
+	callerDepth     int                                                 // Frames skipped for every entry
+	callers         map[callerKey]string                                // Rendered callers by file and line
+	callersMu       sync.RWMutex                                        // Guards callers
+	batchBuf        *bytes.Buffer                                       // Output buffer reused by writeBatch
+	batchEnds       []int                                               // Line end offsets reused by writeBatch
+	pwd             string                                              // Working directory at startup, base of relative caller paths
+	ring            *ringBuffer                                         // Recent entries across all levels
+	hooks           []*hook                                             // Entry observers, replaced on write
+	errHooks        []ErrorHook                                         // Operational error observers, replaced on write
+	hooksMu         sync.RWMutex                                        // Guards hooks and errHooks
+	subs            []*Subscription                                     // Live entry streams, replaced on write
+	subsClosed      bool                                                // Set once subscribers were told to drain
+	subsMu          sync.RWMutex                                        // Guards subs and subsClosed
+	subsWG          sync.WaitGroup                                      // Tracks subscriber goroutines
+	closed          atomic.Bool                                         // Set once shutdown has begun
+	closeMu         sync.RWMutex                                        // Orders setting closed against the start of sends
+	sending         sync.WaitGroup                                      // Sends in flight, waited for by shutdown
+	closeOnce       sync.Once                                           // Runs the shutdown sequence once
+	limiter         *rateLimiter                                        // Rate limiter, nil when disabled
+	namedLevels     map[string]*namedLevel                              // Level overrides of named loggers
+	namedMu         sync.Mutex                                          // Guards namedLevels
+	encrypter       *encrypter                                          // Encrypts file writes, nil when disabled
+	stacktracer     *stacktracer                                        // Automatic stack traces, nil when disabled
+	sampler         *sampler                                            // Repeated-message sampling, nil when disabled
+	dedup           *deduper                                            // Collapses consecutive repeats, nil when disabled
+	stats           stats                                               // Internal counters
+	errWriter       io.Writer                                           // Destination for internal errors
+	errMu           sync.Mutex                                          // Serializes writes to errWriter
+	serviceFields   []Field                                             // Service metadata attached to every entry
+	serviceJSON     []Field                                             // Service metadata keyed for the JSON "service" object
+	format          Format                                              // File output format
+	formatter       Formatter                                           // Custom line layout, nil for the built-in formats
+	rotateEvery     Rotation                                            // Time-based rotation policy
+	naming          ArchiveNaming                                       // Archive naming scheme
+	period          time.Time                                           // Start of the rotation period of the current file
+	nextRotation    time.Time                                           // When the current period ends
+	compress        bool                                                // Gzip archives after rotation
+	compressWG      sync.WaitGroup                                      // Tracks background compressions
+	busyArchives    sync.Map                                            // Archives being compressed or uploaded, kept from retention
+	upload          *UploadConfig                                       // Archive upload settings, nil when disabled
+	uploadCh        chan string                                         // Archives waiting for upload
+	uploadWG        sync.WaitGroup                                      // Tracks the upload goroutine
+	maxAge          time.Duration                                       // Archive age limit
+	maxBackups      int                                                 // Archive count limit
+	maxTotalSize    int64                                               // Limit on the log file plus archives in bytes
+	cleanupReq      chan struct{}                                       // Wakes the retention goroutine after rotation
+	sinks           []Sink                                              // Receive every batch alongside the file
+	fallbacks       []Sink                                              // Used in order when the file write fails
+	routes          []route                                             // Level-filtered destinations
+	fileLevels      map[int]bool                                        // Levels written to the file, nil for all
+	consoleLevels   map[int]bool                                        // Levels printed by the console tee, nil for all
+	piiPatterns     []*regexp.Regexp                                    // Compiled PII patterns
+	piiMask         []byte                                              // Replacement for PII matches
+	redactKeys      map[string]bool                                     // Lowercased field names whose values are masked
+	redactKeysRe    *regexp.Regexp                                      // Matches key=value pairs of redactKeys in messages
+	shedder         *loadShedder                                        // Adaptive load shedding, nil when disabled
+	exitFunc        func(code int)                                      // Ends the process after a fatal entry
+	timeFormat      string                                              // Timestamp layout or epoch format
+	timeLocation    *time.Location                                      // Time zone of timestamps
+	overflow        OverflowPolicy                                      // Behavior when the buffer is full
+	maxMessage      int                                                 // MaxMessageSize, 0 for unlimited
+	oversize        OversizePolicy                                      // Handling of messages over maxMessage
+	timingLevel     int                                                 // Level of Span and TimeTrack entries
+	extractors      []ContextExtractor                                  // Fields derived from contexts in WithContext
+	syncPolicy      SyncPolicy                                          // When the file is synced after writes
+	syncBytes       int64                                               // Sync threshold for SyncEveryBytes
+	blockTimeout    time.Duration                                       // Wait limit for OverflowBlockWithTimeout
+	spill           *spiller                                            // On-disk queue of OverflowSpill, nil when disabled
 }
 
 var defaultLogger *Logger
 
-// Initialize creates a new logger instance
-func Initialize(config Config) error {
-	if config.LogPath == "" {
+// New creates an independent logger instance. Several loggers (e.g. an access
+// log and an application log) can coexist, each with its own file and settings.
+func New(config Config) (*Logger, error) {
+	if config.LogPath == "" && !config.StdoutOnly {
 		pwd, _ := os.Getwd()
 		config.LogPath = filepath.Join(pwd, "storage", "logs", "app.log")
 	}
 
-	// Create logs directory and archive subdirectory
-	logsDir := filepath.Dir(config.LogPath)
-	archiveDir := filepath.Join(logsDir, "archive")
-	if err := os.MkdirAll(archiveDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directories: %v", err)
+	if config.StdoutOnly {
+		// File-only features have nothing to act on
+		config.RotateEvery = RotateNone
+		config.Compress = false
+		config.EncryptionKey = nil
+		config.MaxAge = 0
+		config.MaxBackups = 0
+		config.MaxTotalSize = 0
+		config.Upload = nil
+		config.SyncPolicy = SyncNever
+	} else {
+		// Create logs directory and archive subdirectory; lumberjack-style
+		// archives sit beside the log file
+		dir := filepath.Join(filepath.Dir(config.LogPath), "archive")
+		if config.ArchiveNaming == ArchiveLumberjack {
+			dir = filepath.Dir(config.LogPath)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directories: %v", err)
+		}
 	}
 
 	if config.BufferSize == 0 {
 		config.BufferSize = 100000
 	}
 
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50000
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Millisecond
+	}
+
 	if config.MaxFileSize == 0 {
 		config.MaxFileSize = 25 * 1024 * 1024 // 25MB default
 	}
 
-	// Open log file
-	file, err := os.OpenFile(config.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %v", err)
+	if config.SyncPolicy == SyncEveryBytes && config.SyncBytes <= 0 {
+		config.SyncBytes = 1024 * 1024 // 1MB default
+	}
+
+	if config.ConsoleWriter == nil {
+		config.ConsoleWriter = os.Stderr
+	}
+
+	if config.InternalErrorWriter == nil {
+		config.InternalErrorWriter = os.Stderr
 	}
 
-	// Get current file size
-	info, err := file.Stat()
+	if config.OverflowPolicy == OverflowBlockWithTimeout && config.BlockTimeout <= 0 {
+		config.BlockTimeout = 100 * time.Millisecond
+	}
+
+	if config.OverflowPolicy == OverflowSpill && !config.Sync {
+		if config.SpillPath == "" {
+			if config.StdoutOnly {
+				return nil, fmt.Errorf("OverflowSpill requires SpillPath in stdout-only mode")
+			}
+			config.SpillPath = config.LogPath + ".spill"
+		}
+		if config.SpillMaxSize <= 0 {
+			config.SpillMaxSize = defaultSpillMaxSize
+		}
+	}
+
+	if config.ExitFunc == nil {
+		config.ExitFunc = os.Exit
+	}
+
+	if config.PIIMask == "" {
+		config.PIIMask = defaultPIIMask
+	}
+
+	piiPatterns, err := compilePII(config.PIIPatterns)
 	if err != nil {
-		file.Close()
-		return fmt.Errorf("failed to get file info: %v", err)
+		return nil, err
+	}
+	redactKeys, redactKeysRe := compileRedactKeys(config.RedactKeys)
+	service := config.serviceMetadata()
+
+	var enc *encrypter
+	if len(config.EncryptionKey) > 0 {
+		if enc, err = newEncrypter(config.EncryptionKey); err != nil {
+			return nil, err
+		}
 	}
 
-	logger := &Logger{
+	var file *os.File
+	var info os.FileInfo
+	if !config.StdoutOnly {
+		// Open log file
+		file, err = os.OpenFile(config.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+
+		// Get current file size
+		info, err = file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to get file info: %v", err)
+		}
+	}
+
+	var spill *spiller
+	if config.OverflowPolicy == OverflowSpill && !config.Sync {
+		if spill, err = openSpill(config.SpillPath, config.SpillMaxSize); err != nil {
+			if file != nil {
+				file.Close()
+			}
+			return nil, err
+		}
+	}
+
+	logger := &Logger{loggerCore: &loggerCore{
 		file:       file,
-		level:      config.Level,
+		console:    config.StdoutOnly,
 		logPath:    config.LogPath,
 		logChan:    make(chan *logEntry, config.BufferSize),
 		done:       make(chan struct{}),
+		abort:      make(chan struct{}),
+		reloadReq:  make(chan func()),
+		flushReq:   make(chan chan struct{}),
 		wg:         sync.WaitGroup{},
 		bufferSize: config.BufferSize,
+		syncWrite:  config.Sync,
+		batchSize:  config.BatchSize,
+		workers:    config.FormatWorkers,
+		flushDelay: config.FlushInterval,
 		isDev:      config.IsDev,
+		tee:        config.IsDev || config.Console,
+		consoleOut: config.ConsoleWriter,
+		pretty:     config.ConsoleStyle == ConsolePretty,
+		color:      (config.IsDev || config.Console) && useColor(config.Color, config.ConsoleWriter),
+		theme:      config.ColorTheme.clone(),
 		maxSize:    config.MaxFileSize,
-		currSize:   info.Size(),
+
+		validateFields:  config.ValidateFields,
+		callerFormatter: config.CallerFormatter,
+		callerOff:       config.Caller.Disable,
+		callerFunc:      config.Caller.Function,
+		callerPath:      config.Caller.Path,
+		callerDepth:     config.Caller.Skip,
+		errWriter:       config.InternalErrorWriter,
+		serviceFields:   service.fields(),
+		serviceJSON:     service.jsonFields(),
+		format:          config.Format,
+		rotateEvery:     config.RotateEvery,
+		naming:          config.ArchiveNaming,
+		compress:        config.Compress,
+		maxAge:          config.MaxAge,
+		maxBackups:      config.MaxBackups,
+		maxTotalSize:    config.MaxTotalSize,
+		sinks:           config.Sinks,
+		fallbacks:       config.FallbackSinks,
+		fileLevels:      levelSet(config.FileLevels),
+		consoleLevels:   levelSet(config.ConsoleLevels),
+		piiPatterns:     piiPatterns,
+		piiMask:         []byte(config.PIIMask),
+		redactKeys:      redactKeys,
+		redactKeysRe:    redactKeysRe,
+		exitFunc:        config.ExitFunc,
+		timeFormat:      config.TimeFormat,
+		timeLocation:    config.TimeLocation,
+		overflow:        config.OverflowPolicy,
+		maxMessage:      config.MaxMessageSize,
+		oversize:        config.OversizePolicy,
+		timingLevel:     config.TimingLevel,
+		extractors:      config.ContextExtractors,
+		syncPolicy:      config.SyncPolicy,
+		syncBytes:       config.SyncBytes,
+		blockTimeout:    config.BlockTimeout,
+		spill:           spill,
+	}}
+
+	if !config.StdoutOnly && !config.Sync && config.WriteBufferSize > 0 {
+		logger.writeCap = config.WriteBufferSize
+		logger.writeBuf = make([]byte, 0, config.WriteBufferSize+64*1024)
+	}
+	if !config.StdoutOnly && canPreallocate && config.Preallocate > 0 {
+		logger.prealloc = config.Preallocate
+	}
+
+	logger.level.Store(int64(config.Level))
+	logger.pwd, _ = os.Getwd()
+	if info != nil {
+		logger.currSize = info.Size()
+	}
+
+	if config.OnError != nil {
+		logger.errHooks = []ErrorHook{config.OnError}
+	}
+
+	// The built-in formatters map onto the native formats
+	switch config.Formatter.(type) {
+	case nil:
+	case TextFormatter, *TextFormatter:
+		logger.format = Text
+	case JSONFormatter, *JSONFormatter:
+		logger.format = JSON
+	case LogfmtFormatter, *LogfmtFormatter:
+		logger.format = Logfmt
+	default:
+		logger.formatter = config.Formatter
+	}
+
+	logger.sampler = newSampler(config.Sampling)
+	logger.dedup = newDeduper(config.Dedup)
+	logger.stacktracer = newStacktracer(config.Stacktrace)
+	logger.encrypter = enc
+	for name, level := range config.NamedLevels {
+		logger.SetNamedLevel(name, level)
+	}
+
+	for _, r := range config.Routes {
+		logger.routes = append(logger.routes, route{levels: levelSet(r.Levels), sink: r.Sink})
+	}
+
+	if config.RateLimit > 0 {
+		logger.limiter = newRateLimiter(config.RateLimit, config.RateBurst, config.RateLimitBy)
+	}
+
+	if logger.rotateEvery != RotateNone {
+		// An existing file belongs to the period it was last written in
+		periodTime := time.Now()
+		if info.Size() > 0 {
+			periodTime = info.ModTime()
+		}
+		logger.startPeriod(periodTime)
+	}
+
+	if config.AdaptiveShedding {
+		logger.shedder = newLoadShedder(config.ShedHighWatermark, config.ShedLowWatermark)
+	}
+
+	if config.RingBufferSize > 0 {
+		logger.ring = newRingBuffer(config.RingBufferSize)
 	}
 
-	defaultLogger = logger
 	logger.wg.Add(1)
 	go logger.processLogs()
 
+	if logger.ring != nil && config.DumpOnSignal {
+		logger.watchDumpSignal()
+	}
+
+	if logger.maxAge > 0 || logger.maxBackups > 0 || logger.maxTotalSize > 0 {
+		logger.cleanupReq = make(chan struct{}, 1)
+		logger.watchRetention()
+	}
+
+	if config.Upload != nil && config.Upload.Uploader != nil {
+		upload := *config.Upload
+		if upload.Retries == 0 {
+			upload.Retries = defaultUploadRetries
+		} else if upload.Retries < 0 {
+			upload.Retries = 0
+		}
+		if upload.Timeout <= 0 {
+			upload.Timeout = defaultUploadTimeout
+		}
+		logger.upload = &upload
+		logger.uploadCh = make(chan string, uploadQueueSize)
+		logger.watchUploads()
+	}
+
+	if !config.StdoutOnly && (config.ReopenOnSignal || config.ReopenCheck > 0) {
+		logger.watchReopen(config.ReopenCheck, config.ReopenOnSignal)
+	}
+
+	if !config.Sync && config.OverflowPolicy != OverflowBlock && config.DropReport >= 0 {
+		if config.DropReport == 0 {
+			config.DropReport = defaultDropReport
+		}
+		logger.watchDrops(config.DropReport)
+	}
+
+	if logger.limiter != nil && config.RateLimitReport >= 0 {
+		if config.RateLimitReport == 0 {
+			config.RateLimitReport = defaultRateLimitReport
+		}
+		logger.watchRateLimit(config.RateLimitReport)
+	}
+
+	return logger, nil
+}
+
+// Initialize creates the default logger used by the package-level functions
+func Initialize(config Config) error {
+	logger, err := New(config)
+	if err != nil {
+		return err
+	}
+	defaultLogger = logger
 	return nil
 }
 
@@ -181,138 +731,504 @@ func Initialize(config Config) error {
 func (l *Logger) processLogs() {
 	defer l.wg.Done()
 
-	batch := make([]*logEntry, 0, 50000)
-	ticker := time.NewTicker(time.Millisecond)
-	defer ticker.Stop()
+	batch := make([]*logEntry, 0, l.batchSize)
+
+	// The timer runs only while a batch is waiting, so an idle logger never wakes up
+	timer := time.NewTimer(l.flushDelay)
+	stopTimer(timer)
+	pending := false
+
+	// With deduplication, a second timer ends a run of repeats nothing else interrupts
+	var dedupTimer *time.Timer
+	var dedupC <-chan time.Time
+	armDedup := func(due int64) {
+		if due == 0 || dedupC != nil {
+			return
+		}
+		dedupTimer = time.NewTimer(time.Duration(due - time.Now().UnixNano()))
+		dedupC = dedupTimer.C
+	}
+
+	// With OverflowSpill, a signal to write the entries in the spill file
+	var spillC <-chan struct{}
+	if l.spill != nil {
+		spillC = l.spill.ready
+	}
+
+	write := func() {
+		if pending {
+			stopTimer(timer)
+			pending = false
+		}
+		if len(batch) > 0 {
+			l.commit(batch)
+			batch = batch[:0]
+			if l.dedup != nil {
+				armDedup(l.dedupDue())
+			}
+		}
+		if len(l.logChan) == 0 {
+			// Nothing else is coming right now, so buffered lines go out
+			l.flushWriteBuffer()
+		}
+	}
 
 	for {
 		select {
 		case entry := <-l.logChan:
 			batch = append(batch, entry)
 
-			if len(batch) >= 50000 {
-				l.writeBatch(batch)
-				for _, e := range batch {
-					e.msg = e.msg[:0]
-					entryPool.Put(e)
-				}
-				batch = batch[:0]
+			if len(batch) >= l.batchSize {
+				write()
+			} else if !pending {
+				timer.Reset(l.flushDelay)
+				pending = true
 			}
 
-		case <-ticker.C:
-			if len(batch) > 0 {
-				l.writeBatch(batch)
-				for _, e := range batch {
-					e.msg = e.msg[:0]
-					entryPool.Put(e)
-				}
-				batch = batch[:0]
+		case <-timer.C:
+			pending = false
+			write()
+
+		case <-dedupC:
+			dedupC = nil
+			armDedup(l.flushDedup(false))
+
+		case <-spillC:
+			// Spilled entries are newer than everything in the buffer
+			for n := len(l.logChan); n > 0; n-- {
+				batch = append(batch, <-l.logChan)
 			}
+			write()
+			l.drainSpill(false)
+			l.flushWriteBuffer()
+
+		case ack := <-l.flushReq:
+			// Write everything queued so far, then acknowledge
+			for n := len(l.logChan); n > 0; n-- {
+				batch = append(batch, <-l.logChan)
+			}
+			write()
+			if l.spill != nil {
+				l.drainSpill(true)
+			}
+			if l.dedup != nil {
+				l.flushDedup(true)
+			}
+			l.flushWriteBuffer()
+			close(ack)
+
+		case fn := <-l.reloadReq:
+			// Entries collected so far go out under the old settings
+			write()
+			l.batchMu.Lock()
+			fn()
+			l.batchMu.Unlock()
 
 		case <-l.done:
 			close(l.logChan)
 			for entry := range l.logChan {
 				batch = append(batch, entry)
-				if len(batch) >= 50000 {
-					l.writeBatch(batch)
-					for _, e := range batch {
-						e.msg = e.msg[:0]
-						entryPool.Put(e)
+				if len(batch) >= l.batchSize {
+					if l.aborted() {
+						l.abandon(batch)
+						return
 					}
-					batch = batch[:0]
+					write()
 				}
 			}
-			if len(batch) > 0 {
-				l.writeBatch(batch)
-				for _, e := range batch {
-					e.msg = e.msg[:0]
-					entryPool.Put(e)
+			if !l.aborted() {
+				write()
+				if l.spill != nil {
+					l.drainSpill(true)
+				}
+				if l.dedup != nil {
+					l.flushDedup(true)
 				}
+			} else {
+				l.abandon(batch)
+			}
+			if dedupC != nil {
+				dedupTimer.Stop()
 			}
 			return
 		}
 	}
 }
 
+// commit writes a batch of accepted entries, collapsing repeats when
+// deduplication is on, and returns the entries to the pool
+func (l *Logger) commit(batch []*logEntry) {
+	l.batchMu.Lock()
+	defer l.batchMu.Unlock()
+
+	out := batch
+	if l.dedup != nil {
+		var removed int
+		out, removed = l.dedup.filter(batch)
+		l.stats.deduplicated.Add(uint64(removed))
+	}
+	l.writeBatch(out)
+	l.stats.written.Add(uint64(len(batch)))
+	releaseBatch(out)
+}
+
+// stopTimer stops t and discards a tick it may already have sent
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
+
+// aborted reports whether a shutdown deadline has passed
+func (l *Logger) aborted() bool {
+	select {
+	case <-l.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush blocks until every entry queued before the call has been written
+func (l *Logger) flush() {
+	ack := make(chan struct{})
+	select {
+	case l.flushReq <- ack:
+		<-ack
+	case <-l.done:
+	}
+}
+
+// Flush blocks until every entry logged before the call has been written and
+// the log file has been synced to disk. Use it before checkpoints or handing
+// control to code that may not return. After Close it does nothing.
+func (l *Logger) Flush() error {
+	l.flush()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Close syncs the file itself before releasing it
+	if l.closed.Load() || l.console {
+		return nil
+	}
+
+	return l.syncLocked()
+}
+
+// Snapshot writes a consistent point-in-time copy of the current log file to destPath.
+// Pending entries are flushed first and the copy is taken while writes are paused,
+// so the snapshot never ends with a partial line.
+func (l *Logger) Snapshot(destPath string) error {
+	if l.console {
+		return fmt.Errorf("snapshot requires a log file")
+	}
+	l.flush()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushLocked()
+
+	src, err := os.Open(l.logPath)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer src.Close()
+
+	// Copy into a temporary file first so destPath is replaced atomically
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), filepath.Base(destPath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %v", err)
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to copy log file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close snapshot file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to move snapshot into place: %v", err)
+	}
+	return nil
+}
+
+// releaseBatch returns processed entries to the pool
+func releaseBatch(batch []*logEntry) {
+	for _, e := range batch {
+		releaseEntry(e)
+	}
+}
+
+// releaseEntry resets an entry and returns it to the pool
+func releaseEntry(e *logEntry) {
+	settle(e, nil)
+	if cap(e.msg) > maxPooledMessage {
+		return
+	}
+	e.msg = e.msg[:0]
+	e.fields = nil
+	e.ctx = nil
+	entryPool.Put(e)
+}
+
 // writeBatch writes a batch of log entries to the file
 func (l *Logger) writeBatch(entries []*logEntry) {
 	if len(entries) == 0 {
 		return
 	}
 
-	buf := bytes.NewBuffer(make([]byte, 0, 64*1024)) // 64KB buffer
+	// The buffer is reused across batches; one grown by a burst is let go
+	buf := l.batchBuf
+	if buf == nil || buf.Cap() > maxBatchBuffer {
+		buf = bytes.NewBuffer(make([]byte, 0, 64*1024)) // 64KB buffer
+		l.batchBuf = buf
+	}
 	defer buf.Reset()
 
-	pwd, _ := os.Getwd()
+	// Line end offsets, needed only to split the batch by level
+	var ends []int
+	split := l.fileLevels != nil || len(l.routes) > 0 || l.console
+	if split {
+		ends = l.batchEnds[:0]
+		defer func() { l.batchEnds = ends[:0] }()
+	}
 
-	for _, entry := range entries {
-		// Get relative path for better IDE integration
-		relPath := entry.file
-		if abs, err := filepath.Abs(entry.file); err == nil {
-			if rel, err := filepath.Rel(pwd, abs); err == nil {
-				relPath = rel
+	if l.workers > 1 && !l.tee {
+		ends = l.renderParallel(buf, entries, ends, split)
+	} else {
+		ends = l.renderEntries(buf, entries, ends, split)
+	}
+
+	fileOut := buf.Bytes()
+	if l.fileLevels != nil {
+		fileOut = selectLines(fileOut, ends, entries, l.fileLevels)
+	}
+
+	l.mu.Lock()
+	l.writeErr = nil
+	if l.console {
+		start := time.Now()
+		l.writeConsole(buf.Bytes(), ends, entries)
+		l.stats.recordWrite(time.Since(start))
+	} else {
+		l.rotateIfDue(entries[0].timestamp)
+		if len(fileOut) > 0 {
+			start := time.Now()
+			l.writeLocked(fileOut)
+			if l.writeCap > 0 && hasStrict(entries) {
+				// Strict callers learn whether the write itself succeeded
+				l.flushLocked()
 			}
+			l.stats.recordWrite(time.Since(start))
+			l.syncAfterWrite(entries)
 		}
+	}
+	if l.writeErr != nil {
+		for _, entry := range entries {
+			settle(entry, &OpError{Op: OpWrite, Err: l.writeErr})
+		}
+	}
+	l.mu.Unlock()
 
-		timeStr := time.Unix(0, entry.timestamp).Format("2006/01/02 15:04:05")
+	if len(l.sinks) > 0 {
+		l.writeSinks(buf.Bytes(), entries)
+	}
+	if len(l.routes) > 0 {
+		l.writeRoutes(buf.Bytes(), ends, entries)
+	}
+	l.publish(entries)
+}
 
-		// Development mode: print to console with colors
-		if l.isDev {
-			fmt.Printf("%s [%s%s%s] [%s:%d] %s\n",
-				timeStr,
-				levelColors[entry.level],
-				levelNames[entry.level],
-				colorReset,
-				relPath, entry.line,
-				entry.msg)
+// renderEntries renders entries into buf, printing them to the console as
+// configured, and appends each line's end offset to ends when split is set
+func (l *Logger) renderEntries(buf *bytes.Buffer, entries []*logEntry, ends []int, split bool) []int {
+	for _, entry := range entries {
+		caller := l.formatCaller(entry)
+
+		// Development mode: print to console with colors. In console mode
+		// this is the output itself, split between stdout and stderr below.
+		showConsole := l.tee && (l.console || l.consoleLevels == nil || l.consoleLevels[entry.level])
+		if showConsole && l.pretty {
+			if l.console {
+				l.appendPretty(buf, entry, caller)
+			} else {
+				var line bytes.Buffer
+				l.appendPretty(&line, entry, caller)
+				l.consoleOut.Write(line.Bytes())
+			}
+		} else if showConsole {
+			var timeBuf, fieldBuf, blockBuf bytes.Buffer
+			l.appendTime(&timeBuf, entry.timestamp, defaultTextTimeFormat)
+			appendFields(&fieldBuf, l.serviceFields)
+			appendFields(&fieldBuf, entry.fields)
+			appendBlocks(&blockBuf, entry.fields)
+			color, reset := l.theme.color(entry.level), colorReset
+			if !l.color || color == "" {
+				color, reset = "", ""
+			}
+			out := l.consoleOut
+			if l.console {
+				out = buf
+			}
+			segment := ""
+			if cs := callerSegment(caller, l.callerFunction(entry)); cs != "" {
+				segment = " [" + cs + "]"
+			}
+			fmt.Fprintf(out, "%s [%s%s%s]%s %s%s\n%s",
+				timeBuf.Bytes(),
+				color,
+				LevelName(entry.level),
+				reset,
+				segment,
+				entry.msg, fieldBuf.Bytes(), blockBuf.Bytes())
 		}
 
 		// Always write to file with IDE-friendly path
-		fmt.Fprintf(buf, "%s [%s] [%s:%d] %s\n",
-			timeStr,
-			levelNames[entry.level],
-			relPath, entry.line,
-			entry.msg)
+		if !l.tee || !l.console {
+			l.appendEntry(buf, entry, caller)
+		}
+		if split {
+			ends = append(ends, buf.Len())
+		}
 	}
+	return ends
+}
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// appendEntry renders a log entry as a single line in the configured format
+func (l *Logger) appendEntry(buf *bytes.Buffer, entry *logEntry, caller string) {
+	if l.formatter != nil {
+		l.appendFormatted(buf, entry, caller)
+		return
+	}
+	switch l.format {
+	case JSON:
+		l.appendJSONEntry(buf, entry, caller)
+		return
+	case Logfmt:
+		l.appendLogfmtEntry(buf, entry, caller)
+		return
+	}
 
-	// Write to file
-	n, err := l.file.Write(buf.Bytes())
-	if err != nil {
-		if l.isDev {
-			fmt.Printf("Error writing to log file: %v\n", err)
+	l.appendTime(buf, entry.timestamp, defaultTextTimeFormat)
+	buf.WriteString(" [")
+	buf.WriteString(LevelName(entry.level))
+	buf.WriteByte(']')
+	if cs := callerSegment(caller, l.callerFunction(entry)); cs != "" {
+		buf.WriteString(" [")
+		buf.WriteString(cs)
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(' ')
+	buf.Write(entry.msg)
+	appendFields(buf, l.serviceFields)
+	appendFields(buf, entry.fields)
+	buf.WriteByte('\n')
+	appendBlocks(buf, entry.fields)
+}
+
+// writeLocked writes rendered lines to the file, or collects them under
+// WriteBufferSize. The caller must hold l.mu.
+func (l *Logger) writeLocked(p []byte) {
+	if l.console {
+		l.writeStream(os.Stdout, p)
+		return
+	}
+	if l.writeCap > 0 {
+		l.writeBuf = append(l.writeBuf, p...)
+		if len(l.writeBuf) >= l.writeCap || l.currSize+int64(len(l.writeBuf)) >= l.maxSize {
+			l.flushLocked()
 		}
 		return
 	}
+	l.writeFile(p)
+}
 
-	l.currSize += int64(n)
+// writeFile writes to the file and rotates when it grows too large. The
+// caller must hold l.mu.
+func (l *Logger) writeFile(p []byte) {
+	if !l.appendFile(p) {
+		return
+	}
 	if l.currSize >= l.maxSize {
-		if err := l.rotate(); err != nil && l.isDev {
-			fmt.Printf("Error rotating log file: %v\n", err)
+		if err := l.rotate(); err != nil {
+			l.reportError(OpRotate, err, "Error rotating log file: %v", err)
 		}
 	}
 }
 
+// appendFile writes to the file, or to the fallback sinks when that fails,
+// and reports whether the file took the write. The caller must hold l.mu.
+func (l *Logger) appendFile(p []byte) bool {
+	out := p
+	if l.encrypter != nil {
+		out = l.encrypter.seal(p)
+	}
+	l.reserveLocked(int64(len(out)))
+	n, err := l.file.Write(out)
+	if err != nil {
+		// Fallback sinks get plaintext, as other sinks do. A sealed record
+		// cut short cannot be decrypted, so all of it goes to the fallback.
+		if l.encrypter == nil {
+			p = p[n:]
+		}
+		l.writeFallback(p, err)
+		return false
+	}
+
+	// The primary file is healthy again
+	if l.stats.fallbackLevel.Load() != 0 {
+		l.stats.fallbackLevel.Store(0)
+	}
+
+	l.currSize += int64(n)
+	l.unsynced += int64(n)
+	l.stats.bytesWritten.Add(uint64(n))
+	return true
+}
+
+// internalError reports one of the logger's own operational errors
+func (l *Logger) internalError(format string, args ...interface{}) {
+	l.errMu.Lock()
+	defer l.errMu.Unlock()
+	fmt.Fprintf(l.errWriter, format+"\n", args...)
+}
+
 // rotate moves the current log file to the archive directory with a number
 func (l *Logger) rotate() error {
-	if err := l.file.Close(); err != nil {
-		return fmt.Errorf("failed to close current log file: %v", err)
-	}
+	// A flush that rotated on its own would leave this rotation an empty file
+	l.drainLocked()
 
-	// Get next archive number
-	nextNum, err := l.getNextArchiveNumber()
+	// Claim the archive slot before touching the current file
+	archivePath, err := l.claimArchivePath()
 	if err != nil {
-		return fmt.Errorf("failed to get next archive number: %v", err)
+		return err
 	}
 
-	// Create archive path
-	archiveDir := filepath.Join(filepath.Dir(l.logPath), "archive")
-	archivePath := filepath.Join(archiveDir, fmt.Sprintf("%d.log", nextNum))
+	// Under a sync policy, what reached the old file must be durable too
+	if l.syncPolicy != SyncNever {
+		if err := l.syncLocked(); err != nil {
+			l.reportError(OpWrite, err, "Error syncing log file: %v", err)
+		}
+	}
+	if err := l.trimLocked(); err != nil {
+		l.reportError(OpRotate, err, "Error trimming log file: %v", err)
+	}
+
+	if err := l.file.Close(); err != nil {
+		os.Remove(archivePath)
+		return fmt.Errorf("failed to close current log file: %v", err)
+	}
 
-	// Move current log to archive
+	// Move current log to archive, replacing the claimed placeholder
 	if err := os.Rename(l.logPath, archivePath); err != nil {
+		os.Remove(archivePath)
 		return fmt.Errorf("failed to move log to archive: %v", err)
 	}
 
@@ -324,13 +1240,55 @@ func (l *Logger) rotate() error {
 
 	l.file = file
 	l.currSize = 0
+	l.unsynced = 0
+	l.stats.rotations.Add(1)
+
+	if l.compress {
+		l.compressArchive(archivePath)
+	} else {
+		l.queueUpload(archivePath)
+	}
+	l.requestCleanup()
 	return nil
 }
 
-// getNextArchiveNumber gets the next available archive number
+// maxArchiveClaimAttempts bounds the retries when archive numbers collide
+const maxArchiveClaimAttempts = 100
+
+// claimArchivePath reserves the next archive number by creating its file exclusively.
+// Processes sharing the log directory can race on the number; O_EXCL guarantees only
+// one of them wins each number and the others move on to the next one.
+func (l *Logger) claimArchivePath() (string, error) {
+	if l.naming == ArchiveLumberjack {
+		return l.claimLumberjackPath()
+	}
+	if l.rotateEvery != RotateNone {
+		return l.claimDatedArchivePath()
+	}
+
+	nextNum, err := l.getNextArchiveNumber()
+	if err != nil {
+		return "", fmt.Errorf("failed to get next archive number: %v", err)
+	}
+
+	dir, base, ext := l.splitLogPath()
+	for i := 0; i < maxArchiveClaimAttempts; i++ {
+		archivePath := filepath.Join(dir, "archive", fmt.Sprintf("%s-%d%s", base, nextNum+i, ext))
+		claimed, err := claimArchiveFile(archivePath)
+		if err != nil {
+			return "", err
+		}
+		if claimed {
+			return archivePath, nil
+		}
+	}
+	return "", fmt.Errorf("failed to claim archive number after %d attempts", maxArchiveClaimAttempts)
+}
+
+// getNextArchiveNumber gets the next available archive number of the log file
 func (l *Logger) getNextArchiveNumber() (int, error) {
-	archiveDir := filepath.Join(filepath.Dir(l.logPath), "archive")
-	files, err := os.ReadDir(archiveDir)
+	dir, base, ext := l.splitLogPath()
+	files, err := os.ReadDir(filepath.Join(dir, "archive"))
 	if err != nil {
 		return 1, err
 	}
@@ -340,80 +1298,343 @@ func (l *Logger) getNextArchiveNumber() (int, error) {
 		if file.IsDir() {
 			continue
 		}
-		name := file.Name()
-		if num, err := strconv.Atoi(strings.TrimSuffix(name, ".log")); err == nil {
-			if num > maxNum {
-				maxNum = num
-			}
+		if num, ok := archiveNumber(file.Name(), base, ext); ok && num > maxNum {
+			maxNum = num
 		}
 	}
 	return maxNum + 1, nil
 }
 
 // log logs a message at the specified level
-func (l *Logger) log(level int, format string, args ...interface{}) {
-	if level < l.level {
+func (l *Logger) log(level int, flags entryFlags, format string, args ...interface{}) {
+	if l == nil || (levelBelow(level, l.minLevel()) && l.ring == nil) {
+		return
+	}
+
+	// Sample on the format string before paying for formatting
+	if l.sampled(level, flags, format) {
 		return
 	}
 
 	// Get caller info
-	_, file, line, _ := runtime.Caller(2)
+	pc, file, line := l.caller(2)
 
-	// Get message buffer from pool
-	msgBuf := bytes.NewBuffer(make([]byte, 0, 1024)) // 1KB for messages
+	// Get message buffer from pool; the entry keeps its own copy
+	msgBuf := msgPool.Get().(*bytes.Buffer)
+	defer releaseMsg(msgBuf)
+	msgBuf.WriteString(l.prefix)
 	fmt.Fprintf(msgBuf, format, args...)
+	msg := msgBuf.Bytes()
+	fields := l.withStack(level, resolveLazy(l.fields), file, line)
+	if l.redacting() {
+		msg = l.redactMessage(msg)
+		fields = l.redactFields(fields)
+	}
+
+	if l.oversized(msg) {
+		l.emitOversized(level, flags, msg, fields, pc, file, line)
+		return
+	}
+	l.emit(level, flags, msg, fields, pc, file, line)
+}
+
+// logFields logs a message with structured fields at the specified level
+func (l *Logger) logFields(level int, flags entryFlags, msg string, fields []Field) {
+	if l == nil || (levelBelow(level, l.minLevel()) && l.ring == nil) {
+		return
+	}
+
+	// Get caller info
+	pc, file, line := l.caller(2)
+
+	l.dispatch(level, flags, msg, fields, pc, file, line)
+}
+
+// dispatch applies bound fields, redaction, the ring buffer and validation to
+// a structured entry whose caller is already known, then enqueues it
+func (l *Logger) dispatch(level int, flags entryFlags, msg string, fields []Field, pc uintptr, file string, line int) {
+	if l.sampled(level, flags, msg) {
+		return
+	}
+
+	// Bound fields come first, followed by the fields of this call
+	if len(l.fields) > 0 {
+		fields = append(append(make([]Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+	}
+	fields = resolveLazy(fields)
+	fields = l.withStack(level, fields, file, line)
+
+	msgBuf := msgPool.Get().(*bytes.Buffer)
+	defer releaseMsg(msgBuf)
+	msgBuf.WriteString(l.prefix)
+	msgBuf.WriteString(msg)
+	msgBytes := msgBuf.Bytes()
+	if l.redacting() {
+		msgBytes = l.redactMessage(msgBytes)
+		fields = l.redactFields(fields)
+	}
+
+	if l.validateFields && l.isDev {
+		l.checkFields(fields, file, line)
+	}
+
+	if l.oversized(msgBytes) {
+		l.emitOversized(level, flags, msgBytes, fields, pc, file, line)
+		return
+	}
+	l.emit(level, flags, msgBytes, fields, pc, file, line)
+}
+
+// emit records an entry in the ring buffer and enqueues it when its level is
+// enabled
+func (l *Logger) emit(level int, flags entryFlags, msg []byte, fields []Field, pc uintptr, file string, line int) {
+	// The ring buffer sees every level, even those filtered from the file
+	if l.ring != nil {
+		l.ring.add(level, msg, fields, pc, file, line, l.entryTime())
+		if levelBelow(level, l.minLevel()) {
+			return
+		}
+	}
+
+	l.enqueue(level, flags, msg, fields, pc, file, line)
+}
+
+// entryTime returns the timestamp of an entry logged now through l
+func (l *Logger) entryTime() int64 {
+	if !l.at.IsZero() {
+		return l.at.UnixNano()
+	}
+	return time.Now().UnixNano()
+}
+
+// enqueue hands a log entry to the writer goroutine
+func (l *Logger) enqueue(level int, flags entryFlags, msg []byte, fields []Field, pc uintptr, file string, line int) {
+	if flags&flagPriority == 0 {
+		if l.limiter != nil && !l.limiter.allow(level, file, line) {
+			l.stats.rateLimited.Add(1)
+			return
+		}
+		if l.shedder != nil && l.shedder.shouldDrop(len(l.logChan), cap(l.logChan)) {
+			l.stats.shed.Add(1)
+			return
+		}
+	}
 
 	// Get entry from pool
 	entry := entryPool.Get().(*logEntry)
 	entry.level = level
-	entry.msg = append(entry.msg[:0], msgBuf.Bytes()...)
+	entry.flags = flags
+	entry.msg = append(entry.msg[:0], msg...)
+	entry.fields = fields
+	entry.pc = pc
 	entry.file = file
 	entry.line = line
-	entry.timestamp = time.Now().UnixNano()
+	entry.timestamp = l.entryTime()
+	entry.ctx = l.ctx
+	if l.strict != nil {
+		entry.done = l.strict.done
+		l.strict.pending = true
+	}
 
-	// Non-blocking send
-	select {
-	case l.logChan <- entry:
-	default:
+	if !l.runHooks(entry) {
+		settle(entry, ErrDropEntry)
+		releaseEntry(entry)
+		return
+	}
+
+	l.closeMu.RLock()
+	if l.closed.Load() {
+		// Logger has been shut down, nothing will write this entry
+		l.closeMu.RUnlock()
+		settle(entry, ErrClosed)
+		releaseEntry(entry)
+		return
+	}
+	// Shutdown waits for the send instead of closeMu being held across it,
+	// so a full buffer never stalls Close or the callers checking closed
+	l.sending.Add(1)
+	l.closeMu.RUnlock()
+
+	if l.syncWrite {
+		l.stats.countLevel(level)
+		l.writeSync(entry)
+	} else if flags&flagPriority != 0 || l.overflow == OverflowBlock {
+		// Priority entries wait for buffer space instead of being dropped
+		select {
+		case l.logChan <- entry:
+			l.stats.countLevel(level)
+		case <-l.abort:
+			settle(entry, ErrClosed)
+			releaseEntry(entry)
+		}
+	} else if l.trySend(entry) {
+		l.stats.countLevel(level)
+	} else {
+		l.stats.countDrop(level)
 		if l.isDev {
-			fmt.Printf("WARNING: Log buffer full, dropping message\n")
+			l.internalError("WARNING: Log buffer full, dropping message")
 		}
-		entry.msg = entry.msg[:0]
-		entryPool.Put(entry)
+		l.notifyError(OpDrop, errBufferFull)
+		releaseEntry(entry)
 	}
+	l.sending.Done()
+
+	switch {
+	case level == FATAL && flags&flagNoExit == 0:
+		l.Close()
+		l.exitFunc(1)
+	case level == FATAL, level == PANIC:
+		// Make sure the entry is on disk before the caller unwinds
+		l.Flush()
+	}
+}
+
+// writeSync writes an entry on the calling goroutine in Sync mode. Writes
+// are serialized with each other and with the writer goroutine, so error
+// hooks must not log to the same logger.
+func (l *Logger) writeSync(entry *logEntry) {
+	batch := [1]*logEntry{entry}
+	l.commit(batch[:])
+}
+
+// Trace logs a trace message
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.log(TRACE, 0, format, args...)
+}
+
+// Debug logs a debug message
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.log(DEBUG, 0, format, args...)
+}
+
+// Info logs an info message
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.log(INFO, 0, format, args...)
+}
+
+// Warn logs a warning message
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.log(WARN, 0, format, args...)
+}
+
+// Error logs an error message
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.log(ERROR, 0, format, args...)
+}
+
+// ErrorWithStack logs an error message with stack trace
+func (l *Logger) ErrorWithStack(msg string, err error) {
+	stackBuf := make([]byte, 4096)
+	n := runtime.Stack(stackBuf, false)
+	l.log(ERROR, 0, "%s: %v\nStack Trace:\n%s", msg, err, stackBuf[:n])
+}
+
+// MustDebug logs a debug message that bypasses sampling, rate limiting, dedup and load shedding
+func (l *Logger) MustDebug(format string, args ...interface{}) {
+	l.log(DEBUG, flagPriority, format, args...)
+}
+
+// MustInfo logs an info message that bypasses sampling, rate limiting, dedup and load shedding
+func (l *Logger) MustInfo(format string, args ...interface{}) {
+	l.log(INFO, flagPriority, format, args...)
+}
+
+// MustWarn logs a warning message that bypasses sampling, rate limiting, dedup and load shedding
+func (l *Logger) MustWarn(format string, args ...interface{}) {
+	l.log(WARN, flagPriority, format, args...)
+}
+
+// MustError logs an error message that bypasses sampling, rate limiting, dedup and load shedding
+func (l *Logger) MustError(format string, args ...interface{}) {
+	l.log(ERROR, flagPriority, format, args...)
+}
 
-	if level == FATAL {
-		close(l.done)
-		l.wg.Wait()
-		os.Exit(1)
+// Fatal logs a fatal message, closes the logger and exits the program
+func (l *Logger) Fatal(format string, args ...interface{}) {
+	l.log(FATAL, flagPriority, format, args...)
+}
+
+// FatalNoExit logs a fatal message and waits for it to be written, but leaves
+// closing the logger and exiting to the caller (e.g. a graceful shutdown path)
+func (l *Logger) FatalNoExit(format string, args ...interface{}) {
+	l.log(FATAL, flagPriority|flagNoExit, format, args...)
+}
+
+// Panic logs a panic message, waits for it to be written and then panics with it
+func (l *Logger) Panic(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(PANIC, flagPriority, "%s", msg)
+	panic(msg)
+}
+
+// TraceKV logs a trace message with structured fields
+func (l *Logger) TraceKV(msg string, fields Fields) {
+	l.logFields(TRACE, 0, msg, fields.sorted())
+}
+
+// DebugKV logs a debug message with structured fields
+func (l *Logger) DebugKV(msg string, fields Fields) {
+	l.logFields(DEBUG, 0, msg, fields.sorted())
+}
+
+// InfoKV logs an info message with structured fields
+func (l *Logger) InfoKV(msg string, fields Fields) {
+	l.logFields(INFO, 0, msg, fields.sorted())
+}
+
+// WarnKV logs a warning message with structured fields
+func (l *Logger) WarnKV(msg string, fields Fields) {
+	l.logFields(WARN, 0, msg, fields.sorted())
+}
+
+// ErrorKV logs an error message with structured fields
+func (l *Logger) ErrorKV(msg string, fields Fields) {
+	l.logFields(ERROR, 0, msg, fields.sorted())
+}
+
+// FatalKV logs a fatal message with structured fields and exits the program
+func (l *Logger) FatalKV(msg string, fields Fields) {
+	l.logFields(FATAL, flagPriority, msg, fields.sorted())
+}
+
+// PanicKV logs a panic message with structured fields, waits for it to be written and then panics with it
+func (l *Logger) PanicKV(msg string, fields Fields) {
+	l.logFields(PANIC, flagPriority, msg, fields.sorted())
+	panic(msg)
+}
+
+// Trace logs a trace message
+func Trace(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(TRACE, 0, format, args...)
 	}
 }
 
 // Debug logs a debug message
 func Debug(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.log(DEBUG, format, args...)
+		defaultLogger.log(DEBUG, 0, format, args...)
 	}
 }
 
 // Info logs an info message
 func Info(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.log(INFO, format, args...)
+		defaultLogger.log(INFO, 0, format, args...)
 	}
 }
 
 // Warn logs a warning message
 func Warn(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.log(WARN, format, args...)
+		defaultLogger.log(WARN, 0, format, args...)
 	}
 }
 
 // Error logs an error message
 func Error(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.log(ERROR, format, args...)
+		defaultLogger.log(ERROR, 0, format, args...)
 	}
 }
 
@@ -422,23 +1643,207 @@ func ErrorWithStack(msg string, err error) {
 	if defaultLogger != nil {
 		stackBuf := make([]byte, 4096)
 		n := runtime.Stack(stackBuf, false)
-		defaultLogger.log(ERROR, "%s: %v\nStack Trace:\n%s", msg, err, stackBuf[:n])
+		defaultLogger.log(ERROR, 0, "%s: %v\nStack Trace:\n%s", msg, err, stackBuf[:n])
+	}
+}
+
+// MustDebug logs a debug message that bypasses sampling, rate limiting, dedup and load shedding
+func MustDebug(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(DEBUG, flagPriority, format, args...)
+	}
+}
+
+// MustInfo logs an info message that bypasses sampling, rate limiting, dedup and load shedding
+func MustInfo(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(INFO, flagPriority, format, args...)
+	}
+}
+
+// MustWarn logs a warning message that bypasses sampling, rate limiting, dedup and load shedding
+func MustWarn(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(WARN, flagPriority, format, args...)
+	}
+}
+
+// MustError logs an error message that bypasses sampling, rate limiting, dedup and load shedding
+func MustError(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(ERROR, flagPriority, format, args...)
 	}
 }
 
-// Fatal logs a fatal message and exits the program
+// Fatal logs a fatal message and exits the program. Like the Must variants it is never sampled, rate limited, collapsed or shed.
 func Fatal(format string, args ...interface{}) {
 	if defaultLogger != nil {
-		defaultLogger.log(FATAL, format, args...)
+		defaultLogger.log(FATAL, flagPriority, format, args...)
+	}
+}
+
+// FatalNoExit logs a fatal message and waits for it to be written, without closing the logger or exiting
+func FatalNoExit(format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.log(FATAL, flagPriority|flagNoExit, format, args...)
+	}
+}
+
+// Panic logs a panic message, waits for it to be written and then panics with it
+func Panic(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if defaultLogger != nil {
+		defaultLogger.log(PANIC, flagPriority, "%s", msg)
 	}
+	panic(msg)
+}
+
+// TraceKV logs a trace message with structured fields
+func TraceKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(TRACE, 0, msg, fields.sorted())
+	}
+}
+
+// DebugKV logs a debug message with structured fields
+func DebugKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(DEBUG, 0, msg, fields.sorted())
+	}
+}
+
+// InfoKV logs an info message with structured fields
+func InfoKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(INFO, 0, msg, fields.sorted())
+	}
+}
+
+// WarnKV logs a warning message with structured fields
+func WarnKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(WARN, 0, msg, fields.sorted())
+	}
+}
+
+// ErrorKV logs an error message with structured fields
+func ErrorKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(ERROR, 0, msg, fields.sorted())
+	}
+}
+
+// FatalKV logs a fatal message with structured fields and exits the program
+func FatalKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(FATAL, flagPriority, msg, fields.sorted())
+	}
+}
+
+// PanicKV logs a panic message with structured fields, waits for it to be written and then panics with it
+func PanicKV(msg string, fields Fields) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(PANIC, flagPriority, msg, fields.sorted())
+	}
+	panic(msg)
+}
+
+// Tracem logs a trace message with fields taken from a map, in sorted key order
+func (l *Logger) Tracem(msg string, fields map[string]interface{}) {
+	l.logFields(TRACE, 0, msg, Fields(fields).sorted())
+}
+
+// Debugm logs a debug message with fields taken from a map, in sorted key order
+func (l *Logger) Debugm(msg string, fields map[string]interface{}) {
+	l.logFields(DEBUG, 0, msg, Fields(fields).sorted())
+}
+
+// Infom logs an info message with fields taken from a map, in sorted key order
+func (l *Logger) Infom(msg string, fields map[string]interface{}) {
+	l.logFields(INFO, 0, msg, Fields(fields).sorted())
+}
+
+// Warnm logs a warning message with fields taken from a map, in sorted key order
+func (l *Logger) Warnm(msg string, fields map[string]interface{}) {
+	l.logFields(WARN, 0, msg, Fields(fields).sorted())
+}
+
+// Errorm logs an error message with fields taken from a map, in sorted key order
+func (l *Logger) Errorm(msg string, fields map[string]interface{}) {
+	l.logFields(ERROR, 0, msg, Fields(fields).sorted())
+}
+
+// Tracem logs a trace message with fields taken from a map
+func Tracem(msg string, fields map[string]interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(TRACE, 0, msg, Fields(fields).sorted())
+	}
+}
+
+// Debugm logs a debug message with fields taken from a map
+func Debugm(msg string, fields map[string]interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(DEBUG, 0, msg, Fields(fields).sorted())
+	}
+}
+
+// Infom logs an info message with fields taken from a map
+func Infom(msg string, fields map[string]interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(INFO, 0, msg, Fields(fields).sorted())
+	}
+}
+
+// Warnm logs a warning message with fields taken from a map
+func Warnm(msg string, fields map[string]interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(WARN, 0, msg, Fields(fields).sorted())
+	}
+}
+
+// Errorm logs an error message with fields taken from a map
+func Errorm(msg string, fields map[string]interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logFields(ERROR, 0, msg, Fields(fields).sorted())
+	}
+}
+
+// Flush writes every queued entry of the default logger and syncs its file
+func Flush() error {
+	if defaultLogger != nil {
+		return defaultLogger.Flush()
+	}
+	return nil
+}
+
+// Snapshot writes a consistent copy of the current log file to destPath
+func Snapshot(destPath string) error {
+	if defaultLogger != nil {
+		return defaultLogger.Snapshot(destPath)
+	}
+	return nil
 }
 
 // Close closes the logger
 func Close() error {
 	if defaultLogger != nil {
-		close(defaultLogger.done)
-		defaultLogger.wg.Wait()
-		return defaultLogger.file.Close()
+		return defaultLogger.Close()
+	}
+	return nil
+}
+
+// CloseWithTimeout closes the logger, giving up on unwritten entries after d
+func CloseWithTimeout(d time.Duration) error {
+	if defaultLogger != nil {
+		return defaultLogger.CloseWithTimeout(d)
+	}
+	return nil
+}
+
+// Shutdown closes the logger, giving up on unwritten entries when ctx is done
+func Shutdown(ctx context.Context) error {
+	if defaultLogger != nil {
+		return defaultLogger.Shutdown(ctx)
 	}
 	return nil
 }
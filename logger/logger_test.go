@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestLogger creates a logger writing to a file in a temporary directory
+// and closes it when the test ends
+func newTestLogger(t testing.TB, config Config) *Logger {
+	t.Helper()
+	if config.LogPath == "" && !config.StdoutOnly {
+		config.LogPath = filepath.Join(t.TempDir(), "app.log")
+	}
+	l, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// readLines flushes the logger and returns the lines of its log file
+func readLines(t *testing.T, l *Logger) []string {
+	t.Helper()
+	if err := l.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	return fileLines(t, l.logPath)
+}
+
+// fileLines returns the lines of a file
+func fileLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// countLines returns how many lines contain substr
+func countLines(lines []string, substr string) int {
+	n := 0
+	for _, line := range lines {
+		if strings.Contains(line, substr) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+package logtest_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jbarasa/logger/logger"
+	"github.com/jbarasa/logger/logger/logtest"
+)
+
+var initOnce sync.Once
+
+// initDefault sets up a default logger that writes nothing, as a test
+// binary's TestMain would
+func initDefault() {
+	initOnce.Do(func() {
+		err := logger.Initialize(logger.Config{StdoutOnly: true, FileLevels: []int{}, Sync: true})
+		if err != nil {
+			panic(err)
+		}
+	})
+}
+
+// An ERROR nobody expected fails the test. fakeT stands in for the
+// *testing.T of a real test.
+func ExampleFailOnError() {
+	initDefault()
+	t := &fakeT{}
+	logtest.FailOnError(t)
+
+	logger.Error("payment declined: %v", "card expired")
+
+	fmt.Println("failed:", len(t.failures) > 0)
+	for _, f := range t.cleanups {
+		f()
+	}
+	// Output: failed: true
+}
+
+// ExpectError lets the next ERROR pass, for scenarios that log one on purpose
+func ExampleErrorGuard_ExpectError() {
+	initDefault()
+	t := &fakeT{}
+	guard := logtest.FailOnError(t)
+
+	guard.ExpectError()
+	logger.Error("payment declined: %v", "card expired")
+
+	fmt.Println("failed:", len(t.failures) > 0)
+	for _, f := range t.cleanups {
+		f()
+	}
+	// Output: failed: false
+}
@@ -0,0 +1,138 @@
+// Package logtest provides helpers for testing code that logs through
+// github.com/jbarasa/logger/logger.
+//
+// FailOnError turns any unexpected ERROR or FATAL entry logged during a test
+// into a test failure, surfacing latent bugs the test did not assert on:
+//
+//	func TestCheckout(t *testing.T) {
+//	    guard := logtest.FailOnError(t)
+//
+//	    guard.ExpectError() // the next ERROR is part of the scenario
+//	    checkout(invalidCart)
+//	}
+//
+// NewTestLogger returns a logger that records its entries in memory, for
+// asserting what code under test logs without touching the filesystem:
+//
+//	func TestSignup(t *testing.T) {
+//	    log := logtest.NewTestLogger(t)
+//	    NewService(log.Logger).Signup("alice@example.com")
+//	    log.AssertContains("user created")
+//	}
+package logtest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// TB is the subset of testing.TB used to report unexpected errors
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// ErrorGuard watches a logger for ERROR and FATAL entries
+type ErrorGuard struct {
+	mu         sync.Mutex
+	t          TB
+	active     bool
+	expected   int
+	unexpected []string
+}
+
+// errNoDefault explains why FailOnError cannot guard anything
+const errNoDefault = "logtest: FailOnError needs the default logger; call logger.Initialize first, or use Guard with another logger"
+
+// FailOnError guards the default logger for the duration of the test.
+// Each unexpected ERROR or FATAL entry fails t. When t is nil, failures are
+// only recorded and can be inspected with Failed and Unexpected.
+//
+// Without a default logger nothing would be guarded, so FailOnError fails
+// the test at once (and panics when t is nil).
+func FailOnError(t TB) *ErrorGuard {
+	l := logger.Default()
+	if l == nil {
+		if t == nil {
+			panic(errNoDefault)
+		}
+		t.Helper()
+		t.Fatalf("%s", errNoDefault)
+		return &ErrorGuard{t: t}
+	}
+	return Guard(t, l)
+}
+
+// Guard watches l like FailOnError watches the default logger. Its hook is
+// removed once the test is over.
+func Guard(t TB, l *logger.Logger) *ErrorGuard {
+	g := &ErrorGuard{t: t, active: true}
+	remove := l.AddRemovableHook(g.hook)
+	if t != nil {
+		t.Cleanup(func() {
+			g.stop()
+			remove()
+		})
+	}
+	return g
+}
+
+// ExpectError allows one more ERROR or FATAL entry without failing the test
+func (g *ErrorGuard) ExpectError() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.expected++
+}
+
+// Failed reports whether an unexpected ERROR or FATAL entry was logged
+func (g *ErrorGuard) Failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.unexpected) > 0
+}
+
+// Unexpected returns the messages of unexpected ERROR and FATAL entries
+func (g *ErrorGuard) Unexpected() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.unexpected...)
+}
+
+// stop makes the guard ignore all further entries, including one whose
+// hooks started running before the hook was removed
+func (g *ErrorGuard) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active = false
+}
+
+// hook records ERROR and FATAL entries that were not expected
+func (g *ErrorGuard) hook(e *logger.Entry) error {
+	if logger.BaseLevel(e.Level) < logger.ERROR {
+		return nil
+	}
+
+	g.mu.Lock()
+	if !g.active {
+		g.mu.Unlock()
+		return nil
+	}
+	if g.expected > 0 {
+		g.expected--
+		g.mu.Unlock()
+		return nil
+	}
+	msg := fmt.Sprintf("unexpected error logged at %s:%d: %s", e.File, e.Line, e.Message)
+	g.unexpected = append(g.unexpected, msg)
+	g.mu.Unlock()
+
+	if g.t != nil {
+		g.t.Helper()
+		g.t.Errorf("%s", msg)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package logtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jbarasa/logger/logger"
+	"github.com/jbarasa/logger/logger/logtest"
+)
+
+// fakeT records failures instead of failing the calling test
+type fakeT struct {
+	failures []string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+// Tests run before the examples, which initialize the default logger
+func TestFailOnErrorWithoutDefaultLogger(t *testing.T) {
+	if logger.Default() != nil {
+		t.Skip("the default logger is already initialized")
+	}
+
+	ft := &fakeT{}
+	guard := logtest.FailOnError(ft)
+	if len(ft.failures) != 1 {
+		t.Fatalf("got %d failures, want 1: %v", len(ft.failures), ft.failures)
+	}
+	// The guard returned is usable, but watches nothing
+	guard.ExpectError()
+	if guard.Failed() {
+		t.Error("guard without a logger reports a failure")
+	}
+}
+
+func TestGuardReportsUnexpectedErrors(t *testing.T) {
+	tl := logtest.NewTestLogger(t)
+	ft := &fakeT{}
+	guard := logtest.Guard(ft, tl.Logger)
+
+	tl.Warn("low disk space")
+	guard.ExpectError()
+	tl.Error("expected failure")
+	tl.Error("unexpected failure")
+
+	if len(ft.failures) != 1 {
+		t.Fatalf("got %d failures, want 1: %v", len(ft.failures), ft.failures)
+	}
+	if got := guard.Unexpected(); len(got) != 1 {
+		t.Errorf("Unexpected() = %v, want one entry", got)
+	}
+
+	// Once the test is over, the guard ignores further entries
+	for _, f := range ft.cleanups {
+		f()
+	}
+	tl.Error("after the test")
+	if len(ft.failures) != 1 {
+		t.Errorf("entry after cleanup failed the test: %v", ft.failures)
+	}
+}
@@ -0,0 +1,237 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Recorder is a sink that keeps every entry in memory
+type Recorder struct {
+	mu      sync.Mutex
+	entries []logger.Entry
+}
+
+// NewRecorder creates an empty recorder. Add it to Config.Sinks to capture the
+// entries of any logger.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write discards formatted output; entries arrive through WriteEntries
+func (r *Recorder) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteEntries records a batch of entries
+func (r *Recorder) WriteEntries(entries []*logger.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		entry := *e
+		entry.Fields = append([]logger.Field(nil), e.Fields...)
+		r.entries = append(r.entries, entry)
+	}
+	return nil
+}
+
+// Close keeps the recorded entries available
+func (r *Recorder) Close() error {
+	return nil
+}
+
+// Entries returns the recorded entries in the order they were written
+func (r *Recorder) Entries() []logger.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]logger.Entry(nil), r.entries...)
+}
+
+// FilterLevel returns the recorded entries at the given level
+func (r *Recorder) FilterLevel(level int) []logger.Entry {
+	return r.filter(func(e *logger.Entry) bool { return e.Level == level })
+}
+
+// FilterMessage returns the recorded entries whose message contains substr
+func (r *Recorder) FilterMessage(substr string) []logger.Entry {
+	return r.filter(func(e *logger.Entry) bool { return strings.Contains(e.Message, substr) })
+}
+
+// FilterField returns the recorded entries with a field key equal to value
+func (r *Recorder) FilterField(key string, value interface{}) []logger.Entry {
+	return r.filter(func(e *logger.Entry) bool {
+		v, ok := Field(*e, key)
+		return ok && v == value
+	})
+}
+
+// Reset discards the recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// filter returns the recorded entries matching keep
+func (r *Recorder) filter(keep func(*logger.Entry) bool) []logger.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []logger.Entry
+	for i := range r.entries {
+		if keep(&r.entries[i]) {
+			out = append(out, r.entries[i])
+		}
+	}
+	return out
+}
+
+// Field returns the value of the last field of e with the given key
+func Field(e logger.Entry, key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// TestLogger is a logger whose entries are recorded in memory instead of
+// written to a file or the console. Each call returns once its entry is
+// recorded, so tests can inspect entries right after the code under test runs.
+type TestLogger struct {
+	*logger.Logger
+	*Recorder
+
+	t        TB
+	mu       sync.Mutex
+	exitCode int
+	exited   bool
+}
+
+// NewTestLogger creates a logger recording every entry from TRACE up. It is
+// closed when the test ends. When t is nil, the logger must be closed by the
+// caller and failed assertions are only reported by their return value.
+func NewTestLogger(t TB) *TestLogger {
+	return NewTestLoggerConfig(t, logger.Config{Level: logger.TRACE})
+}
+
+// NewTestLoggerConfig is NewTestLogger with the filtering, redaction and other
+// settings of config. Output settings are replaced: nothing is written to a
+// file or the console, entries are written synchronously, and Fatal records
+// the exit code instead of exiting.
+func NewTestLoggerConfig(t TB, config logger.Config) *TestLogger {
+	tl := &TestLogger{Recorder: NewRecorder(), t: t}
+
+	config.LogPath = ""
+	config.StdoutOnly = true
+	config.FileLevels = []int{} // Nothing reaches the console
+	config.IsDev = false
+	config.Sync = true
+	config.DumpOnSignal = false
+	config.Sinks = append(config.Sinks[:len(config.Sinks):len(config.Sinks)], tl.Recorder)
+	config.Routes = nil
+	config.FallbackSinks = nil
+	config.ExitFunc = tl.exit
+
+	l, err := logger.New(config)
+	if err != nil {
+		// Only invalid patterns or keys in config can fail without a file
+		panic("logtest: " + err.Error())
+	}
+	tl.Logger = l
+	if t != nil {
+		t.Cleanup(func() { l.Close() })
+	}
+	return tl
+}
+
+// Close closes the logger; the recorded entries remain available
+func (tl *TestLogger) Close() error {
+	return tl.Logger.Close()
+}
+
+// Exited returns the exit code passed by Fatal, and whether Fatal was called
+func (tl *TestLogger) Exited() (int, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.exitCode, tl.exited
+}
+
+// exit records a Fatal exit
+func (tl *TestLogger) exit(code int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.exitCode, tl.exited = code, true
+}
+
+// AssertContains fails the test unless an entry's message contains substr
+func (tl *TestLogger) AssertContains(substr string) bool {
+	tl.helper()
+	if len(tl.FilterMessage(substr)) > 0 {
+		return true
+	}
+	return tl.fail("no entry contains %q", substr)
+}
+
+// AssertLevelContains fails the test unless an entry at level has a message
+// containing substr
+func (tl *TestLogger) AssertLevelContains(level int, substr string) bool {
+	tl.helper()
+	for _, e := range tl.FilterLevel(level) {
+		if strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return tl.fail("no %s entry contains %q", logger.LevelName(level), substr)
+}
+
+// AssertNotContains fails the test if an entry's message contains substr
+func (tl *TestLogger) AssertNotContains(substr string) bool {
+	tl.helper()
+	if len(tl.FilterMessage(substr)) == 0 {
+		return true
+	}
+	return tl.fail("unexpected entry containing %q", substr)
+}
+
+// AssertCount fails the test unless exactly n entries were recorded at level
+func (tl *TestLogger) AssertCount(level int, n int) bool {
+	tl.helper()
+	if got := len(tl.FilterLevel(level)); got != n {
+		return tl.fail("got %d %s entries, want %d", got, logger.LevelName(level), n)
+	}
+	return true
+}
+
+// helper marks the calling assertion as a test helper
+func (tl *TestLogger) helper() {
+	if tl.t != nil {
+		tl.t.Helper()
+	}
+}
+
+// fail reports a failed assertion with the recorded entries and returns false
+func (tl *TestLogger) fail(format string, args ...interface{}) bool {
+	if tl.t == nil {
+		return false
+	}
+	tl.t.Helper()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(format, args...))
+	b.WriteString("; recorded entries:")
+	entries := tl.Entries()
+	if len(entries) == 0 {
+		b.WriteString(" none")
+	}
+	for _, e := range entries {
+		b.WriteString(fmt.Sprintf("\n\t[%s] %s", logger.LevelName(e.Level), e.Message))
+		for _, f := range e.Fields {
+			b.WriteString(fmt.Sprintf(" %s=%v", f.Key, f.Value))
+		}
+	}
+	tl.t.Errorf("%s", b.String())
+	return false
+}
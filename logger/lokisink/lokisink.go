@@ -0,0 +1,386 @@
+// Package lokisink pushes log entries to Grafana Loki over its HTTP push API,
+// so no promtail sidecar is needed to ship the logger's files.
+//
+//	s, err := lokisink.New(lokisink.Options{
+//	    URL:    "http://loki:3100",
+//	    Labels: map[string]string{"service": "api", "env": "prod"},
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{s}})
+//
+// Every entry is labeled with the static labels plus its level, and its line
+// is rendered as logfmt unless another logger.Formatter is configured.
+// Entries are queued in memory and pushed in batches from a background
+// goroutine; failed pushes are retried with exponential backoff.
+package lokisink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// pushPath is appended to URLs that do not already name the push endpoint
+const pushPath = "/loki/api/v1/push"
+
+// Options configures the Loki sink
+type Options struct {
+	URL         string            // Loki base URL or full push URL (required)
+	TenantID    string            // X-Scope-OrgID for multi-tenant Loki
+	Headers     map[string]string // Extra request headers, e.g. Authorization
+	Labels      map[string]string // Static labels of every stream, e.g. service and env
+	LevelLabel  string            // Label holding the entry level (default: "level"; "-" disables)
+	LabelFields []string          // Entry fields promoted to labels; keep their values low-cardinality
+	Formatter   logger.Formatter  // Line rendering (default: logger.LogfmtFormatter)
+	Gzip        bool              // Compress request bodies
+	Client      *http.Client      // HTTP client (default: 10s timeout)
+
+	BatchSize     int           // Entries per push (default: 1000)
+	FlushInterval time.Duration // Maximum time an entry waits before being pushed (default: 1s)
+	MaxPending    int           // Entries queued in memory; the oldest are dropped beyond this (default: 50000)
+
+	MaxRetries   int           // Retries after a failed push (default: 3)
+	RetryBackoff time.Duration // Delay before the first retry, doubled for each one (default: 500ms)
+
+	OnError func(err error) // Called when entries are dropped (default: print to stderr)
+}
+
+// pending is an entry waiting to be pushed
+type pending struct {
+	stream string            // Canonical form of labels, grouping entries into streams
+	labels map[string]string // Stream labels
+	ts     string            // Unix nanoseconds
+	line   string
+}
+
+// Sink pushes entries to Loki
+type Sink struct {
+	opts    Options
+	url     string
+	mu      sync.Mutex
+	pending []pending
+	kick    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// New validates opts and starts the background pusher
+func New(opts Options) (*Sink, error) {
+	if opts.URL == "" {
+		return nil, errors.New("lokisink: URL is required")
+	}
+	if opts.LevelLabel == "" {
+		opts.LevelLabel = "level"
+	}
+	if opts.Formatter == nil {
+		opts.Formatter = logger.LogfmtFormatter{}
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 50000
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) { fmt.Fprintf(os.Stderr, "lokisink: %v\n", err) }
+	}
+
+	url := strings.TrimRight(opts.URL, "/")
+	if !strings.HasSuffix(url, pushPath) {
+		url += pushPath
+	}
+
+	s := &Sink{
+		opts: opts,
+		url:  url,
+		kick: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// WriteEntries queues a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	items := make([]pending, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, s.pendingEntry(e))
+	}
+	s.queue(items)
+	return nil
+}
+
+// Hook queues a single entry; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.WriteEntries([]*logger.Entry{e})
+}
+
+// Write queues each line of formatted output as an INFO entry, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	var entries []*logger.Entry
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		entries = append(entries, &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)})
+	}
+	s.WriteEntries(entries)
+	return len(p), nil
+}
+
+// Close pushes everything still queued and stops the background pusher
+func (s *Sink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// pendingEntry renders an entry and works out its stream labels
+func (s *Sink) pendingEntry(e *logger.Entry) pending {
+	labels := make(map[string]string, len(s.opts.Labels)+1+len(s.opts.LabelFields))
+	for k, v := range s.opts.Labels {
+		labels[k] = v
+	}
+	if s.opts.LevelLabel != "-" {
+		labels[s.opts.LevelLabel] = strings.ToLower(logger.LevelName(e.Level))
+	}
+	for _, name := range s.opts.LabelFields {
+		for _, f := range e.Fields {
+			if f.Key == name {
+				labels[labelName(name)] = fmt.Sprint(f.Value)
+				break
+			}
+		}
+	}
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return pending{
+		stream: streamKey(labels),
+		labels: labels,
+		ts:     strconv.FormatInt(t.UnixNano(), 10),
+		line:   strings.TrimRight(string(s.opts.Formatter.Format(*e)), "\n"),
+	}
+}
+
+// queue adds entries, dropping the oldest queued ones when the queue is full
+func (s *Sink) queue(items []pending) {
+	s.mu.Lock()
+	s.pending = append(s.pending, items...)
+	dropped := 0
+	if over := len(s.pending) - s.opts.MaxPending; over > 0 {
+		s.pending = s.pending[over:]
+		dropped = over
+	}
+	full := len(s.pending) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	if dropped > 0 {
+		s.opts.OnError(fmt.Errorf("dropped %d entries: queue full", dropped))
+	}
+}
+
+// run pushes batches when one is full or the flush interval passes
+func (s *Sink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.kick:
+			s.pushPending(false)
+		case <-ticker.C:
+			s.pushPending(true)
+		case <-s.done:
+			s.pushPending(true)
+			return
+		}
+	}
+}
+
+// pushPending pushes queued entries in batches. Unless all is set only full
+// batches are pushed.
+func (s *Sink) pushPending(all bool) {
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n == 0 || (!all && n < s.opts.BatchSize) {
+			s.mu.Unlock()
+			return
+		}
+		if n > s.opts.BatchSize {
+			n = s.opts.BatchSize
+		}
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if err := s.push(batch); err != nil {
+			s.opts.OnError(fmt.Errorf("dropped %d entries: %v", len(batch), err))
+		}
+	}
+}
+
+// push sends a batch, retrying with exponential backoff on network errors,
+// 429 and 5xx responses
+func (s *Sink) push(batch []pending) error {
+	body, err := s.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// Shutting down: one immediate last attempt instead of waiting
+			_, err := s.post(body)
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single request and reports whether a failure is worth retrying
+func (s *Sink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if s.opts.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.opts.TenantID)
+	}
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to push logs: %v", err)
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("failed to push logs: %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+// encode builds a push request body, grouping entries into streams in order
+func (s *Sink) encode(batch []pending) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	var streams []*stream
+	index := make(map[string]*stream)
+	for _, p := range batch {
+		st, ok := index[p.stream]
+		if !ok {
+			st = &stream{Stream: p.labels}
+			index[p.stream] = st
+			streams = append(streams, st)
+		}
+		st.Values = append(st.Values, [2]string{p.ts, p.line})
+	}
+
+	raw, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode logs: %v", err)
+	}
+	if !s.opts.Gzip {
+		return raw, nil
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress logs: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress logs: %v", err)
+	}
+	return gz.Bytes(), nil
+}
+
+// streamKey renders labels in a canonical order
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// labelName converts a field key into a valid Loki label name
+func labelName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
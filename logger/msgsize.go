@@ -0,0 +1,79 @@
+package logger
+
+import "unicode/utf8"
+
+// OversizePolicy decides what happens to a message longer than
+// Config.MaxMessageSize. PANIC, FATAL and other priority entries are always
+// truncated, so each stays one entry written before the program stops.
+type OversizePolicy int
+
+// Oversize policies
+const (
+	OversizeTruncate OversizePolicy = iota // Cut the message, end it with "...[truncated]" and add a "truncated" field with the bytes cut (default)
+	OversizeSplit                          // Log the message as several entries with "part" and "parts" fields
+	OversizeDrop                           // Drop the entry
+)
+
+// truncatedMarker ends a truncated message
+const truncatedMarker = "...[truncated]"
+
+// maxPooledMessage is the largest message buffer an entry keeps when it
+// returns to the pool
+const maxPooledMessage = 64 * 1024
+
+// oversized reports whether msg exceeds MaxMessageSize
+func (l *Logger) oversized(msg []byte) bool {
+	return l.maxMessage > 0 && len(msg) > l.maxMessage
+}
+
+// emitOversized logs a message longer than MaxMessageSize by the policy
+func (l *Logger) emitOversized(level int, flags entryFlags, msg []byte, fields []Field, pc uintptr, file string, line int) {
+	l.stats.oversized.Add(1)
+
+	// Appended fields must not write into the caller's backing array
+	fields = fields[:len(fields):len(fields)]
+	priority := flags&flagPriority != 0
+	switch {
+	case l.oversize == OversizeSplit && !priority:
+		parts := splitMessage(msg, l.maxMessage)
+		for i, part := range parts {
+			partFields := append(fields, Field{Key: "part", Value: i + 1}, Field{Key: "parts", Value: len(parts)})
+			l.emit(level, flags, part, partFields, pc, file, line)
+		}
+	case l.oversize == OversizeDrop && !priority:
+		return
+	default:
+		cut := runeCut(msg, l.maxMessage)
+		truncated := make([]byte, 0, cut+len(truncatedMarker))
+		truncated = append(append(truncated, msg[:cut]...), truncatedMarker...)
+		l.emit(level, flags, truncated, append(fields, Bytes("truncated", int64(len(msg)-cut))), pc, file, line)
+	}
+}
+
+// runeCut returns the largest length of at most n bytes that does not end
+// inside a UTF-8 sequence
+func runeCut(msg []byte, n int) int {
+	if n >= len(msg) {
+		return len(msg)
+	}
+	for i := n; i > 0 && i > n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(msg[i]) {
+			return i
+		}
+	}
+	return n
+}
+
+// splitMessage cuts msg into parts of at most n bytes at UTF-8 boundaries
+func splitMessage(msg []byte, n int) [][]byte {
+	parts := make([][]byte, 0, len(msg)/n+1)
+	for len(msg) > 0 {
+		cut := runeCut(msg, n)
+		if cut == 0 {
+			cut = n
+		}
+		parts = append(parts, msg[:cut])
+		msg = msg[cut:]
+	}
+	return parts
+}
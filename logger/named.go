@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+)
+
+// levelUnset marks a named logger that inherits its parent's level
+const levelUnset = math.MinInt64
+
+// namedLevel is the level override of one named logger
+type namedLevel struct {
+	level  atomic.Int64 // levelUnset inherits from parent, then the root logger
+	parent *namedLevel  // Override of the enclosing name ("db" for "db.pool")
+}
+
+// Named returns a child logger for a subsystem whose level can be set on its
+// own with SetNamedLevel or Config.NamedLevels. Entries carry a "logger" field
+// with the name. Naming a named logger nests the names: Named("db").Named("pool")
+// is "db.pool", which inherits the level of "db" until given its own.
+func (l *Logger) Named(name string) *Logger {
+	if l == nil || name == "" {
+		return l
+	}
+	var child *Logger
+	if l.name == "" {
+		child = l.withFields([]Field{{Key: "logger", Value: name}})
+	} else {
+		// Replace the parent's name field rather than adding a second one
+		name = l.name + "." + name
+		child = l.withFields(nil)
+		for i := range child.fields {
+			if child.fields[i].Key == "logger" && child.fields[i].Value == l.name {
+				child.fields[i].Value = name
+			}
+		}
+	}
+	child.name = name
+	child.named = l.namedLevel(name)
+	return child
+}
+
+// Named returns a named child of the default logger
+func Named(name string) *Logger {
+	return defaultLogger.Named(name)
+}
+
+// Name returns the name given with Named, or "" for an unnamed logger
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// SetNamedLevel sets the minimum level of the named logger and the loggers
+// nested under it that have no level of their own
+func (l *Logger) SetNamedLevel(name string, level int) {
+	l.namedLevel(name).level.Store(int64(level))
+}
+
+// SetNamedLevel sets the level of a named logger on the default logger
+func SetNamedLevel(name string, level int) {
+	if defaultLogger != nil {
+		defaultLogger.SetNamedLevel(name, level)
+	}
+}
+
+// SetNamedLevels applies a list of name=level pairs such as "db=debug,http=warn"
+func (l *Logger) SetNamedLevels(spec string) error {
+	levels, err := parseNamedLevels(spec)
+	if err != nil {
+		return err
+	}
+	for name, level := range levels {
+		l.SetNamedLevel(name, level)
+	}
+	return nil
+}
+
+// SetNamedLevels applies name=level pairs on the default logger
+func SetNamedLevels(spec string) error {
+	if defaultLogger != nil {
+		return defaultLogger.SetNamedLevels(spec)
+	}
+	return nil
+}
+
+// namedLevel returns the override for name, registering it and its enclosing
+// names on first use
+func (l *Logger) namedLevel(name string) *namedLevel {
+	l.namedMu.Lock()
+	defer l.namedMu.Unlock()
+
+	if l.namedLevels == nil {
+		l.namedLevels = make(map[string]*namedLevel)
+	}
+	return l.namedLevelLocked(name)
+}
+
+// namedLevelLocked does the work of namedLevel; the caller holds namedMu
+func (l *Logger) namedLevelLocked(name string) *namedLevel {
+	if n, ok := l.namedLevels[name]; ok {
+		return n
+	}
+	n := &namedLevel{}
+	n.level.Store(levelUnset)
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		n.parent = l.namedLevelLocked(name[:i])
+	}
+	l.namedLevels[name] = n
+	return n
+}
+
+// parseNamedLevels parses "name=level,name=level"
+func parseNamedLevels(spec string) (map[string]int, error) {
+	levels := make(map[string]int)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid named level %q: expected name=level", pair)
+		}
+		level, err := levelFromName(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+// levelFromName converts a level name such as "debug" or "WARN" into a level
+func levelFromName(name string) (int, error) {
+	upper := strings.ToUpper(name)
+	for level, n := range currentLevels.Load().names {
+		if n == upper {
+			return level, nil
+		}
+	}
+	// Built-in names keep working after a level is renamed
+	for level, n := range levelNames {
+		if n == upper {
+			return level, nil
+		}
+	}
+	if upper == "WARNING" {
+		return WARN, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// resetNamedLevels makes every named logger inherit its level again
+func (l *Logger) resetNamedLevels() {
+	l.namedMu.Lock()
+	defer l.namedMu.Unlock()
+
+	for _, n := range l.namedLevels {
+		n.level.Store(levelUnset)
+	}
+}
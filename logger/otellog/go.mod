@@ -0,0 +1,12 @@
+module github.com/jbarasa/logger/logger/otellog
+
+go 1.21
+
+require (
+	github.com/jbarasa/logger v1.0.2
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
+)
+
+// Build against the logger in this repository
+replace github.com/jbarasa/logger => ../..
@@ -0,0 +1,123 @@
+// Package otellog correlates log entries with OpenTelemetry traces. Entries
+// logged through a context carrying a span get its trace_id and span_id, so
+// logs can be joined with traces in Tempo, Jaeger or any OTel backend, and
+// entries can optionally be recorded as events on the span itself.
+//
+//	logger.Initialize(logger.Config{
+//	    ContextExtractors: []logger.ContextExtractor{otellog.TraceFields},
+//	})
+//	logger.AddLevelHook(otellog.SpanEventHook(otellog.Options{}), logger.LevelsFrom(logger.INFO)...)
+//
+//	func handle(ctx context.Context) {
+//	    ctx, span := tracer.Start(ctx, "handle")
+//	    defer span.End()
+//	    logger.WithContext(ctx).Info("processing order %d", id) // ... trace_id=4bf9... span_id=00f0...
+//	}
+//
+// The package is a separate module so the logger itself does not depend on OpenTelemetry.
+package otellog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbarasa/logger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names of the span context, following the OpenTelemetry log data model
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// TraceFields returns the trace and span IDs of the span in ctx, or nil when
+// ctx carries no valid span. Use it in Config.ContextExtractors.
+func TraceFields(ctx context.Context) []logger.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []logger.Field{
+		{Key: TraceIDKey, Value: sc.TraceID().String()},
+		{Key: SpanIDKey, Value: sc.SpanID().String()},
+		{Key: TraceFlagsKey, Value: sc.TraceFlags().String()},
+	}
+}
+
+// Options configures SpanEventHook
+type Options struct {
+	EventName      string // Name of the span events (default: "log")
+	SetErrorStatus bool   // Mark the span as failed when an ERROR or more severe entry is logged
+}
+
+// SpanEventHook returns a hook that adds each entry logged with a recording
+// span in its context as an event on that span, with the level, message and
+// fields as attributes. Register it with logger.AddLevelHook to limit which
+// levels become events.
+func SpanEventHook(opts Options) logger.Hook {
+	if opts.EventName == "" {
+		opts.EventName = "log"
+	}
+	return func(e *logger.Entry) error {
+		if e.Context == nil {
+			return nil
+		}
+		span := trace.SpanFromContext(e.Context)
+		if !span.IsRecording() {
+			return nil
+		}
+
+		attrs := make([]attribute.KeyValue, 0, 2+len(e.Fields))
+		attrs = append(attrs,
+			attribute.String("log.severity", logger.LevelName(e.Level)),
+			attribute.String("log.message", e.Message),
+		)
+		for _, f := range e.Fields {
+			switch f.Key {
+			case TraceIDKey, SpanIDKey, TraceFlagsKey:
+				// Already implied by the span
+				continue
+			}
+			attrs = append(attrs, attributeOf(f.Key, f.Value))
+		}
+		span.AddEvent(opts.EventName, trace.WithTimestamp(e.Time), trace.WithAttributes(attrs...))
+
+		if opts.SetErrorStatus && logger.BaseLevel(e.Level) >= logger.ERROR {
+			span.SetStatus(codes.Error, e.Message)
+		}
+		return nil
+	}
+}
+
+// attributeOf converts a field into a span attribute, keeping the common
+// scalar types and rendering anything else as a string
+func attributeOf(key string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case error:
+		return attribute.String(key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
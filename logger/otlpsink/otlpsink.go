@@ -0,0 +1,529 @@
+// Package otlpsink exports log entries to an OpenTelemetry collector over
+// OTLP/HTTP (protobuf) or OTLP/gRPC, so logs travel the same pipeline as
+// traces and metrics.
+//
+//	s, err := otlpsink.New(otlpsink.Options{
+//	    Protocol: otlpsink.GRPC,
+//	    Endpoint: "otel-collector:4317",
+//	    Insecure: true,
+//	    Resource: map[string]string{"service.name": "api", "deployment.environment": "prod"},
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{s}})
+//
+// Levels map to OTLP severity numbers and fields to attributes; the caller is
+// recorded as code.filepath, code.lineno and code.function. trace_id and
+// span_id fields, such as those added by the otellog module, become the
+// record's trace context. Entries are queued in memory and exported in
+// batches from a background goroutine; failed exports are retried with
+// exponential backoff.
+//
+// The package is a separate module so the logger itself does not depend on
+// gRPC or protobuf.
+package otlpsink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the compressor used with Gzip
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protocol selects the OTLP transport
+type Protocol int
+
+const (
+	HTTP Protocol = iota // OTLP/HTTP with protobuf bodies (default)
+	GRPC                 // OTLP/gRPC
+)
+
+// logsPath is appended to HTTP endpoints that do not already name it
+const logsPath = "/v1/logs"
+
+// Default collector endpoints
+const (
+	DefaultHTTPEndpoint = "http://localhost:4318"
+	DefaultGRPCEndpoint = "localhost:4317"
+)
+
+// Options configures the OTLP sink
+type Options struct {
+	Protocol  Protocol          // HTTP (default) or GRPC
+	Endpoint  string            // Collector URL for HTTP, host:port for gRPC (default: DefaultHTTPEndpoint or DefaultGRPCEndpoint)
+	Insecure  bool              // gRPC without TLS
+	TLSConfig *tls.Config       // gRPC TLS settings (default: system roots)
+	Headers   map[string]string // HTTP headers or gRPC metadata sent with every export, e.g. authentication
+	Resource  map[string]string // Resource attributes (default: service.name of the executable)
+	Scope     string            // Instrumentation scope name (default: "github.com/jbarasa/logger")
+	Gzip      bool              // Compress HTTP bodies and gRPC messages
+	Timeout   time.Duration     // Limit of a single export (default: 10s)
+
+	BatchSize     int           // Entries per export (default: 512)
+	FlushInterval time.Duration // Maximum time an entry waits before being exported (default: 1s)
+	MaxPending    int           // Entries queued in memory; the oldest are dropped beyond this (default: 50000)
+
+	MaxRetries   int           // Retries after a failed export (default: 3)
+	RetryBackoff time.Duration // Delay before the first retry, doubled for each one (default: 500ms)
+
+	OnError func(err error) // Called when entries are dropped (default: print to stderr)
+}
+
+// Sink exports entries to an OpenTelemetry collector
+type Sink struct {
+	opts     Options
+	url      string
+	client   *http.Client
+	conn     *grpc.ClientConn
+	grpc     collogspb.LogsServiceClient
+	resource *resourcepb.Resource
+	scope    *commonpb.InstrumentationScope
+
+	mu      sync.Mutex
+	pending []*logspb.LogRecord
+	kick    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// New validates opts, connects to the collector and starts the background exporter
+func New(opts Options) (*Sink, error) {
+	if opts.Scope == "" {
+		opts.Scope = "github.com/jbarasa/logger"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 50000
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) { fmt.Fprintf(os.Stderr, "otlpsink: %v\n", err) }
+	}
+	if len(opts.Resource) == 0 {
+		opts.Resource = map[string]string{"service.name": filepath.Base(os.Args[0])}
+	}
+
+	s := &Sink{
+		opts:     opts,
+		resource: &resourcepb.Resource{Attributes: stringAttributes(opts.Resource)},
+		scope:    &commonpb.InstrumentationScope{Name: opts.Scope},
+		kick:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	switch opts.Protocol {
+	case HTTP:
+		endpoint := opts.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultHTTPEndpoint
+		}
+		s.url = strings.TrimRight(endpoint, "/")
+		if !strings.HasSuffix(s.url, logsPath) {
+			s.url += logsPath
+		}
+		s.client = &http.Client{Timeout: opts.Timeout}
+	case GRPC:
+		endpoint := opts.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultGRPCEndpoint
+		}
+		creds := credentials.NewTLS(opts.TLSConfig)
+		if opts.Insecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to collector: %v", err)
+		}
+		s.conn = conn
+		s.grpc = collogspb.NewLogsServiceClient(conn)
+	default:
+		return nil, errors.New("otlpsink: unknown Protocol")
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Severity maps a logger level to an OTLP severity number
+func Severity(level int) logspb.SeverityNumber {
+	switch level = logger.BaseLevel(level); {
+	case level <= logger.TRACE:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_TRACE
+	case level == logger.DEBUG:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case level == logger.INFO:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case level == logger.WARN:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case level == logger.ERROR:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case level == logger.PANIC:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4
+	}
+}
+
+// WriteEntries queues a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	records := make([]*logspb.LogRecord, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, record(e))
+	}
+	s.queue(records)
+	return nil
+}
+
+// Hook queues a single entry; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.WriteEntries([]*logger.Entry{e})
+}
+
+// Write queues each line of formatted output as an INFO entry, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	var entries []*logger.Entry
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		entries = append(entries, &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)})
+	}
+	s.WriteEntries(entries)
+	return len(p), nil
+}
+
+// Close exports everything still queued, stops the background exporter and
+// closes the gRPC connection
+func (s *Sink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		if s.conn != nil {
+			err = s.conn.Close()
+		}
+	})
+	return err
+}
+
+// record converts an entry into an OTLP log record
+func record(e *logger.Entry) *logspb.LogRecord {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	r := &logspb.LogRecord{
+		TimeUnixNano:         uint64(t.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       Severity(e.Level),
+		SeverityText:         logger.LevelName(e.Level),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: e.Message}},
+		Attributes:           make([]*commonpb.KeyValue, 0, len(e.Fields)+3),
+	}
+	if e.File != "" {
+		r.Attributes = append(r.Attributes,
+			keyValue("code.filepath", e.File),
+			keyValue("code.lineno", e.Line))
+	}
+	if e.Function != "" {
+		r.Attributes = append(r.Attributes, keyValue("code.function", e.Function))
+	}
+
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "trace_id":
+			if id, ok := traceID(f.Value, 16); ok {
+				r.TraceId = id
+				continue
+			}
+		case "span_id":
+			if id, ok := traceID(f.Value, 8); ok {
+				r.SpanId = id
+				continue
+			}
+		case "trace_flags":
+			if flags, ok := f.Value.(string); ok && len(flags) == 2 {
+				if b, err := hex.DecodeString(flags); err == nil {
+					r.Flags = uint32(b[0])
+					continue
+				}
+			}
+		}
+		r.Attributes = append(r.Attributes, keyValue(f.Key, f.Value))
+	}
+	return r
+}
+
+// traceID decodes a hex trace or span ID of n bytes
+func traceID(v interface{}, n int) ([]byte, bool) {
+	str, ok := v.(string)
+	if !ok || len(str) != 2*n {
+		return nil, false
+	}
+	id, err := hex.DecodeString(str)
+	return id, err == nil
+}
+
+// keyValue converts a field into an OTLP attribute, keeping the common
+// scalar types and rendering anything else as a string
+func keyValue(key string, v interface{}) *commonpb.KeyValue {
+	var value commonpb.AnyValue
+	switch v := v.(type) {
+	case string:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: v}
+	case bool:
+		value.Value = &commonpb.AnyValue_BoolValue{BoolValue: v}
+	case int:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case int8:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case int16:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case int32:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case int64:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: v}
+	case uint8:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case uint16:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case uint32:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	case float32:
+		value.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}
+	case float64:
+		value.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: v}
+	case []byte:
+		value.Value = &commonpb.AnyValue_BytesValue{BytesValue: v}
+	case error:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: v.Error()}
+	case fmt.Stringer:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: v.String()}
+	default:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}
+	}
+	return &commonpb.KeyValue{Key: key, Value: &value}
+}
+
+// stringAttributes converts a string map into OTLP attributes
+func stringAttributes(m map[string]string) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(m))
+	for k, v := range m {
+		attrs = append(attrs, keyValue(k, v))
+	}
+	return attrs
+}
+
+// queue adds records, dropping the oldest queued ones when the queue is full
+func (s *Sink) queue(records []*logspb.LogRecord) {
+	s.mu.Lock()
+	s.pending = append(s.pending, records...)
+	dropped := 0
+	if over := len(s.pending) - s.opts.MaxPending; over > 0 {
+		s.pending = s.pending[over:]
+		dropped = over
+	}
+	full := len(s.pending) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	if dropped > 0 {
+		s.opts.OnError(fmt.Errorf("dropped %d entries: queue full", dropped))
+	}
+}
+
+// run exports batches when one is full or the flush interval passes
+func (s *Sink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.kick:
+			s.exportPending(false)
+		case <-ticker.C:
+			s.exportPending(true)
+		case <-s.done:
+			s.exportPending(true)
+			return
+		}
+	}
+}
+
+// exportPending exports queued records in batches. Unless all is set only
+// full batches are exported.
+func (s *Sink) exportPending(all bool) {
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n == 0 || (!all && n < s.opts.BatchSize) {
+			s.mu.Unlock()
+			return
+		}
+		if n > s.opts.BatchSize {
+			n = s.opts.BatchSize
+		}
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if err := s.export(batch); err != nil {
+			s.opts.OnError(fmt.Errorf("dropped %d entries: %v", len(batch), err))
+		}
+	}
+}
+
+// export sends a batch, retrying with exponential backoff on failures the
+// OTLP specification marks as retryable
+func (s *Sink) export(batch []*logspb.LogRecord) error {
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:  s.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{Scope: s.scope, LogRecords: batch}},
+		}},
+	}
+
+	send := s.sendHTTP
+	if s.grpc != nil {
+		send = s.sendGRPC
+	}
+
+	backoff := s.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := send(req)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// Shutting down: one immediate last attempt instead of waiting
+			_, err := send(req)
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// sendHTTP makes a single OTLP/HTTP request and reports whether a failure is
+// worth retrying
+func (s *Sink) sendHTTP(req *collogspb.ExportLogsServiceRequest) (bool, error) {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode logs: %v", err)
+	}
+	if s.opts.Gzip {
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		if _, err := zw.Write(body); err != nil {
+			return false, fmt.Errorf("failed to compress logs: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			return false, fmt.Errorf("failed to compress logs: %v", err)
+		}
+		body = gz.Bytes()
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	if s.opts.Gzip {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range s.opts.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return true, fmt.Errorf("failed to export logs: %v", err)
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, fmt.Errorf("failed to export logs: %s", resp.Status)
+	}
+	return false, fmt.Errorf("failed to export logs: %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+// sendGRPC makes a single OTLP/gRPC call and reports whether a failure is
+// worth retrying
+func (s *Sink) sendGRPC(req *collogspb.ExportLogsServiceRequest) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	if len(s.opts.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(s.opts.Headers))
+	}
+
+	var callOpts []grpc.CallOption
+	if s.opts.Gzip {
+		callOpts = append(callOpts, grpc.UseCompressor("gzip"))
+	}
+	if _, err := s.grpc.Export(ctx, req, callOpts...); err != nil {
+		switch status.Code(err) {
+		case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+			codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+			return true, fmt.Errorf("failed to export logs: %v", err)
+		}
+		return false, fmt.Errorf("failed to export logs: %v", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,45 @@
+package logger
+
+import "time"
+
+// OverflowPolicy decides what happens to an entry when the buffer is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	OverflowDrop             OverflowPolicy = iota // Drop the entry so the caller never waits (default)
+	OverflowBlock                                  // Wait for buffer space; no entry is lost but callers slow down with the disk
+	OverflowBlockWithTimeout                       // Wait up to Config.BlockTimeout, then drop the entry
+	OverflowSpill                                  // Append the entry to Config.SpillPath and write it once the buffer drains
+)
+
+// trySend queues a non-priority entry according to the overflow policy and
+// reports whether it was accepted. The caller is counted in l.sending.
+func (l *Logger) trySend(entry *logEntry) bool {
+	// Entries follow those still in the spill file, to keep their order
+	if l.spill != nil && l.spill.active.Load() && l.spillEntry(entry) {
+		return true
+	}
+
+	select {
+	case l.logChan <- entry:
+		return true
+	default:
+	}
+
+	if l.spill != nil {
+		return l.spillEntry(entry)
+	}
+	if l.overflow != OverflowBlockWithTimeout {
+		return false
+	}
+
+	timer := time.NewTimer(l.blockTimeout)
+	defer timer.Stop()
+	select {
+	case l.logChan <- entry:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
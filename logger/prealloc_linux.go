@@ -0,0 +1,30 @@
+//go:build linux
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// canPreallocate reports whether Config.Preallocate is supported
+const canPreallocate = true
+
+// fallocKeepSize is FALLOC_FL_KEEP_SIZE: reserve blocks without changing the
+// file size, so readers never see the reserved space
+const fallocKeepSize = 0x1
+
+// preallocate reserves n bytes of disk space from off in f
+func preallocate(f *os.File, off, n int64) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := rc.Control(func(fd uintptr) {
+		ferr = syscall.Fallocate(int(fd), fallocKeepSize, off, n)
+	}); err != nil {
+		return err
+	}
+	return ferr
+}
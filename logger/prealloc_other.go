@@ -0,0 +1,11 @@
+//go:build !linux
+
+package logger
+
+import "os"
+
+// canPreallocate reports whether Config.Preallocate is supported
+const canPreallocate = false
+
+// preallocate is a no-op on platforms without fallocate
+func preallocate(f *os.File, off, n int64) error { return nil }
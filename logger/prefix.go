@@ -0,0 +1,25 @@
+package logger
+
+// WithPrefix returns a child logger whose messages start with "[prefix] ", so
+// lines from concurrent workers can be told apart without building the
+// prefix into every format string. Prefixes nest: WithPrefix("pool").
+// WithPrefix("worker-7") writes "[pool] [worker-7] message". The prefix is
+// part of the message in every format; use With for a separate field.
+func (l *Logger) WithPrefix(prefix string) *Logger {
+	if l == nil || prefix == "" {
+		return l
+	}
+	child := l.withFields(nil)
+	child.prefix = l.prefix + "[" + prefix + "] "
+	return child
+}
+
+// WithPrefix returns a child of the default logger with a message prefix
+func WithPrefix(prefix string) *Logger {
+	return defaultLogger.WithPrefix(prefix)
+}
+
+// Prefix returns the message prefix added with WithPrefix, or ""
+func (l *Logger) Prefix() string {
+	return l.prefix
+}
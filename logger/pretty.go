@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsoleStyle selects the layout of the console output of IsDev and Console
+type ConsoleStyle int
+
+// Console styles
+const (
+	ConsoleStandard ConsoleStyle = iota // The text file layout with colored levels (default)
+	ConsolePretty                       // Short times, aligned columns, short callers, readable durations and sizes, stacks on their own lines
+)
+
+// prettyCallerWidth is the column width of callers in pretty output
+const prettyCallerWidth = 20
+
+// PrettyFormatter renders entries the way ConsolePretty prints them, for
+// tools that display log files. Color adds ANSI colors, from Theme if set.
+type PrettyFormatter struct {
+	Color bool
+	Theme ColorTheme
+}
+
+// Format renders e in the pretty layout
+func (f PrettyFormatter) Format(e Entry) []byte {
+	var buf bytes.Buffer
+	appendPretty(&buf, e.Time, e.Level, e.caller(), e.Message, f.Color, f.Theme, e.Fields)
+	return buf.Bytes()
+}
+
+// appendPretty renders an entry for ConsolePretty
+func (l *Logger) appendPretty(buf *bytes.Buffer, entry *logEntry, caller string) {
+	appendPretty(buf, l.timeOf(entry.timestamp), entry.level, caller, entry.msg, l.color, l.theme, l.serviceFields, entry.fields)
+}
+
+// appendPretty renders a pretty line, e.g.
+//
+//	10:04:05.123 INFO  handler.go:42         > Request served status=200 took=12.35ms size=1.5KB
+func appendPretty[S string | []byte](buf *bytes.Buffer, t time.Time, level int, caller string, msg S, color bool, theme ColorTheme, fieldSets ...[]Field) {
+	paint := func(code string) {
+		if color {
+			buf.WriteString(code)
+		}
+	}
+
+	paint(colorGray)
+	buf.Write(t.AppendFormat(buf.AvailableBuffer(), "15:04:05.000"))
+	paint(colorReset)
+	buf.WriteByte(' ')
+
+	name := LevelName(level)
+	paint(theme.color(level))
+	buf.WriteString(name)
+	paint(colorReset)
+	if pad := 6 - len(name); pad > 0 {
+		buf.WriteString(strings.Repeat(" ", pad))
+	}
+
+	if caller != "" {
+		if i := strings.LastIndexByte(caller, '/'); i >= 0 {
+			caller = caller[i+1:]
+		}
+		paint(colorGray)
+		buf.WriteString(caller)
+		if pad := prettyCallerWidth - len(caller); pad > 0 {
+			buf.WriteString(strings.Repeat(" ", pad))
+		}
+		buf.WriteString(" >")
+		paint(colorReset)
+		buf.WriteByte(' ')
+	}
+	buf.Write(append(buf.AvailableBuffer(), msg...))
+
+	var stack string
+	for _, fields := range fieldSets {
+		for _, f := range fields {
+			if s, ok := f.Value.(string); ok && f.Key == "stack" && strings.Contains(s, "\n") {
+				stack = s
+				continue
+			}
+			buf.WriteByte(' ')
+			paint(colorGray)
+			buf.WriteString(f.Key)
+			buf.WriteByte('=')
+			paint(colorReset)
+			appendPrettyValue(buf, f.Value)
+		}
+	}
+	buf.WriteByte('\n')
+
+	if stack != "" {
+		for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+			buf.WriteString("    ")
+			buf.WriteString(strings.Replace(line, "\t", "    ", 1))
+			buf.WriteByte('\n')
+		}
+	}
+	for _, fields := range fieldSets {
+		appendBlocks(buf, fields)
+	}
+}
+
+// appendPrettyValue writes a field value, humanizing durations and byte sizes
+func appendPrettyValue(buf *bytes.Buffer, v interface{}) {
+	switch val := v.(type) {
+	case time.Duration:
+		buf.WriteString(humanDuration(val))
+	case ByteSize:
+		buf.WriteString(humanBytes(int64(val)))
+	default:
+		appendTextValue(buf, v)
+	}
+}
+
+// humanDuration renders a duration with about three significant digits,
+// e.g. 12.35ms instead of 12.345678ms
+func humanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		return d.Round(time.Second).String()
+	case abs >= time.Second:
+		return strconv.FormatFloat(d.Seconds(), 'f', 2, 64) + "s"
+	case abs >= time.Millisecond:
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64) + "ms"
+	case abs >= time.Microsecond:
+		return strconv.FormatFloat(float64(d)/float64(time.Microsecond), 'f', 2, 64) + "µs"
+	default:
+		return d.String()
+	}
+}
+
+// humanBytes renders a byte count with the units of configuration sizes,
+// e.g. 1.5KB
+func humanBytes(n int64) string {
+	f := float64(n)
+	for _, unit := range []string{"B", "KB", "MB", "GB"} {
+		if f < 1024 && f > -1024 || unit == "GB" {
+			if unit == "B" {
+				return strconv.FormatInt(n, 10) + unit
+			}
+			return strconv.FormatFloat(f, 'f', 1, 64) + unit
+		}
+		f /= 1024
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityBypassesSampling(t *testing.T) {
+	l := newTestLogger(t, Config{
+		Level:    INFO,
+		Sampling: &SamplingConfig{Tick: time.Hour, Initial: 1},
+	})
+
+	for i := 0; i < 100; i++ {
+		l.Info("hot path")
+		l.MustInfo("critical")
+	}
+	l.MustDebug("below the level")
+
+	lines := readLines(t, l)
+	if n := countLines(lines, "hot path"); n != 1 {
+		t.Errorf("sampled entry written %d times, want 1", n)
+	}
+	if n := countLines(lines, "critical"); n != 100 {
+		t.Errorf("priority entry written %d times, want 100", n)
+	}
+	if n := countLines(lines, "below the level"); n != 0 {
+		t.Errorf("priority entry below the level written %d times, want 0", n)
+	}
+	if s := l.Stats().Sampled; s != 99 {
+		t.Errorf("Sampled = %d, want 99", s)
+	}
+}
+
+func TestPriorityBypassesRateLimit(t *testing.T) {
+	l := newTestLogger(t, Config{RateLimit: 1, RateBurst: 1, RateLimitReport: -1})
+
+	for i := 0; i < 50; i++ {
+		l.Info("normal")
+		l.MustError("critical")
+	}
+
+	lines := readLines(t, l)
+	if n := countLines(lines, "normal"); n != 1 {
+		t.Errorf("rate limited entry written %d times, want 1", n)
+	}
+	if n := countLines(lines, "critical"); n != 50 {
+		t.Errorf("priority entry written %d times, want 50", n)
+	}
+}
+
+func TestPriorityBypassesDedup(t *testing.T) {
+	l := newTestLogger(t, Config{Dedup: &DedupConfig{Window: time.Hour}})
+
+	l.Error("boom")
+	l.Error("boom")
+	for i := 0; i < 5; i++ {
+		l.MustError("boom")
+	}
+	l.Error("boom")
+	l.Error("boom")
+	l.Error("boom")
+
+	// The priority entries end the first run, and the entries after them start a new one
+	lines := readLines(t, l)
+	if n := countLines(lines, "] boom"); n != 7 {
+		t.Errorf("boom written %d times, want 7:\n%v", n, lines)
+	}
+	if n := countLines(lines, "last message repeated 1 times"); n != 1 {
+		t.Errorf("summary of the first run written %d times, want 1", n)
+	}
+	if n := countLines(lines, "last message repeated 2 times"); n != 1 {
+		t.Errorf("summary of the second run written %d times, want 1", n)
+	}
+	if d := l.Stats().Deduplicated; d != 3 {
+		t.Errorf("Deduplicated = %d, want 3", d)
+	}
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tokenBucket is a token-bucket rate limiter. It starts full, so up to burst
+// entries pass immediately before the steady-state rate applies.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64   // Tokens added per second
+	burst  float64   // Maximum number of stored tokens
+	tokens float64   // Currently available tokens
+	last   time.Time // Last refill time
+}
+
+// newTokenBucket creates a full bucket refilled at rate tokens per second
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = int(rate)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// allow reports whether an entry may pass, consuming a token if so
+func (b *tokenBucket) allow() bool {
+	return b.allowAt(time.Now())
+}
+
+// allowAt is allow with an explicit clock reading
+func (b *tokenBucket) allowAt(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RateLimitKey selects what Config.RateLimit applies to
+type RateLimitKey int
+
+// Rate limit keys
+const (
+	RateLimitGlobal      RateLimitKey = iota // One limit shared by every entry (default)
+	RateLimitPerLevel                        // A separate limit for each level
+	RateLimitPerCallSite                     // A separate limit for each file:line, so one noisy loop cannot starve the rest
+)
+
+// defaultRateLimitReport is how often suppressed entries are summarized
+const defaultRateLimitReport = 10 * time.Second
+
+// rateBucket is the token bucket of one key with the entries it suppressed
+// since the last summary
+type rateBucket struct {
+	*tokenBucket
+	suppressed atomic.Uint64
+}
+
+// rateLimiter applies a token bucket per key
+type rateLimiter struct {
+	by     RateLimitKey
+	rate   float64
+	burst  int
+	all    rateBucket                    // RateLimitGlobal
+	levels [FATAL - TRACE + 1]rateBucket // RateLimitPerLevel, indexed by level - TRACE
+	sites  sync.Map                      // RateLimitPerCallSite: callerKey to *rateBucket
+}
+
+// newRateLimiter creates a limiter allowing rate entries per second per key
+func newRateLimiter(rate float64, burst int, by RateLimitKey) *rateLimiter {
+	r := &rateLimiter{by: by, rate: rate, burst: burst}
+	switch by {
+	case RateLimitPerLevel:
+		for i := range r.levels {
+			r.levels[i].tokenBucket = newTokenBucket(rate, burst)
+		}
+	case RateLimitPerCallSite:
+	default:
+		r.all.tokenBucket = newTokenBucket(rate, burst)
+	}
+	return r
+}
+
+// allow reports whether an entry may pass, counting it as suppressed if not
+func (r *rateLimiter) allow(level int, file string, line int) bool {
+	b := r.bucket(level, file, line)
+	if b.allow() {
+		return true
+	}
+	b.suppressed.Add(1)
+	return false
+}
+
+// bucket returns the bucket of an entry's key
+func (r *rateLimiter) bucket(level int, file string, line int) *rateBucket {
+	switch r.by {
+	case RateLimitPerLevel:
+		return &r.levels[BaseLevel(level)-TRACE]
+	case RateLimitPerCallSite:
+		// A program logs from a bounded set of call sites, as in the caller cache
+		key := callerKey{file, line}
+		if b, ok := r.sites.Load(key); ok {
+			return b.(*rateBucket)
+		}
+		b, _ := r.sites.LoadOrStore(key, &rateBucket{tokenBucket: newTokenBucket(r.rate, r.burst)})
+		return b.(*rateBucket)
+	}
+	return &r.all
+}
+
+// watchRateLimit logs a summary of suppressed entries every interval
+func (l *Logger) watchRateLimit(interval time.Duration) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.reportSuppressed(interval)
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
+
+// reportSuppressed logs one WARN entry per key that suppressed entries since
+// the last report, e.g. "Rate limit suppressed 1234 similar messages in the
+// last 10s". Summaries bypass the limiter.
+func (l *Logger) reportSuppressed(interval time.Duration) {
+	report := func(b *rateBucket, what string, fields []Field) {
+		n := b.suppressed.Swap(0)
+		if n == 0 {
+			return
+		}
+		msg := fmt.Sprintf("Rate limit suppressed %d %s in the last %s", n, what, interval)
+		fields = append(fields, Field{Key: "suppressed", Value: n})
+		l.dispatch(WARN, flagPriority, msg, fields, 0, "", 0)
+	}
+
+	r := l.limiter
+	switch r.by {
+	case RateLimitPerLevel:
+		for i := range r.levels {
+			name := LevelName(TRACE + i)
+			report(&r.levels[i], name+" messages", []Field{{Key: "level", Value: name}})
+		}
+	case RateLimitPerCallSite:
+		r.sites.Range(func(k, v interface{}) bool {
+			key := k.(callerKey)
+			site := "unknown" // Caller lookup is disabled
+			if key.file != "" {
+				site = l.callerFile(key.file) + ":" + strconv.Itoa(key.line)
+			}
+			report(v.(*rateBucket), "similar messages", []Field{{Key: "call_site", Value: site}})
+			return true
+		})
+	default:
+		report(&r.all, "messages", nil)
+	}
+}
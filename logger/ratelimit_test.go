@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurstThenThrottle(t *testing.T) {
+	b := newTokenBucket(8, 50)
+	now := b.last
+
+	// A startup burst within the allowance passes in full
+	passed := 0
+	for i := 0; i < 50; i++ {
+		if b.allowAt(now) {
+			passed++
+		}
+	}
+	if passed != 50 {
+		t.Errorf("%d of a 50 entry burst passed, want all", passed)
+	}
+	if b.allowAt(now) {
+		t.Error("entry past the burst passed before any refill")
+	}
+
+	// Sustained traffic of 32 entries a second is held to the rate of 8
+	passed = 0
+	for tick := 0; tick < 80; tick++ {
+		now = now.Add(125 * time.Millisecond)
+		for i := 0; i < 4; i++ {
+			if b.allowAt(now) {
+				passed++
+			}
+		}
+	}
+	if passed != 80 {
+		t.Errorf("%d of 320 entries over 10s passed, want 80", passed)
+	}
+
+	// An idle period refills the bucket up to the burst, no further
+	now = now.Add(time.Hour)
+	passed = 0
+	for i := 0; i < 100; i++ {
+		if b.allowAt(now) {
+			passed++
+		}
+	}
+	if passed != 50 {
+		t.Errorf("%d entries passed after an idle hour, want the burst of 50", passed)
+	}
+}
@@ -0,0 +1,342 @@
+package reader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Default timestamp layouts of the logger's formats
+const (
+	textTimeFormat = "2006/01/02 15:04:05"
+	jsonTimeFormat = time.RFC3339Nano
+)
+
+// errContinuation marks the indented lines below an entry, such as error
+// chains, which belong to the entry above them
+var errContinuation = errors.New("continuation line")
+
+// ParseLine parses one line written by the logger in any of its formats:
+// Text, JSON or Logfmt. Field values of JSON lines keep their JSON types
+// (string, float64, bool, nil, map or slice); those of text and logfmt lines
+// are strings. Caller holds the caller as written, File and Line its parts.
+func ParseLine(line []byte, opts Options) (logger.Entry, error) {
+	s := strings.TrimRight(string(line), "\r\n")
+	switch {
+	case s == "":
+		return logger.Entry{}, errors.New("empty line")
+	case s[0] == ' ' || s[0] == '\t':
+		return logger.Entry{}, errContinuation
+	case s[0] == '{':
+		return opts.parseJSON(s)
+	case strings.HasPrefix(s, "ts="):
+		return opts.parseLogfmt(s)
+	default:
+		return opts.parseText(s)
+	}
+}
+
+// parseJSON parses a JSON line:
+// {"time":...,"level":...,"caller":...,"func":...,"msg":...,"service":{...},"fields":{...}}
+func (o *Options) parseJSON(s string) (logger.Entry, error) {
+	var line struct {
+		Time     json.RawMessage        `json:"time"`
+		Level    string                 `json:"level"`
+		Caller   string                 `json:"caller"`
+		Function string                 `json:"func"`
+		Msg      string                 `json:"msg"`
+		Service  map[string]interface{} `json:"service"`
+		Fields   map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(s), &line); err != nil {
+		return logger.Entry{}, fmt.Errorf("failed to parse JSON line: %v", err)
+	}
+
+	var entry logger.Entry
+	ts := string(line.Time)
+	if unquoted, err := strconv.Unquote(ts); err == nil {
+		ts = unquoted
+	}
+	var err error
+	if entry.Time, err = o.parseTime(ts, jsonTimeFormat); err != nil {
+		return logger.Entry{}, err
+	}
+	if entry.Level, err = parseLevel(line.Level); err != nil {
+		return logger.Entry{}, err
+	}
+	entry.Message = line.Msg
+	entry.Function = line.Function
+	setCaller(&entry, line.Caller)
+	entry.Fields = append(sortedFields(line.Service), sortedFields(line.Fields)...)
+	return entry, nil
+}
+
+// parseLogfmt parses a logfmt line:
+// ts=... level=info caller=file:line msg="..." func=... key=value ...
+func (o *Options) parseLogfmt(s string) (logger.Entry, error) {
+	pairs, ok := splitPairs(s)
+	if !ok {
+		return logger.Entry{}, errors.New("malformed logfmt line")
+	}
+
+	var entry logger.Entry
+	var seen [5]bool // ts, level, caller, msg and func are taken from their first pair
+	for _, p := range pairs {
+		var err error
+		switch {
+		case p.key == "ts" && !seen[0]:
+			seen[0] = true
+			entry.Time, err = o.parseTime(p.value, jsonTimeFormat)
+		case p.key == "level" && !seen[1]:
+			seen[1] = true
+			entry.Level, err = parseLevel(p.value)
+		case p.key == "caller" && !seen[2]:
+			seen[2] = true
+			setCaller(&entry, p.value)
+		case p.key == "msg" && !seen[3]:
+			seen[3] = true
+			entry.Message = p.value
+		case p.key == "func" && !seen[4]:
+			seen[4] = true
+			entry.Function = p.value
+		default:
+			entry.Fields = append(entry.Fields, logger.Field{Key: p.key, Value: p.value})
+		}
+		if err != nil {
+			return logger.Entry{}, err
+		}
+	}
+	if !seen[0] || !seen[1] {
+		return logger.Entry{}, errors.New("logfmt line without ts or level")
+	}
+	return entry, nil
+}
+
+// parseText parses a text line:
+// time [LEVEL] [file:line func] message key=value ...
+// The message is unquoted, so trailing words of the form key=value are read
+// as fields.
+func (o *Options) parseText(s string) (logger.Entry, error) {
+	var entry logger.Entry
+	i := strings.Index(s, " [")
+	if i < 0 {
+		return logger.Entry{}, errors.New("text line without a level")
+	}
+	var err error
+	if entry.Time, err = o.parseTime(s[:i], textTimeFormat); err != nil {
+		return logger.Entry{}, err
+	}
+	rest := s[i+2:]
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return logger.Entry{}, errors.New("text line without a level")
+	}
+	if entry.Level, err = parseLevel(rest[:end]); err != nil {
+		return logger.Entry{}, err
+	}
+	rest = strings.TrimPrefix(rest[end+1:], " ")
+
+	// A bracketed segment is the caller when it starts with file:line; otherwise
+	// it is a WithPrefix prefix and part of the message
+	if strings.HasPrefix(rest, "[") {
+		if end := strings.Index(rest, "] "); end > 0 {
+			caller, function, _ := strings.Cut(rest[1:end], " ")
+			if isCaller(caller) {
+				setCaller(&entry, caller)
+				entry.Function = function
+				rest = rest[end+2:]
+			}
+		}
+	}
+
+	entry.Message, entry.Fields = splitMessage(rest)
+	return entry, nil
+}
+
+// parseTime parses a timestamp with the configured or the default layout.
+// Epoch numbers are read in the unit of Options.TimeFormat; without a
+// TimeFormat their unit is guessed from their size.
+func (o *Options) parseTime(s, defaultLayout string) (time.Time, error) {
+	switch o.TimeFormat {
+	case "", logger.TimeFormatUnix, logger.TimeFormatUnixMilli, logger.TimeFormatUnixNano:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return epochTime(n, o.TimeFormat), nil
+		}
+	}
+
+	layout := o.TimeFormat
+	if layout == "" {
+		layout = defaultLayout
+	}
+	loc := o.TimeLocation
+	if loc == nil {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %v", s, err)
+	}
+	return t, nil
+}
+
+// epochTime converts an epoch number in the unit of format, or when format
+// is empty, in the unit its size suggests
+func epochTime(n int64, format string) time.Time {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case format == logger.TimeFormatUnixNano || format == "" && abs >= 1e15:
+		return time.Unix(0, n)
+	case format == logger.TimeFormatUnixMilli || format == "" && abs >= 1e11:
+		return time.UnixMilli(n)
+	default:
+		return time.Unix(n, 0)
+	}
+}
+
+// parseLevel parses a level name in any case
+func parseLevel(name string) (int, error) {
+	level, err := logger.ParseLevel(name)
+	return int(level), err
+}
+
+// isCaller reports whether s looks like a rendered caller, e.g. "api/handler.go:42"
+func isCaller(s string) bool {
+	i := strings.LastIndexByte(s, ':')
+	if i <= 0 || i == len(s)-1 {
+		return false
+	}
+	_, err := strconv.Atoi(s[i+1:])
+	return err == nil
+}
+
+// setCaller sets the caller of an entry and, when it has the file:line
+// form, its File and Line
+func setCaller(entry *logger.Entry, caller string) {
+	entry.Caller = caller
+	if i := strings.LastIndexByte(caller, ':'); i > 0 {
+		if line, err := strconv.Atoi(caller[i+1:]); err == nil {
+			entry.File, entry.Line = caller[:i], line
+		}
+	}
+}
+
+// sortedFields converts a decoded JSON object to fields ordered by key, as
+// Go's JSON encoding loses the written order
+func sortedFields(m map[string]interface{}) []logger.Field {
+	if len(m) == 0 {
+		return nil
+	}
+	fields := make([]logger.Field, 0, len(m))
+	for k, v := range m {
+		fields = append(fields, logger.Field{Key: k, Value: v})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// pair is a key=value token with its position in the line
+type pair struct {
+	key, value string
+	start      int
+}
+
+// splitPairs splits s into space separated key=value pairs with bare or
+// quoted values, reporting false when s has anything else
+func splitPairs(s string) ([]pair, bool) {
+	tokens, ok := tokenize(s)
+	if !ok {
+		return nil, false
+	}
+	for _, t := range tokens {
+		if t.key == "" {
+			return nil, false
+		}
+	}
+	return tokens, true
+}
+
+// splitMessage separates the message of a text line from the trailing
+// key=value fields
+func splitMessage(s string) (string, []logger.Field) {
+	tokens, ok := tokenize(s)
+	if !ok {
+		return s, nil
+	}
+	first := len(tokens)
+	for first > 0 && tokens[first-1].key != "" {
+		first--
+	}
+	if first == len(tokens) {
+		return s, nil
+	}
+	fields := make([]logger.Field, 0, len(tokens)-first)
+	for _, t := range tokens[first:] {
+		fields = append(fields, logger.Field{Key: t.key, Value: t.value})
+	}
+	return strings.TrimRight(s[:tokens[first].start], " "), fields
+}
+
+// tokenize splits s at spaces into tokens. Tokens of the form key=value have
+// their key set and a quoted value unquoted; other tokens only have a value.
+func tokenize(s string) ([]pair, bool) {
+	var tokens []pair
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+		start := i
+		eq := -1
+		for i < len(s) && s[i] != ' ' {
+			if s[i] == '=' && eq < 0 {
+				eq = i
+				if i+1 < len(s) && s[i+1] == '"' {
+					end, ok := quotedEnd(s, i+1)
+					if !ok {
+						return nil, false
+					}
+					i = end
+					break
+				}
+			}
+			i++
+		}
+		token := s[start:i]
+		if eq <= start || strings.ContainsRune(s[start:eq], '"') {
+			tokens = append(tokens, pair{value: token, start: start})
+			continue
+		}
+		value := s[eq+1 : i]
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, false
+			}
+			value = unquoted
+		}
+		tokens = append(tokens, pair{key: s[start:eq], value: value, start: start})
+	}
+	return tokens, true
+}
+
+// quotedEnd returns the index just past the quoted string starting at s[i]
+func quotedEnd(s string, i int) (int, bool) {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, true
+		}
+	}
+	return 0, false
+}
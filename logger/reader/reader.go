@@ -0,0 +1,262 @@
+// Package reader reads back the files written by
+// github.com/jbarasa/logger/logger, for tools and scripts that search logs
+// instead of grepping them.
+//
+// Open iterates the entries of a log file and, optionally, its rotated
+// archives, oldest first. Compressed and encrypted files are read
+// transparently, and the format of each line (Text, JSON or Logfmt) is
+// detected on its own:
+//
+//	r, err := reader.Open("logs/app.log", reader.Options{
+//	    Archives: true,
+//	    Filter: reader.Filter{
+//	        Since:  time.Now().Add(-24 * time.Hour),
+//	        Levels: logger.LevelsFrom(logger.ERROR),
+//	        Fields: map[string]string{"user_id": "42"},
+//	    },
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer r.Close()
+//
+//	for r.Next() {
+//	    e := r.Entry()
+//	    fmt.Println(e.Time, logger.LevelName(e.Level), e.Message)
+//	}
+//	return r.Err()
+//
+// Lines that cannot be parsed, such as the indented error chains below an
+// entry, are skipped and counted (see Skipped).
+package reader
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Options configures which files are read and how
+type Options struct {
+	Archives      bool                 // Also read the rotated archives, oldest first, before the log file
+	ArchiveNaming logger.ArchiveNaming // Config.ArchiveNaming the log was written with
+	TimeFormat    string               // Config.TimeFormat the log was written with, if set
+	TimeLocation  *time.Location       // Time zone of timestamps without an offset (default: time.Local)
+	Key           []byte               // Config.EncryptionKey of an encrypted log
+
+	Filter Filter // Entries to return (default: all)
+}
+
+// Filter selects entries. Every set condition must match.
+type Filter struct {
+	Since    time.Time         // Entries at or after this time
+	Until    time.Time         // Entries before this time
+	Levels   []int             // Entries at these levels, e.g. logger.LevelsFrom(logger.WARN)
+	Contains string            // Entries whose message contains this text
+	Match    *regexp.Regexp    // Entries whose message matches this expression
+	Fields   map[string]string // Entries with these field values, compared as text
+}
+
+// Matches reports whether an entry passes the filter
+func (f *Filter) Matches(e *logger.Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.Levels != nil && !containsLevel(f.Levels, e.Level) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(e.Message, f.Contains) {
+		return false
+	}
+	if f.Match != nil && !f.Match.MatchString(e.Message) {
+		return false
+	}
+	for key, want := range f.Fields {
+		if v, ok := fieldValue(e, key); !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// containsLevel reports whether levels holds level
+func containsLevel(levels []int, level int) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue returns the value of the last field of e with the given key
+func fieldValue(e *logger.Entry, key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Reader iterates the entries of a set of log files
+type Reader struct {
+	opts    Options
+	files   []string
+	file    *os.File
+	src     io.ReadCloser
+	lines   *bufio.Reader
+	entry   logger.Entry
+	skipped int
+	err     error
+}
+
+// Open reads the log file at logPath and, with Options.Archives, its
+// archives before it. A missing log file is not an error when archives are
+// read, as the writer may have just rotated it away.
+func Open(logPath string, opts Options) (*Reader, error) {
+	var files []string
+	if opts.Archives {
+		archives, err := logger.Archives(logPath, opts.ArchiveNaming)
+		if err != nil {
+			return nil, err
+		}
+		files = archives
+	}
+	if _, err := os.Stat(logPath); err == nil || !opts.Archives {
+		files = append(files, logPath)
+	}
+	return OpenFiles(files, opts), nil
+}
+
+// OpenFiles reads the given files in order. Files ending in .gz are
+// decompressed. Options.Archives and ArchiveNaming are ignored.
+func OpenFiles(paths []string, opts Options) *Reader {
+	return &Reader{opts: opts, files: paths}
+}
+
+// Next advances to the next entry matching the filter, reporting false at
+// the end of the last file or on an error
+func (r *Reader) Next() bool {
+	for r.err == nil {
+		if r.lines == nil && !r.openNext() {
+			return false
+		}
+		line, err := r.lines.ReadBytes('\n')
+		if len(line) > 0 {
+			entry, perr := ParseLine(line, r.opts)
+			if perr != nil {
+				r.skipped++
+			} else if r.opts.Filter.Matches(&entry) {
+				r.entry = entry
+				return true
+			}
+		}
+		if err == io.EOF {
+			r.closeFile()
+		} else if err != nil {
+			r.err = fmt.Errorf("failed to read %s: %v", r.file.Name(), err)
+		}
+	}
+	return false
+}
+
+// Entry returns the entry found by the last call to Next
+func (r *Reader) Entry() logger.Entry {
+	return r.entry
+}
+
+// Err returns the error that stopped Next, if any
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Skipped returns the number of lines read that were not entries
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// Close closes the file being read
+func (r *Reader) Close() error {
+	r.closeFile()
+	r.files = nil
+	return nil
+}
+
+// openNext opens the next file, skipping archives rotated before
+// Filter.Since, and reports false when none is left or it failed
+func (r *Reader) openNext() bool {
+	for len(r.files) > 0 {
+		path := r.files[0]
+		r.files = r.files[1:]
+
+		file, err := os.Open(path)
+		if err != nil {
+			r.err = fmt.Errorf("failed to open log file: %v", err)
+			return false
+		}
+		// An archive was last written when it was rotated, so an older one holds
+		// only older entries; the live file is always read
+		if since := r.opts.Filter.Since; !since.IsZero() && len(r.files) > 0 {
+			if info, err := file.Stat(); err == nil && info.ModTime().Before(since) {
+				file.Close()
+				continue
+			}
+		}
+
+		src, err := r.decode(file)
+		if err != nil {
+			file.Close()
+			r.err = fmt.Errorf("failed to read %s: %v", path, err)
+			return false
+		}
+		r.file, r.src = file, src
+		r.lines = bufio.NewReaderSize(src, 64*1024)
+		return true
+	}
+	return false
+}
+
+// decode wraps a file in decompression and decryption as needed
+func (r *Reader) decode(file *os.File) (io.ReadCloser, error) {
+	var src io.Reader = file
+	if strings.HasSuffix(file.Name(), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		src = gz
+	}
+	if r.opts.Key == nil {
+		return io.NopCloser(src), nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(logger.Decrypt(src, pw, r.opts.Key))
+	}()
+	return pr, nil
+}
+
+// closeFile closes the file being read
+func (r *Reader) closeFile() {
+	if r.src != nil {
+		r.src.Close()
+		r.src = nil
+	}
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	r.lines = nil
+}
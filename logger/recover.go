@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// panicStackDepth is the number of frames recorded for a recovered panic
+const panicStackDepth = 64
+
+// RecoverAndLog logs a panic in progress at PANIC level with the stack of the
+// panicking goroutine, writes every queued entry and syncs the file, then
+// panics again with the same value, so the program still crashes as it would
+// have but the entries leading up to the crash are not lost. Defer it at the
+// top of main and of long-running goroutines:
+//
+//	func main() {
+//	    defer logger.RecoverAndLog()
+//	    ...
+//	}
+func (l *Logger) RecoverAndLog() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+		panic(r)
+	}
+}
+
+// RecoverAndLog is RecoverAndLog for the default logger
+func RecoverAndLog() {
+	if r := recover(); r != nil {
+		defaultLogger.logPanic(r)
+		panic(r)
+	}
+}
+
+// HandlePanics runs fn with RecoverAndLog deferred, for starting goroutines
+// whose panics should be logged before the program crashes:
+//
+//	go l.HandlePanics(worker)
+func (l *Logger) HandlePanics(fn func()) {
+	defer l.RecoverAndLog()
+	fn()
+}
+
+// HandlePanics runs fn with the default logger's RecoverAndLog deferred
+func HandlePanics(fn func()) {
+	defer RecoverAndLog()
+	fn()
+}
+
+// logPanic logs a recovered panic value with its stack trace and flushes the
+// logger. The PANIC level makes enqueue wait for the write.
+func (l *Logger) logPanic(r interface{}) {
+	if l == nil {
+		return
+	}
+
+	pc, file, line := panicSite()
+	var fields []Field
+	if l.stacktracer == nil || PANIC < l.stacktracer.level {
+		// An automatic stack trace would be added by dispatch otherwise
+		tracer := stacktracer{depth: panicStackDepth}
+		if stack := tracer.capture(file, line); stack != "" {
+			fields = []Field{{Key: "stack", Value: stack}}
+		}
+	}
+	if l.callerOff {
+		pc, file, line = 0, "", 0
+	}
+
+	l.dispatch(PANIC, flagPriority, fmt.Sprintf("panic: %v", r), fields, pc, file, line)
+}
+
+// panicSite returns the frame that panicked, seen from a function deferred
+// during the panic. Frames of the runtime (e.g. a nil dereference) and of the
+// logger itself (Panic) are skipped.
+func panicSite() (uintptr, string, int) {
+	var pcs [panicStackDepth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if !panicking {
+			panicking = frame.Function == "runtime.gopanic"
+		} else if !strings.HasPrefix(frame.Function, "runtime.") &&
+			!strings.HasPrefix(frame.Function, "github.com/jbarasa/logger/logger.") {
+			return frame.PC, frame.File, frame.Line
+		}
+		if !more {
+			return 0, "", 0
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in PII patterns for use in Config.PIIPatterns
+const (
+	// PIIEmail matches email addresses
+	PIIEmail = `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`
+	// PIICreditCard matches Visa, Mastercard, Amex and Discover numbers, with optional space or dash separators
+	PIICreditCard = `\b(?:4\d{3}|5[1-5]\d{2}|2[2-7]\d{2}|3[47]\d{2}|6011|65\d{2})[ -]?\d{4}[ -]?\d{4}[ -]?\d{1,7}\b`
+	// PIISSN matches US social security numbers in 123-45-6789 form
+	PIISSN = `\b\d{3}-\d{2}-\d{4}\b`
+)
+
+// SensitiveKeys are common names of fields holding secrets, for use in Config.RedactKeys
+var SensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"api_key", "apikey", "authorization", "cookie", "private_key", "card_number", "cvv",
+}
+
+// defaultPIIMask replaces PII matches when no mask is configured
+const defaultPIIMask = "[REDACTED]"
+
+// compilePII compiles the configured PII patterns
+func compilePII(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII pattern %q: %v", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// compileRedactKeys builds the lookup set for redacted field names and a
+// pattern matching them as key=value, key: value or "key":"value" in messages
+func compileRedactKeys(keys []string) (map[string]bool, *regexp.Regexp) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	set := make(map[string]bool, len(keys))
+	quoted := make([]string, 0, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+	re := regexp.MustCompile(`(?i)(\b(?:` + strings.Join(quoted, "|") + `)"?\s*[=:]\s*)("[^"]*"|[^\s,;&]+)`)
+	return set, re
+}
+
+// redacting reports whether any redaction is configured
+func (l *Logger) redacting() bool {
+	return len(l.piiPatterns) > 0 || l.redactKeysRe != nil
+}
+
+// redactMessage masks PII and values of redacted keys in a rendered message
+func (l *Logger) redactMessage(msg []byte) []byte {
+	for _, re := range l.piiPatterns {
+		msg = re.ReplaceAllLiteral(msg, l.piiMask)
+	}
+	if l.redactKeysRe != nil {
+		// Keep the key, separator and any quotes; mask only the value
+		msg = l.redactKeysRe.ReplaceAllFunc(msg, func(m []byte) []byte {
+			sm := l.redactKeysRe.FindSubmatch(m)
+			out := append([]byte(nil), sm[1]...)
+			if bytes.HasPrefix(sm[2], []byte{'"'}) {
+				return append(append(append(out, '"'), l.piiMask...), '"')
+			}
+			return append(out, l.piiMask...)
+		})
+	}
+	return msg
+}
+
+// redactFields masks the values of redacted keys and PII in string field
+// values, copying the slice only if a value changes so caller-owned fields
+// are never modified
+func (l *Logger) redactFields(fields []Field) []Field {
+	copied := false
+	for i, f := range fields {
+		if l.redactKeys[strings.ToLower(f.Key)] {
+			if !copied {
+				fields = append([]Field(nil), fields...)
+				copied = true
+			}
+			fields[i].Value = string(l.piiMask)
+			continue
+		}
+
+		s, ok := f.Value.(string)
+		if !ok {
+			continue
+		}
+		masked := s
+		for _, re := range l.piiPatterns {
+			masked = re.ReplaceAllLiteralString(masked, string(l.piiMask))
+		}
+		if masked == s {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		fields[i].Value = masked
+	}
+	return fields
+}
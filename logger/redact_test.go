@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPIIPatternsMaskEmailAndCard(t *testing.T) {
+	l := newTestLogger(t, Config{PIIPatterns: []string{PIIEmail, PIICreditCard}})
+	l.Info("receipt for jane.doe+shop@example.com paid with 4111 1111 1111 1111")
+	l.InfoKV("card updated", Fields{"card": "5500-0000-0000-0004", "contact": "ops@example.org"})
+
+	lines := readLines(t, l)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %v", len(lines), lines)
+	}
+	for _, leak := range []string{"jane.doe", "example.com", "4111", "5500", "ops@"} {
+		for _, line := range lines {
+			if strings.Contains(line, leak) {
+				t.Errorf("line %q leaks %q", line, leak)
+			}
+		}
+	}
+	if n := strings.Count(lines[0], "[REDACTED]"); n != 2 {
+		t.Errorf("message %q has %d masks, want 2", lines[0], n)
+	}
+	if n := strings.Count(lines[1], "[REDACTED]"); n != 2 {
+		t.Errorf("fields %q have %d masks, want 2", lines[1], n)
+	}
+}
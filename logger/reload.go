@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// reloadFields selects the settings a reload replaces
+type reloadFields uint8
+
+const (
+	reloadLevel reloadFields = 1 << iota
+	reloadNamedLevels
+	reloadMaxFileSize
+	reloadFileLevels
+	reloadConsoleLevels
+
+	reloadAll = reloadLevel | reloadNamedLevels | reloadMaxFileSize | reloadFileLevels | reloadConsoleLevels
+)
+
+// Reload applies the settings of config that can change while the logger is
+// running: Level, NamedLevels, MaxFileSize, FileLevels, ConsoleLevels, and
+// Sinks and Routes when config lists any. Each of the listed fields replaces
+// the current setting, zero values included: a zero Level is DEBUG and
+// a zero MaxFileSize means 25MB. Other fields are ignored. Sinks are swapped
+// between batches on the writer goroutine, so queued entries are not lost,
+// and the replaced sinks are closed.
+func (l *Logger) Reload(config Config) error {
+	return l.reload(config, reloadAll)
+}
+
+// reload applies the fields of config selected by set, and Sinks and Routes
+// when config lists any
+func (l *Logger) reload(config Config, set reloadFields) error {
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = 25 * 1024 * 1024 // 25MB default
+	}
+
+	var routes []route
+	for _, r := range config.Routes {
+		routes = append(routes, route{levels: levelSet(r.Levels), sink: r.Sink})
+	}
+
+	var oldSinks []Sink
+	applied := l.inWriter(func() {
+		if set&reloadFileLevels != 0 {
+			l.fileLevels = levelSet(config.FileLevels)
+		}
+		if set&reloadConsoleLevels != 0 {
+			l.consoleLevels = levelSet(config.ConsoleLevels)
+		}
+		if config.Sinks != nil {
+			oldSinks = append(oldSinks, l.sinks...)
+			l.sinks = config.Sinks
+		}
+		if config.Routes != nil {
+			for _, r := range l.routes {
+				oldSinks = append(oldSinks, r.sink)
+			}
+			l.routes = routes
+		}
+	})
+	if !applied {
+		return fmt.Errorf("failed to reload: logger is closed")
+	}
+
+	if set&reloadMaxFileSize != 0 {
+		l.mu.Lock()
+		l.maxSize = config.MaxFileSize
+		l.mu.Unlock()
+	}
+
+	if set&reloadLevel != 0 {
+		l.level.Store(int64(config.Level))
+	}
+	if set&reloadNamedLevels != 0 {
+		l.resetNamedLevels()
+		for name, level := range config.NamedLevels {
+			l.SetNamedLevel(name, level)
+		}
+	}
+
+	var firstErr error
+	for _, sink := range oldSinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close replaced sink: %v", err)
+		}
+	}
+	return firstErr
+}
+
+// Reload applies config to the default logger
+func Reload(config Config) error {
+	if defaultLogger != nil {
+		return defaultLogger.Reload(config)
+	}
+	return nil
+}
+
+// ReloadFromFile reads a configuration file (see InitializeFromFile) and
+// applies it like Reload, except that settings the file leaves out keep
+// their current values
+func (l *Logger) ReloadFromFile(path string) error {
+	fc, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	config, err := fc.Config()
+	if err != nil {
+		return err
+	}
+	var set reloadFields
+	if fc.Level != "" {
+		set |= reloadLevel
+	}
+	if fc.NamedLevels != nil {
+		set |= reloadNamedLevels
+	}
+	if fc.MaxFileSize != 0 {
+		set |= reloadMaxFileSize
+	}
+	if fc.ConsoleLevel != "" {
+		set |= reloadConsoleLevels
+	}
+	if err := l.reload(config, set); err != nil {
+		closeConfigSinks(config)
+		return err
+	}
+	return nil
+}
+
+// ReloadFromFile applies a configuration file to the default logger
+func ReloadFromFile(path string) error {
+	if defaultLogger != nil {
+		return defaultLogger.ReloadFromFile(path)
+	}
+	return nil
+}
+
+// WatchConfig reloads the configuration file at path whenever it changes
+// (checked every interval, default 2s) and, on unix, when the process receives
+// SIGHUP. Failed reloads are reported to the error hooks and leave the current
+// settings in place. Watching stops when the logger is closed.
+func (l *Logger) WatchConfig(path string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %v", err)
+	}
+	modTime, size := info.ModTime(), info.Size()
+
+	sigCh, stop := reloadSignal()
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+					continue
+				}
+				modTime, size = info.ModTime(), info.Size()
+			case <-sigCh:
+			case <-l.done:
+				return
+			}
+			if err := l.ReloadFromFile(path); err != nil {
+				l.reportError(OpReload, err, "Error reloading log configuration: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// WatchConfig watches a configuration file for the default logger
+func WatchConfig(path string, interval time.Duration) error {
+	if defaultLogger != nil {
+		return defaultLogger.WatchConfig(path, interval)
+	}
+	return nil
+}
+
+// inWriter runs fn on the writer goroutine between batches, after the entries
+// it has already collected are written. It returns false once the logger is closed.
+func (l *Logger) inWriter(fn func()) bool {
+	done := make(chan struct{})
+	req := func() {
+		fn()
+		close(done)
+	}
+	select {
+	case l.reloadReq <- req:
+		<-done
+		return true
+	case <-l.done:
+		return false
+	}
+}
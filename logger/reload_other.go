@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// reloadSignal returns a channel that never fires on platforms without SIGHUP
+func reloadSignal() (<-chan os.Signal, func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadFromFileKeepsOmittedSettings(t *testing.T) {
+	l := newTestLogger(t, Config{Level: WARN, MaxFileSize: 1 << 20})
+	l.SetNamedLevel("db", ERROR)
+
+	path := filepath.Join(t.TempDir(), "logging.json")
+	if err := os.WriteFile(path, []byte(`{"console_level": "error"}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if err := l.ReloadFromFile(path); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if got := l.GetLevel(); got != WARN {
+		t.Errorf("level = %d after a reload without one, want %d", got, WARN)
+	}
+	if got := l.namedLevel("db").level.Load(); got != ERROR {
+		t.Errorf("named level of db = %d after a reload without named levels, want %d", got, ERROR)
+	}
+	l.mu.Lock()
+	maxSize := l.maxSize
+	l.mu.Unlock()
+	if maxSize != 1<<20 {
+		t.Errorf("max file size = %d after a reload without one, want %d", maxSize, 1<<20)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"level": "debug", "max_file_size": "2MB"}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if err := l.ReloadFromFile(path); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if got := l.GetLevel(); got != DEBUG {
+		t.Errorf("level = %d after reloading debug, want %d", got, DEBUG)
+	}
+	l.mu.Lock()
+	maxSize = l.maxSize
+	l.mu.Unlock()
+	if maxSize != 2<<20 {
+		t.Errorf("max file size = %d after reloading 2MB, want %d", maxSize, 2<<20)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reloadSignal subscribes to SIGHUP, returning the channel and a function to unsubscribe
+func reloadSignal() (<-chan os.Signal, func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	return sigCh, func() { signal.Stop(sigCh) }
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Reopen closes the log file and opens LogPath again. Call it after an
+// external tool such as logrotate has moved the file away, so entries go to
+// the new file instead of the renamed one; Config.ReopenOnSignal and
+// Config.ReopenCheck do this automatically. It does nothing in StdoutOnly
+// mode or after Close.
+func (l *Logger) Reopen() error {
+	if l.console {
+		return nil
+	}
+	if l.closed.Load() {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reopenLocked()
+}
+
+// Reopen reopens the log file of the default logger
+func Reopen() error {
+	if defaultLogger == nil {
+		return nil
+	}
+	return defaultLogger.Reopen()
+}
+
+// reopenLocked swaps the file handle for a fresh one of LogPath. The new file
+// is opened before the old one is let go, so a failure leaves the logger
+// writing where it was. The caller must hold l.mu.
+func (l *Logger) reopenLocked() error {
+	// Buffered lines belong to the old file. Writing them must not rotate,
+	// since LogPath may already name a file an external tool created.
+	l.drainLocked()
+
+	// Under a sync policy, what reached the old file must be durable too
+	if l.syncPolicy != SyncNever {
+		if err := l.syncLocked(); err != nil {
+			l.reportError(OpWrite, err, "Error syncing log file: %v", err)
+		}
+	}
+
+	file, err := os.OpenFile(l.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to get file info: %v", err)
+	}
+
+	if err := l.trimLocked(); err != nil {
+		l.reportError(OpReopen, err, "Error trimming log file: %v", err)
+	}
+	l.file.Close()
+
+	l.file = file
+	l.currSize = info.Size()
+	l.unsynced = 0
+	return nil
+}
+
+// reopenIfMoved reopens the log file when LogPath no longer names it (moved
+// or deleted), and adopts the smaller size when it was truncated in place
+// (logrotate's copytruncate)
+func (l *Logger) reopenIfMoved() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %v", err)
+	}
+	onDisk, err := os.Stat(l.logPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to get file info: %v", err)
+	}
+	if err != nil || !os.SameFile(current, onDisk) {
+		return l.reopenLocked()
+	}
+	if size := current.Size(); size < l.currSize {
+		l.currSize = size
+	}
+	return nil
+}
+
+// watchReopen reopens the log file on SIGHUP (onSignal, unix only) and when
+// a check every interval (if positive) finds it was moved or truncated
+func (l *Logger) watchReopen(interval time.Duration, onSignal bool) {
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+	var sigCh <-chan os.Signal
+	stop := func() {}
+	if onSignal {
+		sigCh, stop = reloadSignal()
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer stop()
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+
+		for {
+			select {
+			case <-sigCh:
+				if err := l.Reopen(); err != nil {
+					l.reportError(OpReopen, err, "Error reopening log file: %v", err)
+				}
+			case <-tick:
+				if err := l.reopenIfMoved(); err != nil {
+					l.reportError(OpReopen, err, "Error reopening log file: %v", err)
+				}
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReopenWritesBufferToMovedFile(t *testing.T) {
+	l := newTestLogger(t, Config{WriteBufferSize: 1 << 20, MaxFileSize: 1024})
+
+	// Lines still collected past MaxFileSize when logrotate moves the file
+	line := strings.Repeat("x", 2000) + "\n"
+	l.mu.Lock()
+	l.writeBuf = append(l.writeBuf, line...)
+	l.mu.Unlock()
+
+	moved := l.logPath + ".1"
+	if err := os.Rename(l.logPath, moved); err != nil {
+		t.Fatalf("failed to move log file: %v", err)
+	}
+	if err := l.Reopen(); err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+
+	data, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatalf("failed to read moved file: %v", err)
+	}
+	if string(data) != line {
+		t.Errorf("moved file holds %d bytes, want the %d buffered", len(data), len(line))
+	}
+	info, err := os.Stat(l.logPath)
+	if err != nil {
+		t.Fatalf("reopened log file is missing: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("reopened log file holds %d bytes, want 0", info.Size())
+	}
+	if archives, _ := filepath.Glob(filepath.Join(filepath.Dir(l.logPath), "archive", "*")); len(archives) > 0 {
+		t.Errorf("reopening rotated the new file: %v", archives)
+	}
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// retentionInterval is how often archives are checked against the retention limits
+const retentionInterval = time.Hour
+
+// watchRetention enforces MaxAge, MaxBackups and MaxTotalSize at startup, after every
+// rotation and once per retentionInterval
+func (l *Logger) watchRetention() {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+
+		l.cleanupArchives()
+		for {
+			select {
+			case <-ticker.C:
+				l.cleanupArchives()
+			case <-l.cleanupReq:
+				l.cleanupArchives()
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
+
+// requestCleanup asks the retention goroutine for a pass without blocking
+func (l *Logger) requestCleanup() {
+	if l.cleanupReq == nil {
+		return
+	}
+	select {
+	case l.cleanupReq <- struct{}{}:
+	default:
+	}
+}
+
+// archiveFile is a rotated log file with the time it was rotated
+type archiveFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// listArchives returns the archives of the log file, newest first, leaving
+// out files of other logs sharing the directory. Bare N.log archives of
+// earlier versions are included while the log has its directory to itself.
+// Numbered archives are dated by modification time, lumberjack archives by
+// their name.
+func (l *Logger) listArchives() ([]archiveFile, error) {
+	dir, base, ext := l.splitLogPath()
+	if l.naming != ArchiveLumberjack {
+		dir = filepath.Join(dir, "archive")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := l.naming != ArchiveLumberjack && l.ownsLegacyArchives()
+
+	var archives []archiveFile
+	for _, e := range entries {
+		// Skip directories and files still being written (e.g. compression output)
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		modTime, ok := time.Time{}, true
+		if l.naming == ArchiveLumberjack {
+			// The directory also holds the live log and possibly unrelated files
+			if modTime, ok = l.lumberjackTime(e.Name(), base, ext); !ok {
+				continue
+			}
+		} else if !isNumberedArchive(e.Name(), base, ext) && !(legacy && isLegacyArchive(e.Name(), ext)) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if l.naming != ArchiveLumberjack {
+			modTime = info.ModTime()
+		}
+		archives = append(archives, archiveFile{filepath.Join(dir, e.Name()), modTime, info.Size()})
+	}
+
+	// Newest first, so the count limit keeps the most recent archives
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].modTime.After(archives[j].modTime)
+	})
+	return archives, nil
+}
+
+// Archives returns the rotated archives of the log file at logPath, oldest
+// first, including compressed ones. naming must be the Config.ArchiveNaming
+// the file was written with. A log that was never rotated has no archives.
+func Archives(logPath string, naming ArchiveNaming) ([]string, error) {
+	l := &Logger{loggerCore: &loggerCore{logPath: logPath, naming: naming}}
+	archives, err := l.listArchives()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read archive directory: %v", err)
+	}
+	paths := make([]string, len(archives))
+	for i, a := range archives {
+		paths[len(archives)-1-i] = a.path
+	}
+	return paths, nil
+}
+
+// cleanupArchives removes archives older than maxAge, all but the newest
+// maxBackups archives, and the oldest archives that take the log file and
+// its archives past maxTotalSize
+func (l *Logger) cleanupArchives() {
+	archives, err := l.listArchives()
+	if err != nil {
+		l.reportError(OpRetention, err, "Error reading archive directory: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	total := l.currSize
+	l.mu.Unlock()
+
+	cutoff := time.Now().Add(-l.maxAge)
+	for i, a := range archives {
+		total += a.size
+		expired := l.maxAge > 0 && a.modTime.Before(cutoff)
+		excess := l.maxBackups > 0 && i >= l.maxBackups
+		oversize := l.maxTotalSize > 0 && total > l.maxTotalSize
+		if !expired && !excess && !oversize {
+			continue
+		}
+		if _, busy := l.busyArchives.Load(a.path); busy {
+			continue
+		}
+		if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+			l.reportError(OpRetention, err, "Error removing old archive: %v", err)
+		}
+	}
+}
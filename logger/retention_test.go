@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionKeepsToOwnArchives(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestLogger(t, Config{LogPath: filepath.Join(dir, "app.log"), MaxBackups: 2, InternalErrorWriter: io.Discard})
+	worker := newTestLogger(t, Config{LogPath: filepath.Join(dir, "app-worker.log"), InternalErrorWriter: io.Discard})
+
+	for i := 0; i < 5; i++ {
+		app.Info("app entry %d", i)
+		worker.Info("worker entry %d", i)
+		if err := app.Rotate(); err != nil {
+			t.Fatalf("failed to rotate app.log: %v", err)
+		}
+		if err := worker.Rotate(); err != nil {
+			t.Fatalf("failed to rotate app-worker.log: %v", err)
+		}
+	}
+	app.cleanupArchives()
+
+	archives, err := Archives(app.logPath, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives of app.log: %v", err)
+	}
+	if len(archives) != 2 {
+		t.Errorf("app.log has %d archives, want 2: %v", len(archives), archives)
+	}
+	for _, a := range archives {
+		if _, ok := archiveNumber(filepath.Base(a), "app", ".log"); !ok {
+			t.Errorf("archive %s of app.log is not one of its own", a)
+		}
+	}
+
+	// MaxBackups of app.log must not touch the archives of app-worker.log
+	archives, err = Archives(worker.logPath, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives of app-worker.log: %v", err)
+	}
+	if len(archives) != 5 {
+		t.Errorf("app-worker.log has %d archives, want 5: %v", len(archives), archives)
+	}
+	for _, a := range archives {
+		if !strings.HasPrefix(filepath.Base(a), "app-worker-") {
+			t.Errorf("archive %s of app-worker.log is not one of its own", a)
+		}
+	}
+}
+
+// writeLegacyArchives creates archive/1.log to archive/3.log as earlier
+// versions named them, each an hour older than the last
+func writeLegacyArchives(t *testing.T, dir string) []string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "archive"), 0755); err != nil {
+		t.Fatalf("failed to create archive directory: %v", err)
+	}
+	var paths []string
+	for i, name := range []string{"3.log", "2.log", "1.log.gz"} {
+		path := filepath.Join(dir, "archive", name)
+		if err := os.WriteFile(path, []byte("old entry\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		old := time.Now().Add(-time.Duration(i+1) * time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("failed to date %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestRetentionCountsLegacyArchives(t *testing.T) {
+	dir := t.TempDir()
+	legacy := writeLegacyArchives(t, dir)
+	l := newTestLogger(t, Config{LogPath: filepath.Join(dir, "app.log"), MaxBackups: 4, InternalErrorWriter: io.Discard})
+
+	archives, err := Archives(l.logPath, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives: %v", err)
+	}
+	if len(archives) != 3 {
+		t.Errorf("got %d archives before rotating, want the 3 of the earlier version: %v", len(archives), archives)
+	}
+
+	for i := 0; i < 2; i++ {
+		l.Info("entry %d", i)
+		if err := l.Rotate(); err != nil {
+			t.Fatalf("failed to rotate: %v", err)
+		}
+	}
+	l.cleanupArchives()
+
+	// The two new archives and the two newest old ones are kept
+	archives, err = Archives(l.logPath, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives: %v", err)
+	}
+	if len(archives) != 4 {
+		t.Errorf("got %d archives, want 4: %v", len(archives), archives)
+	}
+	if _, err := os.Stat(legacy[2]); !os.IsNotExist(err) {
+		t.Errorf("oldest archive %s was not removed", legacy[2])
+	}
+	for _, path := range legacy[:2] {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("archive %s was removed: %v", path, err)
+		}
+	}
+}
+
+func TestLegacyArchivesOfSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	legacy := writeLegacyArchives(t, dir)
+	newTestLogger(t, Config{LogPath: filepath.Join(dir, "worker.log")})
+	l := newTestLogger(t, Config{LogPath: filepath.Join(dir, "app.log"), MaxBackups: 1, InternalErrorWriter: io.Discard})
+
+	// With two logs in the directory, bare archives belong to neither
+	l.Info("entry")
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	l.cleanupArchives()
+
+	archives, err := Archives(l.logPath, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives: %v", err)
+	}
+	if len(archives) != 1 || !strings.HasPrefix(filepath.Base(archives[0]), "app-") {
+		t.Errorf("got archives %v, want only the new one of app.log", archives)
+	}
+	for _, path := range legacy {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("archive %s was removed: %v", path, err)
+		}
+	}
+}
+
+func TestIsNumberedArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"app-1.log", true},
+		{"app-12.log.gz", true},
+		{"app-2024-05-01.log", true},
+		{"app-2024-05-01T15.log", true},
+		{"app-2024-05-01.3.log", true},
+		{"app-worker-1.log", false},
+		{"app-worker-2024-05-01.log", false},
+		{"1.log", false},
+		{"app-.log", false},
+		{"app-1.txt", false},
+		{"other-1.log", false},
+	}
+	for _, tt := range tests {
+		if got := isNumberedArchive(tt.name, "app", ".log"); got != tt.want {
+			t.Errorf("isNumberedArchive(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
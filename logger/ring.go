@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// ringBuffer keeps the most recent entries across all levels for on-demand dumps
+type ringBuffer struct {
+	mu      sync.Mutex
+	entries []logEntry
+	next    int
+	full    bool
+}
+
+// newRingBuffer creates a ring buffer holding up to size entries
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{entries: make([]logEntry, size)}
+}
+
+// add records a copy of an entry, overwriting the oldest one when full
+func (r *ringBuffer) add(level int, msg []byte, fields []Field, pc uintptr, file string, line int, timestamp int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slot := &r.entries[r.next]
+	slot.level = level
+	slot.msg = append(slot.msg[:0], msg...)
+	slot.fields = fields
+	slot.pc = pc
+	slot.file = file
+	slot.line = line
+	slot.timestamp = timestamp
+
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot returns copies of the buffered entries, oldest first
+func (r *ringBuffer) snapshot() []logEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []logEntry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+
+	out := make([]logEntry, len(ordered))
+	for i, e := range ordered {
+		out[i] = e
+		out[i].msg = append([]byte(nil), e.msg...)
+	}
+	return out
+}
+
+// DumpRing writes the in-memory ring buffer of recent entries to the log file.
+// The dump is framed by marker lines so it stands out from regular output.
+func (l *Logger) DumpRing() error {
+	if l.ring == nil {
+		return fmt.Errorf("ring buffer is not enabled")
+	}
+
+	// Write queued entries first so the dump follows them in the file
+	l.flush()
+
+	entries := l.ring.snapshot()
+
+	// Marker lines stay parseable when the file is written as JSON or logfmt
+	begin := fmt.Sprintf("----- BEGIN RING DUMP (%d entries) -----\n", len(entries))
+	end := "----- END RING DUMP -----\n"
+	switch l.format {
+	case JSON:
+		begin = fmt.Sprintf("{\"ring_dump\":\"begin\",\"entries\":%d}\n", len(entries))
+		end = "{\"ring_dump\":\"end\"}\n"
+	case Logfmt:
+		begin = fmt.Sprintf("ring_dump=begin entries=%d\n", len(entries))
+		end = "ring_dump=end\n"
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 64*1024))
+	buf.WriteString(begin)
+	for i := range entries {
+		l.appendEntry(buf, &entries[i], l.formatCaller(&entries[i]))
+	}
+	buf.WriteString(end)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.writeLocked(buf.Bytes())
+	l.flushLocked()
+	return nil
+}
+
+// DumpRing writes the recent in-memory entries to the log file
+func DumpRing() error {
+	if defaultLogger != nil {
+		return defaultLogger.DumpRing()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchDumpSignal dumps the ring buffer to the log file whenever SIGUSR1 arrives
+func (l *Logger) watchDumpSignal() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer signal.Stop(sigCh)
+
+		for {
+			select {
+			case <-sigCh:
+				if err := l.DumpRing(); err != nil {
+					l.reportError(OpDump, err, "Error dumping ring buffer: %v", err)
+				}
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	l := newTestLogger(t, Config{Level: INFO, RingBufferSize: 3, DumpOnSignal: true})
+	l.Debug("cache miss for %s", "user:1")
+	l.Debug("cache miss for %s", "user:2")
+	l.Info("request served")
+	l.Debug("cache miss for %s", "user:3")
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to send SIGUSR1: %v", err)
+	}
+
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for countLines(lines, "END RING DUMP") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ring buffer was not dumped on SIGUSR1:\n%s", strings.Join(lines, "\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+		lines = readLines(t, l)
+	}
+
+	// The dump holds the last three entries, DEBUG ones included
+	var dump []string
+	for i, line := range lines {
+		if strings.Contains(line, "BEGIN RING DUMP (3 entries)") {
+			dump = lines[i+1:]
+			break
+		}
+	}
+	if len(dump) != 4 {
+		t.Fatalf("got dump %v, want three entries and the end marker", dump)
+	}
+	for i, want := range []string{"user:2", "request served", "user:3"} {
+		if !strings.Contains(dump[i], want) {
+			t.Errorf("dump entry %d = %q, want %q", i, dump[i], want)
+		}
+	}
+	if countLines(lines, "user:1") != 0 {
+		t.Errorf("entry pushed out of the ring or filtered by level reached the file:\n%s", strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rotation selects a time-based rotation policy
+type Rotation int
+
+// Time-based rotation policies
+const (
+	RotateNone   Rotation = iota // Rotate on size only
+	RotateHourly                 // Rotate at the start of every hour
+	RotateDaily                  // Rotate at midnight (local time)
+)
+
+// periodStart returns the start of the rotation period containing t
+func (r Rotation) periodStart(t time.Time) time.Time {
+	switch r {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// next returns the start of the period following the one starting at start
+func (r Rotation) next(start time.Time) time.Time {
+	if r == RotateHourly {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// label names a period in archive file names
+func (r Rotation) label(start time.Time) string {
+	if r == RotateHourly {
+		return start.Format("2006-01-02T15")
+	}
+	return start.Format("2006-01-02")
+}
+
+// rotateIfDue rotates the file when the entry time has crossed into a new
+// rotation period. The caller must hold l.mu.
+func (l *Logger) rotateIfDue(timestamp int64) {
+	if l.rotateEvery == RotateNone || timestamp < l.nextRotation.UnixNano() {
+		return
+	}
+	l.flushLocked()
+	if l.currSize > 0 {
+		if err := l.rotate(); err != nil {
+			l.reportError(OpRotate, err, "Error rotating log file: %v", err)
+			return
+		}
+	}
+	l.startPeriod(time.Unix(0, timestamp))
+}
+
+// Rotate writes the entries logged so far, then moves the log file to the
+// archive and starts a new one, as if it had reached MaxFileSize. An empty
+// file is not rotated. It does nothing in StdoutOnly mode or after Close.
+func (l *Logger) Rotate() error {
+	if l.console {
+		return nil
+	}
+	l.flush()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed.Load() {
+		return nil
+	}
+	l.flushLocked()
+	if l.currSize == 0 {
+		return nil
+	}
+	return l.rotate()
+}
+
+// Rotate rotates the log file of the default logger
+func Rotate() error {
+	if defaultLogger == nil {
+		return nil
+	}
+	return defaultLogger.Rotate()
+}
+
+// startPeriod records the rotation period the current file belongs to
+func (l *Logger) startPeriod(t time.Time) {
+	l.period = l.rotateEvery.periodStart(t)
+	l.nextRotation = l.rotateEvery.next(l.period)
+}
+
+// ArchiveNaming selects how rotated files are named
+type ArchiveNaming int
+
+// Archive naming schemes
+const (
+	ArchiveNumbered   ArchiveNaming = iota // archive/app-1.log, archive/app-2.log, ..., or archive/app-2024-05-01.log with RotateEvery (default)
+	ArchiveLumberjack                      // app-2024-05-01T12-00-00.000.log beside the log file, as lumberjack names backups
+)
+
+// lumberjackTimeFormat is the rotation time in lumberjack backup names
+const lumberjackTimeFormat = "2006-01-02T15-04-05.000"
+
+// archiveLocation is the time zone of lumberjack archive names: UTC, as in
+// lumberjack, unless Config.TimeLocation is set
+func (l *Logger) archiveLocation() *time.Location {
+	if l.timeLocation != nil {
+		return l.timeLocation
+	}
+	return time.UTC
+}
+
+// splitLogPath returns the directory, base name and extension of the log
+// file, e.g. "logs", "app" and ".log"
+func (l *Logger) splitLogPath() (string, string, string) {
+	ext := filepath.Ext(l.logPath)
+	base := strings.TrimSuffix(filepath.Base(l.logPath), ext)
+	if ext == "" {
+		ext = ".log"
+	}
+	return filepath.Dir(l.logPath), base, ext
+}
+
+// claimLumberjackPath reserves an archive name carrying the rotation time,
+// e.g. logs/app-2024-05-01T12-00-00.000.log. A name taken by another process
+// in the same millisecond moves on to the next millisecond.
+func (l *Logger) claimLumberjackPath() (string, error) {
+	dir, base, ext := l.splitLogPath()
+	now := time.Now().In(l.archiveLocation())
+
+	for i := 0; i < maxArchiveClaimAttempts; i++ {
+		t := now.Add(time.Duration(i) * time.Millisecond)
+		archivePath := filepath.Join(dir, base+"-"+t.Format(lumberjackTimeFormat)+ext)
+		claimed, err := claimArchiveFile(archivePath)
+		if err != nil {
+			return "", err
+		}
+		if claimed {
+			return archivePath, nil
+		}
+	}
+	return "", fmt.Errorf("failed to claim archive name after %d attempts", maxArchiveClaimAttempts)
+}
+
+// lumberjackTime parses the rotation time out of a lumberjack archive name,
+// reporting false for other files in the directory
+func (l *Logger) lumberjackTime(name, base, ext string) (time.Time, bool) {
+	name = strings.TrimSuffix(name, compressedExt)
+	if !strings.HasPrefix(name, base+"-") || !strings.HasSuffix(name, ext) {
+		return time.Time{}, false
+	}
+	ts := name[len(base)+1 : len(name)-len(ext)]
+	t, err := time.ParseInLocation(lumberjackTimeFormat, ts, l.archiveLocation())
+	return t, err == nil
+}
+
+// archiveLabel returns what follows "<base>-" in the name of an archive of
+// the log file, without the extension and compression suffix
+func archiveLabel(name, base, ext string) (string, bool) {
+	name = strings.TrimSuffix(name, compressedExt)
+	if len(name) <= len(base)+1+len(ext) || !strings.HasPrefix(name, base+"-") || !strings.HasSuffix(name, ext) {
+		return "", false
+	}
+	return name[len(base)+1 : len(name)-len(ext)], true
+}
+
+// archiveNumber returns the number of a numbered archive, e.g. 3 for
+// app-3.log or app-3.log.gz
+func archiveNumber(name, base, ext string) (int, bool) {
+	label, ok := archiveLabel(name, base, ext)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(label)
+	return n, err == nil && n > 0
+}
+
+// isNumberedArchive reports whether name is a numbered or dated archive of
+// the log file. Other loggers may rotate into the same archive directory, and
+// a base such as "app-worker" must not pass for an archive of "app".
+func isNumberedArchive(name, base, ext string) bool {
+	if _, ok := archiveNumber(name, base, ext); ok {
+		return true
+	}
+	label, ok := archiveLabel(name, base, ext)
+	if !ok {
+		return false
+	}
+	// Size rotations within a period add a counter: 2024-05-01.1
+	if i := strings.IndexByte(label, '.'); i >= 0 {
+		if _, err := strconv.Atoi(label[i+1:]); err != nil {
+			return false
+		}
+		label = label[:i]
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15"} {
+		if _, err := time.Parse(layout, label); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isLegacyArchive reports whether name is a bare numbered archive, e.g.
+// 3.log or 3.log.gz, as written before archives were named after their log
+func isLegacyArchive(name, ext string) bool {
+	name = strings.TrimSuffix(name, compressedExt)
+	if !strings.HasSuffix(name, ext) {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(name, ext))
+	return err == nil && n > 0
+}
+
+// ownsLegacyArchives reports whether the bare numbered archives in the
+// archive directory belong to this log. Their names do not say which log
+// they came from, so they are only claimed when no other log with the same
+// extension shares the directory.
+func (l *Logger) ownsLegacyArchives() bool {
+	dir, _, ext := l.splitLogPath()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	own := filepath.Base(l.logPath)
+	for _, e := range entries {
+		if !e.IsDir() && e.Name() != own && strings.HasSuffix(e.Name(), ext) {
+			return false
+		}
+	}
+	return true
+}
+
+// claimDatedArchivePath reserves an archive name carrying the current period,
+// e.g. archive/app-2024-05-01.log. Further rotations in the same period (size
+// limit reached, restarts) get a counter: app-2024-05-01.1.log.
+func (l *Logger) claimDatedArchivePath() (string, error) {
+	dir, base, ext := l.splitLogPath()
+	prefix := filepath.Join(dir, "archive", base+"-"+l.rotateEvery.label(l.period))
+
+	for i := 0; i < maxArchiveClaimAttempts; i++ {
+		archivePath := prefix + ext
+		if i > 0 {
+			archivePath = fmt.Sprintf("%s.%d%s", prefix, i, ext)
+		}
+		claimed, err := claimArchiveFile(archivePath)
+		if err != nil {
+			return "", err
+		}
+		if claimed {
+			return archivePath, nil
+		}
+	}
+	return "", fmt.Errorf("failed to claim archive name after %d attempts", maxArchiveClaimAttempts)
+}
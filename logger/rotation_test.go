@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Two processes logging to one file share its archive directory
+func TestSharedArchiveDirLosesNoArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	a := newTestLogger(t, Config{LogPath: path, InternalErrorWriter: io.Discard})
+	b := newTestLogger(t, Config{LogPath: path, InternalErrorWriter: io.Discard})
+
+	for i := 0; i < 5; i++ {
+		a.Info("entry %d of process a", i)
+		if err := a.Rotate(); err != nil {
+			t.Fatalf("failed to rotate process a: %v", err)
+		}
+		b.Info("entry %d of process b", i)
+		if err := b.Rotate(); err != nil {
+			t.Fatalf("failed to rotate process b: %v", err)
+		}
+	}
+	a.Flush()
+	b.Flush()
+
+	archives, err := Archives(path, ArchiveNumbered)
+	if err != nil {
+		t.Fatalf("failed to list archives: %v", err)
+	}
+	if len(archives) != 10 {
+		t.Errorf("got %d archives after 10 rotations, want 10: %v", len(archives), archives)
+	}
+	var lines []string
+	for _, archive := range archives {
+		lines = append(lines, fileLines(t, archive)...)
+	}
+	lines = append(lines, fileLines(t, path)...)
+	if n := countLines(lines, "of process a"); n != 5 {
+		t.Errorf("found %d entries of process a, want 5", n)
+	}
+	if n := countLines(lines, "of process b"); n != 5 {
+		t.Errorf("found %d entries of process b, want 5", n)
+	}
+}
+
+func TestConcurrentArchiveClaimsAreUnique(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	loggers := []*Logger{
+		newTestLogger(t, Config{LogPath: path}),
+		newTestLogger(t, Config{LogPath: path}),
+	}
+
+	var mu sync.Mutex
+	claimed := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, l := range loggers {
+		wg.Add(1)
+		go func(l *Logger) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				archivePath, err := l.claimArchivePath()
+				if err != nil {
+					t.Errorf("failed to claim archive path: %v", err)
+					return
+				}
+				mu.Lock()
+				if claimed[archivePath] {
+					t.Errorf("archive %s claimed twice", archivePath)
+				}
+				claimed[archivePath] = true
+				mu.Unlock()
+			}
+		}(l)
+	}
+	wg.Wait()
+
+	if len(claimed) != 100 {
+		t.Errorf("got %d archive paths for 100 claims, want 100", len(claimed))
+	}
+}
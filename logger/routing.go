@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Route sends entries at the listed levels to a sink, for example a small
+// error-only file operators can tail without the rest of the traffic
+type Route struct {
+	Levels []int // Levels delivered to Sink (see LevelsFrom)
+	Sink   Sink  // Destination; an EntrySink receives structured entries
+}
+
+// route is a Route with its levels prepared for lookup
+type route struct {
+	levels map[int]bool
+	sink   Sink
+}
+
+// LevelsFrom returns every level at or above min, for Route.Levels and Config.FileLevels
+func LevelsFrom(min int) []int {
+	var levels []int
+	for _, level := range allLevels() {
+		if !levelBelow(level, min) {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// levelSet converts a level list into a lookup set, or nil when the list is nil
+func levelSet(levels []int) map[int]bool {
+	if levels == nil {
+		return nil
+	}
+	set := make(map[int]bool, len(levels))
+	for _, level := range levels {
+		set[level] = true
+	}
+	return set
+}
+
+// selectLines returns the rendered lines of entries whose level is in levels.
+// ends holds the end offset of each entry's line in out.
+func selectLines(out []byte, ends []int, entries []*logEntry, levels map[int]bool) []byte {
+	var selected []byte
+	start, all := 0, true
+	for i, entry := range entries {
+		if levels[entry.level] {
+			selected = append(selected, out[start:ends[i]]...)
+		} else {
+			all = false
+		}
+		start = ends[i]
+	}
+	if all {
+		return out
+	}
+	return selected
+}
+
+// writeRoutes delivers the matching part of a batch to every route
+func (l *Logger) writeRoutes(out []byte, ends []int, entries []*logEntry) {
+	for i, r := range l.routes {
+		if es, ok := r.sink.(EntrySink); ok {
+			var matched []*Entry
+			for _, entry := range entries {
+				if r.levels[entry.level] {
+					matched = append(matched, entry.export())
+				}
+			}
+			if len(matched) > 0 {
+				if err := es.WriteEntries(matched); err != nil {
+					l.reportError(OpSink, err, "Error writing to route %d: %v", i+1, err)
+				}
+			}
+			continue
+		}
+
+		if lines := selectLines(out, ends, entries, r.levels); len(lines) > 0 {
+			if _, err := r.sink.Write(lines); err != nil {
+				l.reportError(OpSink, err, "Error writing to route %d: %v", i+1, err)
+			}
+		}
+	}
+}
+
+// FileSink appends output to a file, moving it to path.1 (replacing any
+// previous one) when it reaches its size limit
+type FileSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	path    string
+	maxSize int64
+	size    int64
+}
+
+// NewFileSink opens path for appending, creating its directory if needed.
+// A maxSize of 0 never rotates.
+func NewFileSink(path string, maxSize int64) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to get file info: %v", err)
+	}
+	return &FileSink{file: file, path: path, maxSize: maxSize, size: info.Size()}, nil
+}
+
+// Write appends p, rotating afterwards if the file has grown too large
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if s.maxSize > 0 && s.size >= s.maxSize {
+		if err := s.rotate(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// rotate moves the file to path.1 and starts a new one. The caller must hold s.mu.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %v", err)
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %v", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create new log file: %v", err)
+	}
+	s.file = file
+	s.size = 0
+	return nil
+}
+
+// Close syncs and closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return fmt.Errorf("failed to sync log file: %v", err)
+	}
+	return s.file.Close()
+}
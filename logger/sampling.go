@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets is the number of counters per level. Messages hashing to
+// the same bucket share a counter, which only makes sampling slightly stricter.
+const samplerBuckets = 4096
+
+// SamplingRule limits how often one message is logged per tick: the first
+// Initial occurrences pass, then every Thereafter-th one. Thereafter 0 drops
+// the rest of the tick. Initial 0 disables sampling.
+type SamplingRule struct {
+	Initial    int
+	Thereafter int
+}
+
+// SamplingConfig configures sampling of repeated messages. Messages are keyed
+// by level and format string (or message for structured calls), so a hot loop
+// logging the same warning is thinned out while distinct messages are not.
+type SamplingConfig struct {
+	Tick       time.Duration        // Window the counts apply to (default: 1s)
+	Initial    int                  // Default rule for every level: first N per tick...
+	Thereafter int                  // ...then every Mth
+	Levels     map[int]SamplingRule // Per-level rules replacing the default (Initial 0 exempts a level)
+}
+
+// sampleCounter counts occurrences of a bucket within the current tick
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// inc counts an occurrence at now and returns the count within the tick
+func (c *sampleCounter) inc(now int64, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+
+	// The tick has passed: the first caller to swap the deadline restarts the count
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// levelSampler holds the rule and counters of one level
+type levelSampler struct {
+	rule     SamplingRule
+	counters [samplerBuckets]sampleCounter
+}
+
+// sampler decides which repeated messages are logged
+type sampler struct {
+	tick   int64
+	levels map[int]*levelSampler
+}
+
+// newSampler builds a sampler, or returns nil when no level is sampled
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+	tick := cfg.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	s := &sampler{tick: int64(tick), levels: make(map[int]*levelSampler)}
+	for _, level := range allLevels() {
+		rule := SamplingRule{Initial: cfg.Initial, Thereafter: cfg.Thereafter}
+		if r, ok := cfg.Levels[level]; ok {
+			rule = r
+		}
+		if rule.Initial > 0 {
+			s.levels[level] = &levelSampler{rule: rule}
+		}
+	}
+	if len(s.levels) == 0 {
+		return nil
+	}
+	return s
+}
+
+// allow reports whether an occurrence of msg at level should be logged
+func (s *sampler) allow(level int, msg string) bool {
+	ls := s.levels[level]
+	if ls == nil {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(msg))
+	n := ls.counters[h.Sum32()%samplerBuckets].inc(time.Now().UnixNano(), s.tick)
+
+	initial := uint64(ls.rule.Initial)
+	if n <= initial {
+		return true
+	}
+	return ls.rule.Thereafter > 0 && (n-initial)%uint64(ls.rule.Thereafter) == 0
+}
+
+// sampled reports whether a non-priority entry is dropped by sampling, counting it if so
+func (l *Logger) sampled(level int, flags entryFlags, msg string) bool {
+	if l.sampler == nil || flags&flagPriority != 0 || l.sampler.allow(level, msg) {
+		return false
+	}
+	l.stats.sampled.Add(1)
+	return true
+}
@@ -0,0 +1,68 @@
+package logger
+
+import "os"
+
+// ServiceMetadata describes the service emitting the logs. Non-empty values are
+// attached to every entry as well-known fields, so they never need repeating
+// at call sites.
+type ServiceMetadata struct {
+	Name        string // Service name, emitted as "service"
+	Version     string // Service version, emitted as "version"
+	Environment string // Deployment environment (e.g. "production"), emitted as "environment"
+	Instance    string // Instance identifier (e.g. pod or host name), emitted as "instance"
+	Host        string // Host name, emitted as "host" (filled in by Config.HostInfo)
+	PID         int    // Process ID, emitted as "pid" (filled in by Config.HostInfo)
+}
+
+// serviceMetadata merges Service with the ServiceName, Environment and
+// HostInfo shorthands; values set in Service take precedence
+func (c Config) serviceMetadata() ServiceMetadata {
+	m := c.Service
+	if m.Name == "" {
+		m.Name = c.ServiceName
+	}
+	if m.Environment == "" {
+		m.Environment = c.Environment
+	}
+	if c.HostInfo {
+		if m.Host == "" {
+			m.Host, _ = os.Hostname()
+		}
+		if m.PID == 0 {
+			m.PID = os.Getpid()
+		}
+	}
+	return m
+}
+
+// jsonFields returns the non-empty metadata values keyed for the nested
+// JSON "service" object
+func (m ServiceMetadata) jsonFields() []Field {
+	return m.collect("name")
+}
+
+// fields returns the non-empty metadata values in a fixed order
+func (m ServiceMetadata) fields() []Field {
+	return m.collect("service")
+}
+
+// collect returns the non-empty metadata values, with the service name under
+// nameKey
+func (m ServiceMetadata) collect(nameKey string) []Field {
+	var fields []Field
+	for _, f := range []Field{
+		{Key: nameKey, Value: m.Name},
+		{Key: "version", Value: m.Version},
+		{Key: "environment", Value: m.Environment},
+		{Key: "instance", Value: m.Instance},
+		{Key: "host", Value: m.Host},
+	} {
+		if f.Value != "" {
+			fields = append(fields, f)
+		}
+	}
+	if m.PID != 0 {
+		fields = append(fields, Field{Key: "pid", Value: m.PID})
+	}
+	return fields
+}
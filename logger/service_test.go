@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestServiceMetadataInTextAndJSON(t *testing.T) {
+	service := ServiceMetadata{Version: "1.4.2", Instance: "pod-7", PID: 42}
+	config := Config{Service: service, ServiceName: "checkout", Environment: "production"}
+
+	text := newTestLogger(t, config)
+	text.Info("order shipped")
+	lines := readLines(t, text)
+	if len(lines) != 1 {
+		t.Fatalf("got %d text lines, want 1: %v", len(lines), lines)
+	}
+	for _, want := range []string{"service=checkout", "version=1.4.2", "environment=production", "instance=pod-7", "pid=42"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("text line %q is missing %s", lines[0], want)
+		}
+	}
+
+	config.Format = JSON
+	js := newTestLogger(t, config)
+	js.Info("order shipped")
+	lines = readLines(t, js)
+	if len(lines) != 1 {
+		t.Fatalf("got %d JSON lines, want 1: %v", len(lines), lines)
+	}
+	var entry struct {
+		Service map[string]interface{} `json:"service"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to parse JSON line %q: %v", lines[0], err)
+	}
+	want := map[string]interface{}{"name": "checkout", "version": "1.4.2", "environment": "production", "instance": "pod-7", "pid": float64(42)}
+	for key, value := range want {
+		if entry.Service[key] != value {
+			t.Errorf("service.%s = %v in %q, want %v", key, entry.Service[key], lines[0], value)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+)
+
+// Default buffer usage watermarks for adaptive load shedding
+const (
+	defaultShedHighWatermark = 0.8
+	defaultShedLowWatermark  = 0.5
+)
+
+// loadShedder drops a growing share of entries while the buffer is under
+// sustained pressure. Shedding starts when usage crosses the high watermark
+// and stops once it falls back to the low watermark; in between the drop
+// rate grows linearly from 0 at the low watermark to 1 at a full buffer.
+type loadShedder struct {
+	high     float64
+	low      float64
+	active   atomic.Bool
+	rateBits atomic.Uint64 // math.Float64bits of the current drop rate
+}
+
+// newLoadShedder creates a shedder with the given watermarks, applying defaults
+func newLoadShedder(high, low float64) *loadShedder {
+	if high <= 0 || high > 1 {
+		high = defaultShedHighWatermark
+	}
+	if low <= 0 || low >= high {
+		low = math.Min(defaultShedLowWatermark, high/2)
+	}
+	return &loadShedder{high: high, low: low}
+}
+
+// shouldDrop updates the shedding state for the current buffer usage and
+// reports whether this entry should be dropped
+func (s *loadShedder) shouldDrop(queued, capacity int) bool {
+	usage := float64(queued) / float64(capacity)
+
+	switch {
+	case !s.active.Load() && usage >= s.high:
+		s.active.Store(true)
+	case s.active.Load() && usage <= s.low:
+		s.active.Store(false)
+	}
+
+	if !s.active.Load() {
+		s.rateBits.Store(0)
+		return false
+	}
+
+	rate := math.Min(math.Max((usage-s.low)/(1-s.low), 0), 1)
+	s.rateBits.Store(math.Float64bits(rate))
+	return rand.Float64() < rate
+}
+
+// rate returns the current drop rate
+func (s *loadShedder) rate() float64 {
+	return math.Float64frombits(s.rateBits.Load())
+}
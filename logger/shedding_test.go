@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestSheddingRisesWithPressure(t *testing.T) {
+	l := newTestLogger(t, Config{
+		BufferSize:          1000,
+		BatchSize:           1,
+		AdaptiveShedding:    true,
+		DropReport:          -1,
+		InternalErrorWriter: io.Discard,
+	})
+
+	// Stall the writer on its first entry so the buffer only fills
+	l.mu.Lock()
+	var rates []float64
+	var shedShares []float64
+	for stage := 0; stage < 20; stage++ {
+		shedBefore := l.Stats().Shed
+		for i := 0; i < 200; i++ {
+			l.Info("flood entry %d.%d", stage, i)
+		}
+		s := l.Stats()
+		rates = append(rates, s.ShedRate)
+		shedShares = append(shedShares, float64(s.Shed-shedBefore)/200)
+	}
+
+	if rates[0] != 0 || shedShares[0] != 0 {
+		t.Errorf("shed %.2f at rate %.2f with the buffer a fifth full, want nothing", shedShares[0], rates[0])
+	}
+	for i := 1; i < len(rates); i++ {
+		if rates[i] < rates[i-1] {
+			t.Errorf("shed rate fell from %.3f to %.3f as the buffer filled", rates[i-1], rates[i])
+		}
+	}
+	if last := rates[len(rates)-1]; last < 0.9 {
+		t.Errorf("shed rate = %.3f with a full buffer, want at least 0.9", last)
+	}
+	if last := shedShares[len(shedShares)-1]; last < 0.9 {
+		t.Errorf("shed %.2f of the entries with a full buffer, want at least 0.9", last)
+	}
+
+	// Priority entries are never shed; they wait for the writer instead
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			l.MustInfo("critical entry %d", i)
+		}
+	}()
+	l.mu.Unlock()
+	wg.Wait()
+
+	lines := readLines(t, l)
+	if n := countLines(lines, "critical entry"); n != 10 {
+		t.Errorf("got %d priority entries, want 10", n)
+	}
+}
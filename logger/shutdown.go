@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ShutdownError is returned when Shutdown or CloseWithTimeout runs out of time.
+// The remaining cleanup (closing the file and sinks) finishes in the background.
+type ShutdownError struct {
+	Abandoned uint64 // Accepted entries not yet written when the deadline passed
+	Err       error  // The context error
+}
+
+// Error implements error
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("logger shutdown did not complete: %d entries abandoned: %v", e.Abandoned, e.Err)
+}
+
+// Unwrap returns the context error, so errors.Is(err, context.DeadlineExceeded) works
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// Close shuts the logger down, writing every accepted entry before returning.
+//
+// The shutdown sequence runs in a fixed order so no stage loses data that an
+// earlier stage is still producing:
+//
+//  1. Stop accepting new entries; later log calls are discarded
+//  2. Signal the writer, which drains the channel and writes what remains
+//  3. Wait for the writer and auxiliary goroutines (signal watcher, retention) to exit
+//  4. Deliver the entries still buffered for subscribers
+//  5. Wait for background archive compression and uploads to finish
+//  6. Sync and close the log file and the spill file
+//  7. Close the sinks, route sinks and fallback sinks
+//
+// Close is safe to call more than once; later calls return nil.
+func (l *Logger) Close() error {
+	return l.Shutdown(context.Background())
+}
+
+// CloseWithTimeout is Close bounded by d (see Shutdown)
+func (l *Logger) CloseWithTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return l.Shutdown(ctx)
+}
+
+// Shutdown runs the Close sequence until ctx is done. If the deadline passes
+// first, the writer stops after its current batch and Shutdown returns a
+// *ShutdownError with the number of entries abandoned, so a slow disk or sink
+// cannot hang service shutdown.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	var err error
+	l.closeOnce.Do(func() {
+		err = l.shutdown(ctx)
+	})
+	return err
+}
+
+// shutdown performs the ordered shutdown sequence described on Close
+func (l *Logger) shutdown(ctx context.Context) error {
+	// 1. Stop accepting new entries. Once the write lock is taken no new
+	// send can start; those in flight finish before the writer is told to stop.
+	l.closeMu.Lock()
+	l.closed.Store(true)
+	l.closeMu.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		l.sending.Wait()
+
+		// 2-3. Drain the channel and wait for every goroutine to finish
+		close(l.done)
+		l.wg.Wait()
+
+		// 4. The writer published its last batch
+		l.closeSubscriptions()
+
+		// 5. No rotation can start any more, so compressions are all accounted
+		// for, and only then can no more uploads be queued
+		l.compressWG.Wait()
+		if l.uploadCh != nil {
+			close(l.uploadCh)
+			l.uploadWG.Wait()
+		}
+
+		// 6-7. Release the file and sinks
+		result <- l.release()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		// Entries queued but not written by now are given up on
+		close(l.abort)
+		return &ShutdownError{Abandoned: l.stats.unwritten(), Err: ctx.Err()}
+	}
+}
+
+// release syncs and closes the log file, then closes every sink
+func (l *Logger) release() error {
+	// 6. Persist and release the file handle
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var firstErr error
+	if l.file != nil {
+		l.flushLocked()
+		if err := l.trimLocked(); err != nil {
+			firstErr = err
+		}
+		if err := l.file.Sync(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to sync log file: %v", err)
+		}
+		if err := l.file.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close log file: %v", err)
+		}
+	}
+	if l.spill != nil {
+		// Entries a shutdown deadline left in it are written on the next start
+		if err := l.spill.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// 7. Release the sinks
+	for i, sink := range l.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close sink %d: %v", i+1, err)
+		}
+	}
+	for i, r := range l.routes {
+		if err := r.sink.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close route %d: %v", i+1, err)
+		}
+	}
+	for i, sink := range l.fallbacks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close fallback sink %d: %v", i+1, err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordSink keeps what it is given and whether it was closed
+type recordSink struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (s *recordSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *recordSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestCloseDrainsEveryConsumer(t *testing.T) {
+	// os/signal starts its dispatch goroutine on first use and never stops it
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	signal.Stop(sigCh)
+	before := runtime.NumGoroutine()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logging.json")
+	if err := os.WriteFile(configPath, []byte(`{"level": "info"}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	sink, route, fallback := &recordSink{}, &recordSink{}, &recordSink{}
+	uploads := filepath.Join(dir, "uploads")
+	l, err := New(Config{
+		LogPath:             filepath.Join(dir, "app.log"),
+		Level:               INFO,
+		FormatWorkers:       4,
+		Compress:            true,
+		MaxBackups:          10,
+		Upload:              &UploadConfig{Uploader: DirUploader(uploads)},
+		Sinks:               []Sink{sink},
+		Routes:              []Route{{Levels: LevelsFrom(ERROR), Sink: route}},
+		FallbackSinks:       []Sink{fallback},
+		RateLimit:           1e6,
+		RingBufferSize:      16,
+		DumpOnSignal:        true,
+		ReopenCheck:         10 * time.Millisecond,
+		OverflowPolicy:      OverflowSpill,
+		InternalErrorWriter: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	if err := l.WatchConfig(configPath, 10*time.Millisecond); err != nil {
+		t.Fatalf("failed to watch config file: %v", err)
+	}
+	var hooked, delivered int
+	var mu sync.Mutex
+	l.AddHook(func(e *Entry) error {
+		mu.Lock()
+		hooked++
+		mu.Unlock()
+		return nil
+	})
+	l.SubscribeWith(INFO, func(e Entry) {
+		time.Sleep(time.Millisecond) // Slower than the writer, so entries are still buffered at Close
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	}, SubscribeConfig{Buffer: 1000, Policy: SubscriberBlock})
+
+	for i := 0; i < 400; i++ {
+		l.Info("order %d shipped", i)
+		if i%100 == 99 {
+			l.Error("batch %d incomplete", i/100)
+			if err := l.Rotate(); err != nil {
+				t.Fatalf("failed to rotate: %v", err)
+			}
+			time.Sleep(5 * time.Millisecond) // Uploads are named after the archive time in milliseconds
+		}
+	}
+	l.Info("last order shipped")
+	if err := l.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	mu.Lock()
+	if hooked != 405 || delivered != 405 {
+		t.Errorf("hook saw %d entries and subscriber %d, want 405 each", hooked, delivered)
+	}
+	mu.Unlock()
+	for name, s := range map[string]*recordSink{"sink": sink, "route": route, "fallback": fallback} {
+		if !s.closed {
+			t.Errorf("%s was not closed", name)
+		}
+	}
+	if n := strings.Count(sink.buf.String(), "\n"); n != 405 {
+		t.Errorf("sink got %d lines, want 405", n)
+	}
+	if n := strings.Count(route.buf.String(), "\n"); n != 4 {
+		t.Errorf("route got %d lines, want the 4 errors", n)
+	}
+	uploaded, _ := filepath.Glob(filepath.Join(uploads, "*.gz"))
+	if len(uploaded) != 4 {
+		t.Errorf("got %d compressed uploads, want 4: %v", len(uploaded), uploaded)
+	}
+	if lines := fileLines(t, l.logPath); countLines(lines, "last order shipped") != 1 {
+		t.Errorf("log file is missing the last entry: %v", lines)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("%d goroutines left running after Close, want %d:\n%s",
+				runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// slowSink keeps the writer busy between batches, so callers stay blocked on
+// a full buffer for longer
+type slowSink struct{}
+
+func (slowSink) Write(p []byte) (int, error) {
+	time.Sleep(100 * time.Microsecond)
+	return len(p), nil
+}
+
+func (slowSink) Close() error { return nil }
+
+// closeUnderLoad closes a logger while callers wait on its full buffer and
+// others keep running op, failing if Close never returns
+func closeUnderLoad(t *testing.T, op func(*Logger)) {
+	for i := 0; i < 20; i++ {
+		// Not newTestLogger: its cleanup would wait on a deadlocked Close
+		l, err := New(Config{
+			LogPath:             filepath.Join(t.TempDir(), "app.log"),
+			OverflowPolicy:      OverflowBlock,
+			BufferSize:          1,
+			Sinks:               []Sink{slowSink{}},
+			InternalErrorWriter: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("failed to create logger: %v", err)
+		}
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		run := func(fn func()) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						fn()
+					}
+				}
+			}()
+		}
+		for g := 0; g < 8; g++ {
+			run(func() { l.Info("order shipped") })
+		}
+		for g := 0; g < 4; g++ {
+			run(func() { op(l) })
+		}
+
+		closed := make(chan struct{})
+		go func() {
+			time.Sleep(time.Millisecond)
+			go func() {
+				// Keep calling while Close runs, but not so long that
+				// the calls starve it
+				time.Sleep(5 * time.Millisecond)
+				close(stop)
+			}()
+			l.Close()
+			wg.Wait()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(10 * time.Second):
+			buf := make([]byte, 1<<20)
+			t.Fatalf("Close deadlocked in iteration %d:\n%s", i, buf[:runtime.Stack(buf, true)])
+		}
+	}
+}
+
+func TestCloseWhileFlushing(t *testing.T) {
+	closeUnderLoad(t, func(l *Logger) { l.Flush() })
+}
+
+func TestCloseWhileRotating(t *testing.T) {
+	closeUnderLoad(t, func(l *Logger) { l.Rotate() })
+}
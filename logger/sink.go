@@ -0,0 +1,74 @@
+package logger
+
+import "io"
+
+// Sink is a destination for formatted log output
+type Sink interface {
+	Write(p []byte) (n int, err error)
+	Close() error
+}
+
+// EntrySink is implemented by sinks that want structured entries instead of
+// formatted output, such as journald or network collectors. When a sink in
+// Config.Sinks implements it, WriteEntries is called instead of Write.
+type EntrySink interface {
+	Sink
+	WriteEntries(entries []*Entry) error
+}
+
+// writeSinks delivers a batch to every configured sink. Formatted sinks get
+// the same bytes as the file; entry sinks get the exported entries.
+func (l *Logger) writeSinks(formatted []byte, entries []*logEntry) {
+	var exported []*Entry
+	for i, sink := range l.sinks {
+		var err error
+		if es, ok := sink.(EntrySink); ok {
+			if exported == nil {
+				exported = make([]*Entry, len(entries))
+				for j, e := range entries {
+					exported[j] = e.export()
+				}
+			}
+			err = es.WriteEntries(exported)
+		} else {
+			_, err = sink.Write(formatted)
+		}
+		if err != nil {
+			l.reportError(OpSink, err, "Error writing to sink %d: %v", i+1, err)
+		}
+	}
+}
+
+// writerSink adapts an io.Writer that needs no closing
+type writerSink struct {
+	io.Writer
+}
+
+// Close is a no-op; the wrapped writer is owned by the caller
+func (writerSink) Close() error { return nil }
+
+// WriterSink adapts any io.Writer (os.Stdout, a buffer, a network connection)
+// into a Sink. Closing the sink does not close the writer.
+func WriterSink(w io.Writer) Sink {
+	return writerSink{w}
+}
+
+// writeFallback writes output the primary file rejected to the first fallback
+// sink that accepts it, recording which fallback is active. p is plaintext even
+// when the file is encrypted. The caller must hold l.mu.
+func (l *Logger) writeFallback(p []byte, primaryErr error) {
+	for i, sink := range l.fallbacks {
+		if _, err := sink.Write(p); err != nil {
+			l.reportError(OpWrite, err, "Error writing to fallback sink %d: %v", i+1, err)
+			continue
+		}
+		if prev := l.stats.fallbackLevel.Swap(int64(i + 1)); prev != int64(i+1) {
+			l.reportError(OpWrite, primaryErr, "Error writing to log file: %v (switched to fallback sink %d)", primaryErr, i+1)
+		}
+		return
+	}
+
+	l.stats.fallbackLevel.Store(-1)
+	l.writeErr = primaryErr
+	l.reportError(OpWrite, primaryErr, "Error writing to log file: %v", primaryErr)
+}
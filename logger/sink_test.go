@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// failPrimary makes writes to the log file fail until the returned function
+// puts the file back
+func failPrimary(t *testing.T, l *Logger) func() {
+	t.Helper()
+	readOnly, err := os.Open(l.logPath)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	l.mu.Lock()
+	file := l.file
+	l.file = readOnly
+	l.mu.Unlock()
+	return func() {
+		l.mu.Lock()
+		l.file = file
+		l.mu.Unlock()
+		readOnly.Close()
+	}
+}
+
+func TestFallbackAndRecovery(t *testing.T) {
+	var fallback bytes.Buffer
+	l := newTestLogger(t, Config{FallbackSinks: []Sink{WriterSink(&fallback)}, InternalErrorWriter: io.Discard})
+
+	l.Info("before the outage")
+	l.flush()
+
+	restore := failPrimary(t, l)
+	l.Info("during the outage")
+	l.flush()
+	if !strings.Contains(fallback.String(), "during the outage") {
+		t.Errorf("fallback sink got %q, want the entry logged during the outage", fallback.String())
+	}
+	if level := l.Stats().FallbackLevel; level != 1 {
+		t.Errorf("FallbackLevel = %d during the outage, want 1", level)
+	}
+
+	restore()
+	l.Info("after recovery")
+	lines := readLines(t, l)
+	if countLines(lines, "before the outage") != 1 || countLines(lines, "after recovery") != 1 {
+		t.Errorf("log file is missing entries written by the file:\n%v", lines)
+	}
+	if countLines(lines, "during the outage") != 0 {
+		t.Errorf("log file holds the entry written during the outage")
+	}
+	if strings.Contains(fallback.String(), "after recovery") {
+		t.Errorf("fallback sink got an entry after the file recovered")
+	}
+	if level := l.Stats().FallbackLevel; level != 0 {
+		t.Errorf("FallbackLevel = %d after recovery, want 0", level)
+	}
+}
+
+func TestFallbackGetsPlaintext(t *testing.T) {
+	var fallback bytes.Buffer
+	l := newTestLogger(t, Config{
+		EncryptionKey:       bytes.Repeat([]byte{7}, 32),
+		FallbackSinks:       []Sink{WriterSink(&fallback)},
+		InternalErrorWriter: io.Discard,
+	})
+
+	restore := failPrimary(t, l)
+	defer restore()
+	l.Info("card on file updated")
+	l.flush()
+
+	if !strings.Contains(fallback.String(), "card on file updated") {
+		t.Errorf("fallback sink got %q, want the plaintext entry", fallback.String())
+	}
+}
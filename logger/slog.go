@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// slogHandler implements slog.Handler on top of a Logger's async pipeline
+type slogHandler struct {
+	l      *Logger
+	attrs  []Field // Attributes added with WithAttrs, already flattened
+	prefix string  // Key prefix of the open groups, e.g. "request."
+}
+
+// SlogHandler returns a slog.Handler that routes records through this logger,
+// so code using the standard log/slog API writes to the same files and sinks:
+//
+//	slog.SetDefault(slog.New(l.SlogHandler()))
+//
+// Groups are flattened into dotted field keys, request-scoped fields from
+// the record's context (see ContextWithFields) are attached, and entries keep
+// the record's time when it is set.
+func (l *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// SlogHandler returns a slog.Handler backed by the default logger
+func SlogHandler() slog.Handler {
+	return defaultLogger.SlogHandler()
+}
+
+// slogLevel maps a slog level onto the nearest logger level at or below it
+func slogLevel(level slog.Level) int {
+	switch {
+	case level < slog.LevelDebug:
+		return TRACE
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
+
+// Enabled reports whether records at level would be written
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l != nil && (!levelBelow(slogLevel(level), h.l.minLevel()) || h.l.ring != nil)
+}
+
+// Handle converts a record into an entry and enqueues it
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := slogLevel(r.Level)
+	if !h.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	ctxFields := h.l.contextFields(ctx)
+	fields := make([]Field, 0, len(ctxFields)+len(h.attrs)+r.NumAttrs())
+	fields = append(fields, ctxFields...)
+	fields = append(fields, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	pc := r.PC
+	if h.l.callerOff {
+		pc = 0
+	}
+	var file string
+	var line int
+	if pc != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		file, line = frame.File, frame.Line
+	}
+
+	l := h.l
+	withCtx := ctx != nil && ctx != l.ctx && l.hasHooks()
+	if withCtx || !r.Time.IsZero() {
+		// Hooks see the context of the call, entries carry the record's time
+		c := *l
+		if withCtx {
+			c.ctx = ctx
+		}
+		c.at = r.Time
+		l = &c
+	}
+	l.dispatch(level, 0, r.Message, fields, pc, file, line)
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := append(make([]Field, 0, len(h.attrs)+len(attrs)), h.attrs...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{l: h.l, attrs: fields, prefix: h.prefix}
+}
+
+// WithGroup returns a handler that nests later attributes under name
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendAttr flattens an attribute into fields, expanding groups into dotted keys
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogRecordTime(t *testing.T) {
+	l := newTestLogger(t, Config{})
+	var hooked time.Time
+	l.AddHook(func(e *Entry) error {
+		hooked = e.Time
+		return nil
+	})
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	r := slog.NewRecord(at, slog.LevelInfo, "replayed", 0)
+	if err := l.SlogHandler().Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	lines := readLines(t, l)
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "2020/01/02 03:04:05") {
+		t.Errorf("lines = %q, want the record's time", lines)
+	}
+	if !hooked.Equal(at) {
+		t.Errorf("hook saw %v, want %v", hooked, at)
+	}
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotCopiesCompleteLines(t *testing.T) {
+	l := newTestLogger(t, Config{WriteBufferSize: 1 << 20})
+	for i := 0; i < 100; i++ {
+		l.Info("order %d shipped", i)
+	}
+
+	dest := filepath.Join(t.TempDir(), "snapshot.log")
+	if err := l.Snapshot(dest); err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	snapshot := fileLines(t, dest)
+	if n := countLines(snapshot, "shipped"); n != 100 || len(snapshot) != 100 {
+		t.Errorf("snapshot holds %d lines with %d entries, want the 100 logged before it", len(snapshot), n)
+	}
+
+	source := fileLines(t, l.logPath)
+	if strings.Join(snapshot, "\n") != strings.Join(source, "\n") {
+		t.Errorf("snapshot differs from the log file:\nsnapshot: %v\nsource:   %v", snapshot, source)
+	}
+}
@@ -0,0 +1,317 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultSpillMaxSize caps the spill file unless SpillMaxSize is set
+const defaultSpillMaxSize = 100 * 1024 * 1024 // 100MB
+
+// errSpillFull is returned by add once the spill file reached SpillMaxSize
+var errSpillFull = errors.New("spill file full")
+
+// spiller is the on-disk queue of OverflowSpill. Entries are appended as JSON
+// lines while the buffer is full and read back by the writer goroutine once
+// the buffer has drained. While anything is spilled, new entries are spilled
+// too, so entries keep their order.
+type spiller struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	w       *os.File      // Append handle
+	r       *bufio.Reader // Reads records from the start of the file
+	rf      *os.File      // Read handle behind r
+	size    int64         // Bytes appended
+	read    int64         // Bytes read back
+	stale   int64         // Bytes left by a previous run, read back first
+	active  atomic.Bool   // Set while spilled entries wait to be written
+	ready   chan struct{} // Wakes the writer goroutine to drain the file
+}
+
+// spillRecord is an entry in the spill file
+type spillRecord struct {
+	Level  int          `json:"level"`
+	Flags  entryFlags   `json:"flags,omitempty"`
+	Time   int64        `json:"time"`
+	PC     uintptr      `json:"pc,omitempty"`
+	File   string       `json:"file,omitempty"`
+	Line   int          `json:"line,omitempty"`
+	Msg    []byte       `json:"msg"`
+	Fields []spillField `json:"fields,omitempty"`
+}
+
+// spillField keeps a field's text and JSON renderings, so restored entries
+// are written as they would have been
+type spillField struct {
+	Key  string          `json:"k"`
+	Text string          `json:"t"`
+	JSON json.RawMessage `json:"j"`
+}
+
+// spilledValue is a field value read back from the spill file
+type spilledValue struct {
+	text string
+	json json.RawMessage
+}
+
+// String returns the text rendering of the value
+func (v spilledValue) String() string { return v.text }
+
+// MarshalJSON returns the JSON rendering of the value
+func (v spilledValue) MarshalJSON() ([]byte, error) { return v.json, nil }
+
+// openSpill opens the spill file, keeping entries a previous run left in it
+func openSpill(path string, maxSize int64) (*spiller, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %v", err)
+	}
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %v", err)
+	}
+	rf, err := os.Open(path)
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("failed to open spill file: %v", err)
+	}
+	info, err := w.Stat()
+	if err != nil {
+		w.Close()
+		rf.Close()
+		return nil, fmt.Errorf("failed to get spill file info: %v", err)
+	}
+
+	s := &spiller{
+		path:    path,
+		maxSize: maxSize,
+		w:       w,
+		rf:      rf,
+		r:       bufio.NewReaderSize(rf, 64*1024),
+		size:    info.Size(),
+		stale:   info.Size(),
+		ready:   make(chan struct{}, 1),
+	}
+	if s.size > 0 {
+		s.active.Store(true)
+		s.ready <- struct{}{}
+	}
+	return s, nil
+}
+
+// add appends an entry to the spill file
+func (s *spiller) add(entry *logEntry) error {
+	rec := spillRecord{
+		Level: entry.level,
+		Flags: entry.flags,
+		Time:  entry.timestamp,
+		PC:    entry.pc,
+		File:  entry.file,
+		Line:  entry.line,
+		Msg:   entry.msg,
+	}
+	if len(entry.fields) > 0 {
+		rec.Fields = make([]spillField, len(entry.fields))
+		var buf bytes.Buffer
+		for i, f := range entry.fields {
+			buf.Reset()
+			appendJSONValue(&buf, f.Value)
+			raw := json.RawMessage(append([]byte(nil), buf.Bytes()...))
+			text := formatValue(f.Value)
+			if !json.Valid(raw) {
+				raw, _ = json.Marshal(text)
+			}
+			rec.Fields[i] = spillField{Key: f.Key, Text: text, JSON: raw}
+		}
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode spilled entry: %v", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+int64(len(line)) > s.maxSize {
+		return errSpillFull
+	}
+	n, err := s.w.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write spill file: %v", err)
+	}
+	s.active.Store(true)
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next reads up to max entries back from the spill file, returning how many
+// of them a previous run left and whether more remain. Once everything is
+// read the file is emptied and new entries go to the buffer again.
+func (s *spiller) next(max int) ([]*logEntry, int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []*logEntry
+	stale := 0
+	for len(entries) < max && s.read < s.size {
+		line, err := s.r.ReadBytes('\n')
+		start := s.read
+		s.read += int64(len(line))
+		if err == io.EOF {
+			// A record cut short by a crash
+			s.read = s.size
+			break
+		}
+		if err != nil {
+			// The rest of the file cannot be trusted; start over
+			s.reset()
+			return entries, stale, false, fmt.Errorf("failed to read spill file: %v", err)
+		}
+		entry, err := decodeSpill(line, start < s.stale)
+		if err != nil {
+			continue
+		}
+		if start < s.stale {
+			stale++
+		}
+		entries = append(entries, entry)
+	}
+
+	if s.read < s.size {
+		return entries, stale, true, nil
+	}
+	return entries, stale, false, s.reset()
+}
+
+// reset empties the spill file once every entry was read back. The caller
+// must hold s.mu.
+func (s *spiller) reset() error {
+	s.active.Store(false)
+	s.size, s.read, s.stale = 0, 0, 0
+	if err := s.w.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate spill file: %v", err)
+	}
+	if _, err := s.rf.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind spill file: %v", err)
+	}
+	s.r.Reset(s.rf)
+	return nil
+}
+
+// close releases the spill file, removing it when nothing is left in it.
+// Entries already written are cut from the front, so the next run reads
+// back only the rest.
+func (s *spiller) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rf.Close()
+	if err := s.w.Close(); err != nil {
+		return fmt.Errorf("failed to close spill file: %v", err)
+	}
+	if s.read >= s.size {
+		os.Remove(s.path)
+		return nil
+	}
+	if s.read == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read spill file: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data[s.read:], 0600); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact spill file: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact spill file: %v", err)
+	}
+	return nil
+}
+
+// decodeSpill restores an entry from a spill record. Program counters of
+// records left by a previous run mean nothing in this one and are dropped.
+func decodeSpill(line []byte, stale bool) (*logEntry, error) {
+	var rec spillRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, err
+	}
+	entry := entryPool.Get().(*logEntry)
+	entry.level = rec.Level
+	entry.flags = rec.Flags
+	entry.timestamp = rec.Time
+	entry.pc = rec.PC
+	if stale {
+		entry.pc = 0
+	}
+	entry.file = rec.File
+	entry.line = rec.Line
+	entry.msg = append(entry.msg[:0], rec.Msg...)
+	if len(rec.Fields) > 0 {
+		entry.fields = make([]Field, len(rec.Fields))
+		for i, f := range rec.Fields {
+			entry.fields[i] = Field{Key: f.Key, Value: spilledValue{text: f.Text, json: f.JSON}}
+		}
+	}
+	return entry, nil
+}
+
+// spillEntry appends an entry the full buffer cannot take to the spill file
+// and returns it to the pool, reporting whether it was kept
+func (l *Logger) spillEntry(entry *logEntry) bool {
+	if err := l.spill.add(entry); err != nil {
+		if err != errSpillFull {
+			l.reportError(OpSpill, err, "Error spilling log entry: %v", err)
+		}
+		return false
+	}
+	l.stats.spilled.Add(1)
+	releaseEntry(entry)
+	return true
+}
+
+// drainSpill writes spilled entries, a batch at a time or all of them. It
+// runs on the writer goroutine after everything queued in the buffer, which
+// is older, has been written.
+func (l *Logger) drainSpill(all bool) {
+	for {
+		entries, stale, more, err := l.spill.next(l.batchSize)
+		if err != nil {
+			l.reportError(OpSpill, err, "Error draining spill file: %v", err)
+		}
+		// Entries of a previous run were never counted in this one
+		for _, e := range entries[:stale] {
+			l.stats.countLevel(e.level)
+		}
+		if len(entries) > 0 {
+			l.commit(entries)
+		}
+		if !more || err != nil || l.aborted() {
+			return
+		}
+		if !all {
+			// Come back after whatever else the writer has to do
+			select {
+			case l.spill.ready <- struct{}{}:
+			default:
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StacktraceConfig attaches a stack trace to entries at or above a level
+type StacktraceConfig struct {
+	Level int // Entries at or above this level get a "stack" field
+	Depth int // Maximum frames recorded (default: 32)
+	Skip  int // Frames dropped from the top, e.g. for logging wrappers (default: 0, the logging call is the first frame)
+}
+
+// stacktracer captures stack traces for entries at or above level
+type stacktracer struct {
+	level int
+	depth int
+	skip  int
+}
+
+// newStacktracer returns a stacktracer for cfg, or nil when cfg is nil
+func newStacktracer(cfg *StacktraceConfig) *stacktracer {
+	if cfg == nil {
+		return nil
+	}
+	depth := cfg.Depth
+	if depth <= 0 {
+		depth = 32
+	}
+	skip := cfg.Skip
+	if skip < 0 {
+		skip = 0
+	}
+	return &stacktracer{level: cfg.Level, depth: depth, skip: skip}
+}
+
+// capture renders the current goroutine's stack starting at the logging call
+// file:line, one "function\n\tfile:line" pair per frame
+func (s *stacktracer) capture(file string, line int) string {
+	pcs := make([]uintptr, s.depth+s.skip+32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	// Frames above the call site belong to the logger (or slog, log, fmt)
+	var b strings.Builder
+	started := false
+	skip, kept := s.skip, 0
+	for kept < s.depth {
+		frame, more := frames.Next()
+		if !started {
+			if file == "" {
+				// No call site recorded: start at the first frame outside the logger
+				started = !strings.HasPrefix(frame.Function, "github.com/jbarasa/logger/logger.")
+			} else {
+				started = frame.File == file && frame.Line == line
+			}
+		}
+		if started {
+			if skip > 0 {
+				skip--
+			} else {
+				b.WriteString(frame.Function)
+				b.WriteString("\n\t")
+				b.WriteString(frame.File)
+				b.WriteByte(':')
+				b.WriteString(strconv.Itoa(frame.Line))
+				b.WriteByte('\n')
+				kept++
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// withStack appends a "stack" field when the level calls for one
+func (l *Logger) withStack(level int, fields []Field, file string, line int) []Field {
+	if l.stacktracer == nil || levelBelow(level, l.stacktracer.level) {
+		return fields
+	}
+	stack := l.stacktracer.capture(file, line)
+	if stack == "" {
+		return fields
+	}
+	// Never append into the caller's array
+	return append(fields[:len(fields):len(fields)], Field{Key: "stack", Value: stack})
+}
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogStats is a point-in-time snapshot of the logger's internal counters
+type LogStats struct {
+	RateLimited   uint64  // Entries discarded by the rate limiter
+	FallbackLevel int     // 0 while writing to the log file, N while using the Nth fallback sink, -1 if all failed
+	Shed          uint64  // Entries dropped by adaptive load shedding
+	ShedRate      float64 // Fraction of non-priority entries currently being dropped
+	Dropped       uint64  // Entries dropped because the buffer was full (see OverflowPolicy)
+	Sampled       uint64  // Entries skipped by sampling
+	Deduplicated  uint64  // Repeats collapsed into "last message repeated" entries (see DedupConfig)
+	Uploaded      uint64  // Archives uploaded (see UploadConfig)
+	Oversized     uint64  // Messages over MaxMessageSize, truncated, split or dropped
+	Spilled       uint64  // Entries written to the spill file because the buffer was full (see OverflowSpill)
+
+	Logged          map[string]uint64 // Entries queued for writing, by level name
+	DroppedByLevel  map[string]uint64 // Entries dropped because the buffer was full, by level name
+	BytesWritten    uint64            // Bytes written to the log file
+	Rotations       uint64            // Completed log file rotations
+	QueueDepth      int               // Entries waiting in the buffer
+	QueueCapacity   int               // Size of the buffer
+	Writes          uint64            // Batch writes to the log file
+	Syncs           uint64            // Syncs of the log file to disk (see SyncPolicy)
+	WriteLatency    time.Duration     // Average duration of a batch write
+	MaxWriteLatency time.Duration     // Longest batch write
+}
+
+// stats holds the live counters behind LogStats
+type stats struct {
+	rateLimited   atomic.Uint64
+	fallbackLevel atomic.Int64
+	shed          atomic.Uint64
+	dropped       atomic.Uint64
+	sampled       atomic.Uint64
+	deduplicated  atomic.Uint64
+	uploaded      atomic.Uint64
+	oversized     atomic.Uint64
+	spilled       atomic.Uint64
+
+	logged       [FATAL - TRACE + 1]atomic.Uint64 // Indexed by level - TRACE
+	droppedLevel [FATAL - TRACE + 1]atomic.Uint64 // Drops indexed by level - TRACE
+	custom       sync.Map                         // *levelCounts of custom levels, by level
+	bytesWritten atomic.Uint64
+	rotations    atomic.Uint64
+	writes       atomic.Uint64
+	syncs        atomic.Uint64
+	writeNanos   atomic.Int64  // Total time spent in batch writes
+	maxWrite     atomic.Int64  // Longest batch write
+	written      atomic.Uint64 // Queued entries the writer has finished with
+}
+
+// levelCounts holds the counters of a custom level
+type levelCounts struct {
+	logged  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// customCounts returns the counters of a custom level
+func (s *stats) customCounts(level int) *levelCounts {
+	if c, ok := s.custom.Load(level); ok {
+		return c.(*levelCounts)
+	}
+	c, _ := s.custom.LoadOrStore(level, &levelCounts{})
+	return c.(*levelCounts)
+}
+
+// countLevel counts an entry queued at level
+func (s *stats) countLevel(level int) {
+	if i := level - TRACE; i >= 0 && i < len(s.logged) {
+		s.logged[i].Add(1)
+		return
+	}
+	s.customCounts(level).logged.Add(1)
+}
+
+// countDrop counts an entry at level dropped on a full buffer
+func (s *stats) countDrop(level int) {
+	s.dropped.Add(1)
+	if i := level - TRACE; i >= 0 && i < len(s.droppedLevel) {
+		s.droppedLevel[i].Add(1)
+		return
+	}
+	s.customCounts(level).dropped.Add(1)
+}
+
+// unwritten returns the number of queued entries the writer has not finished with
+func (s *stats) unwritten() uint64 {
+	var queued uint64
+	for i := range s.logged {
+		queued += s.logged[i].Load()
+	}
+	s.custom.Range(func(_, c interface{}) bool {
+		queued += c.(*levelCounts).logged.Load()
+		return true
+	})
+	return queued - s.written.Load()
+}
+
+// recordWrite accounts for one batch write taking d
+func (s *stats) recordWrite(d time.Duration) {
+	s.writes.Add(1)
+	s.writeNanos.Add(int64(d))
+	for {
+		max := s.maxWrite.Load()
+		if int64(d) <= max || s.maxWrite.CompareAndSwap(max, int64(d)) {
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the logger's internal counters
+func (l *Logger) Stats() LogStats {
+	s := LogStats{
+		RateLimited:   l.stats.rateLimited.Load(),
+		FallbackLevel: int(l.stats.fallbackLevel.Load()),
+		Shed:          l.stats.shed.Load(),
+		Dropped:       l.stats.dropped.Load(),
+		Sampled:       l.stats.sampled.Load(),
+		Deduplicated:  l.stats.deduplicated.Load(),
+		Uploaded:      l.stats.uploaded.Load(),
+		Oversized:     l.stats.oversized.Load(),
+		Spilled:       l.stats.spilled.Load(),
+
+		Logged:          make(map[string]uint64, len(l.stats.logged)),
+		DroppedByLevel:  make(map[string]uint64, len(l.stats.droppedLevel)),
+		BytesWritten:    l.stats.bytesWritten.Load(),
+		Rotations:       l.stats.rotations.Load(),
+		QueueDepth:      len(l.logChan),
+		QueueCapacity:   cap(l.logChan),
+		Writes:          l.stats.writes.Load(),
+		Syncs:           l.stats.syncs.Load(),
+		MaxWriteLatency: time.Duration(l.stats.maxWrite.Load()),
+	}
+	for i := range l.stats.logged {
+		s.Logged[LevelName(TRACE+i)] = l.stats.logged[i].Load()
+		s.DroppedByLevel[LevelName(TRACE+i)] = l.stats.droppedLevel[i].Load()
+	}
+	l.stats.custom.Range(func(level, c interface{}) bool {
+		s.Logged[LevelName(level.(int))] = c.(*levelCounts).logged.Load()
+		s.DroppedByLevel[LevelName(level.(int))] = c.(*levelCounts).dropped.Load()
+		return true
+	})
+	if s.Writes > 0 {
+		s.WriteLatency = time.Duration(l.stats.writeNanos.Load() / int64(s.Writes))
+	}
+	if l.shedder != nil {
+		s.ShedRate = l.shedder.rate()
+	}
+	return s
+}
+
+// Stats returns a snapshot of the default logger's internal counters
+func Stats() LogStats {
+	if defaultLogger != nil {
+		return defaultLogger.Stats()
+	}
+	return LogStats{}
+}
+
+// WriteMetrics writes the logger's counters in the Prometheus text exposition
+// format, so they can be served from a /metrics endpoint without extra
+// dependencies:
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//	    l.WriteMetrics(w)
+//	})
+func (l *Logger) WriteMetrics(w io.Writer) error {
+	s := l.Stats()
+
+	levels := make([]string, 0, len(s.Logged))
+	for name := range s.Logged {
+		levels = append(levels, name)
+	}
+	sort.Strings(levels)
+
+	var err error
+	metric := func(name, kind, help string, value interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+		}
+	}
+
+	if _, err = fmt.Fprintf(w, "# HELP logger_entries_total Entries queued for writing.\n# TYPE logger_entries_total counter\n"); err != nil {
+		return err
+	}
+	for _, name := range levels {
+		if _, err = fmt.Fprintf(w, "logger_entries_total{level=%q} %d\n", name, s.Logged[name]); err != nil {
+			return err
+		}
+	}
+	metric("logger_dropped_total", "counter", "Entries dropped because the buffer was full.", s.Dropped)
+	if err == nil {
+		_, err = fmt.Fprintf(w, "# HELP logger_dropped_level_total Entries dropped because the buffer was full, by level.\n# TYPE logger_dropped_level_total counter\n")
+	}
+	for _, name := range levels {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "logger_dropped_level_total{level=%q} %d\n", name, s.DroppedByLevel[name])
+		}
+	}
+	metric("logger_rate_limited_total", "counter", "Entries discarded by the rate limiter.", s.RateLimited)
+	metric("logger_shed_total", "counter", "Entries dropped by adaptive load shedding.", s.Shed)
+	metric("logger_sampled_total", "counter", "Entries skipped by sampling.", s.Sampled)
+	metric("logger_deduplicated_total", "counter", "Repeated entries collapsed by deduplication.", s.Deduplicated)
+	metric("logger_oversized_total", "counter", "Messages over the size limit, truncated, split or dropped.", s.Oversized)
+	metric("logger_spilled_total", "counter", "Entries written to the spill file because the buffer was full.", s.Spilled)
+	metric("logger_bytes_written_total", "counter", "Bytes written to the log file.", s.BytesWritten)
+	metric("logger_rotations_total", "counter", "Completed log file rotations.", s.Rotations)
+	metric("logger_archives_uploaded_total", "counter", "Archives uploaded to long-term storage.", s.Uploaded)
+	metric("logger_writes_total", "counter", "Batch writes to the log file.", s.Writes)
+	metric("logger_syncs_total", "counter", "Syncs of the log file to disk.", s.Syncs)
+	metric("logger_queue_depth", "gauge", "Entries waiting in the buffer.", s.QueueDepth)
+	metric("logger_queue_capacity", "gauge", "Size of the buffer.", s.QueueCapacity)
+	metric("logger_write_latency_seconds", "gauge", "Average duration of a batch write.", s.WriteLatency.Seconds())
+	metric("logger_write_latency_max_seconds", "gauge", "Longest batch write.", s.MaxWriteLatency.Seconds())
+	metric("logger_shed_rate", "gauge", "Fraction of non-priority entries currently being shed.", s.ShedRate)
+	metric("logger_fallback_level", "gauge", "0 when writing to the file, N for the Nth fallback sink, -1 if all failed.", s.FallbackLevel)
+	return err
+}
+
+// WriteMetrics writes the default logger's counters in the Prometheus text format
+func WriteMetrics(w io.Writer) error {
+	if defaultLogger != nil {
+		return defaultLogger.WriteMetrics(w)
+	}
+	return nil
+}
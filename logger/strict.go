@@ -0,0 +1,180 @@
+package logger
+
+import "errors"
+
+// ErrClosed is returned by strict calls made after the logger was closed, or
+// whose entry a shutdown deadline left unwritten
+var ErrClosed = errors.New("logger is closed")
+
+// errNoLogger is returned by strict calls on the default logger before Initialize
+var errNoLogger = errors.New("logger is not initialized")
+
+// StrictLogger logs through a Logger and reports whether each entry was
+// recorded, for audit-critical paths where a lost line must not go unnoticed.
+// Every call waits until its entry has been written and returns nil, or
+// returns why it was not: ErrClosed, ErrDropEntry when a hook dropped it, or
+// an *OpError when the file and every fallback sink rejected the write.
+// Entries bypass sampling, rate limiting and load shedding and wait for
+// buffer space; a level below the minimum returns nil without logging.
+//
+//	if err := log.Strict().InfoKV("payment captured", logger.Fields{"id": id}); err != nil {
+//	    return fmt.Errorf("failed to record payment: %v", err)
+//	}
+type StrictLogger struct {
+	l *Logger
+}
+
+// strictCall collects the outcome of one strict entry
+type strictCall struct {
+	done    chan error // Receives the outcome when the entry is released
+	pending bool       // Set once the entry was created
+}
+
+// Strict returns a view of the logger whose calls report whether their
+// entries were recorded
+func (l *Logger) Strict() *StrictLogger {
+	return &StrictLogger{l: l}
+}
+
+// Strict returns a StrictLogger for the default logger
+func Strict() *StrictLogger {
+	return &StrictLogger{l: defaultLogger}
+}
+
+// begin returns a copy of the logger that hands its entries' outcome to call
+func (s *StrictLogger) begin() (*Logger, *strictCall) {
+	if s.l == nil {
+		return nil, nil
+	}
+	call := &strictCall{done: make(chan error, 1)}
+	c := *s.l
+	c.strict = call
+	return &c, call
+}
+
+// wait returns the outcome of the entry, or nil when none was created
+func (call *strictCall) wait() error {
+	if call == nil {
+		return errNoLogger
+	}
+	if !call.pending {
+		return nil
+	}
+	return <-call.done
+}
+
+// settle hands an entry's outcome to its strict caller, if any. The first
+// outcome wins; releaseEntry reports success for entries not settled before.
+func settle(e *logEntry, err error) {
+	if e.done != nil {
+		e.done <- err
+		e.done = nil
+	}
+}
+
+// hasStrict reports whether a strict caller waits for any of the entries
+func hasStrict(entries []*logEntry) bool {
+	for _, e := range entries {
+		if e.done != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// abandon settles entries a shutdown deadline left unwritten, including
+// those still in the closed channel
+func (l *Logger) abandon(batch []*logEntry) {
+	for _, e := range batch {
+		settle(e, ErrClosed)
+	}
+	for e := range l.logChan {
+		settle(e, ErrClosed)
+	}
+}
+
+// Trace logs a trace message and reports whether it was recorded
+func (s *StrictLogger) Trace(format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(TRACE, flagPriority, format, args...)
+	return call.wait()
+}
+
+// Debug logs a debug message and reports whether it was recorded
+func (s *StrictLogger) Debug(format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(DEBUG, flagPriority, format, args...)
+	return call.wait()
+}
+
+// Info logs an info message and reports whether it was recorded
+func (s *StrictLogger) Info(format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(INFO, flagPriority, format, args...)
+	return call.wait()
+}
+
+// Warn logs a warning message and reports whether it was recorded
+func (s *StrictLogger) Warn(format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(WARN, flagPriority, format, args...)
+	return call.wait()
+}
+
+// Error logs an error message and reports whether it was recorded
+func (s *StrictLogger) Error(format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(ERROR, flagPriority, format, args...)
+	return call.wait()
+}
+
+// Log logs a formatted message at any level and reports whether it was
+// recorded. At PANIC and FATAL it neither panics nor exits.
+func (s *StrictLogger) Log(level int, format string, args ...interface{}) error {
+	l, call := s.begin()
+	l.log(level, flagPriority|flagNoExit, format, args...)
+	return call.wait()
+}
+
+// TraceKV logs a trace message with fields and reports whether it was recorded
+func (s *StrictLogger) TraceKV(msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(TRACE, flagPriority, msg, fields.sorted())
+	return call.wait()
+}
+
+// DebugKV logs a debug message with fields and reports whether it was recorded
+func (s *StrictLogger) DebugKV(msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(DEBUG, flagPriority, msg, fields.sorted())
+	return call.wait()
+}
+
+// InfoKV logs an info message with fields and reports whether it was recorded
+func (s *StrictLogger) InfoKV(msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(INFO, flagPriority, msg, fields.sorted())
+	return call.wait()
+}
+
+// WarnKV logs a warning message with fields and reports whether it was recorded
+func (s *StrictLogger) WarnKV(msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(WARN, flagPriority, msg, fields.sorted())
+	return call.wait()
+}
+
+// ErrorKV logs an error message with fields and reports whether it was recorded
+func (s *StrictLogger) ErrorKV(msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(ERROR, flagPriority, msg, fields.sorted())
+	return call.wait()
+}
+
+// LogKV logs a message with fields at any level and reports whether it was
+// recorded. At PANIC and FATAL it neither panics nor exits.
+func (s *StrictLogger) LogKV(level int, msg string, fields Fields) error {
+	l, call := s.begin()
+	l.logFields(level, flagPriority|flagNoExit, msg, fields.sorted())
+	return call.wait()
+}
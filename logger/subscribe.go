@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// defaultSubscriberBuffer is the number of entries buffered per subscriber
+const defaultSubscriberBuffer = 1024
+
+// SubscriberPolicy decides what happens to an entry when a subscriber's
+// buffer is full
+type SubscriberPolicy int
+
+// Subscriber policies
+const (
+	SubscriberDropNewest SubscriberPolicy = iota // Drop the new entry; the subscriber misses the latest entries (default)
+	SubscriberDropOldest                         // Drop the oldest buffered entry; the subscriber stays current
+	SubscriberBlock                              // Make the writer wait; nothing is missed but a slow subscriber slows all logging
+)
+
+// SubscribeConfig configures a subscription
+type SubscribeConfig struct {
+	Buffer int              // Entries buffered between the writer and the subscriber (default: 1024)
+	Policy SubscriberPolicy // What a full buffer does: SubscriberDropNewest, SubscriberDropOldest or SubscriberBlock
+}
+
+// Subscription is a live stream of written entries to a function, created by
+// Subscribe. Entries are delivered in order on the subscription's own
+// goroutine, so a slow consumer does not hold up logging.
+type Subscription struct {
+	l       *Logger
+	level   int
+	fn      func(Entry)
+	policy  SubscriberPolicy
+	ch      chan *Entry
+	quit    chan struct{} // Closed by Close; nothing more is delivered
+	drain   chan struct{} // Closed when the logger closes; the buffer is delivered, then the goroutine exits
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+// Subscribe calls fn with every entry at level or above once it has been
+// written, e.g. to feed an admin UI or a WebSocket debug console without
+// re-reading the log file. Entries carry their rendered Caller. Close the
+// subscription to stop it; entries still buffered when the logger closes
+// are delivered first.
+func (l *Logger) Subscribe(level int, fn func(Entry)) *Subscription {
+	return l.SubscribeWith(level, fn, SubscribeConfig{})
+}
+
+// SubscribeWith is Subscribe with a buffer size and drop policy
+func (l *Logger) SubscribeWith(level int, fn func(Entry), config SubscribeConfig) *Subscription {
+	if l == nil {
+		return nil
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = defaultSubscriberBuffer
+	}
+	s := &Subscription{
+		l:      l,
+		level:  level,
+		fn:     fn,
+		policy: config.Policy,
+		ch:     make(chan *Entry, config.Buffer),
+		quit:   make(chan struct{}),
+		drain:  make(chan struct{}),
+	}
+
+	l.subsMu.Lock()
+	if l.subsClosed {
+		l.subsMu.Unlock()
+		close(s.quit)
+		return s
+	}
+	// Copy on write so publish can iterate without holding the lock
+	subs := make([]*Subscription, len(l.subs), len(l.subs)+1)
+	copy(subs, l.subs)
+	l.subs = append(subs, s)
+	l.subsWG.Add(1)
+	l.subsMu.Unlock()
+
+	go s.run()
+	return s
+}
+
+// Subscribe subscribes to the entries of the default logger
+func Subscribe(level int, fn func(Entry)) *Subscription {
+	return defaultLogger.Subscribe(level, fn)
+}
+
+// SubscribeWith subscribes to the entries of the default logger with a
+// buffer size and drop policy
+func SubscribeWith(level int, fn func(Entry), config SubscribeConfig) *Subscription {
+	return defaultLogger.SubscribeWith(level, fn, config)
+}
+
+// Close stops the subscription and discards its buffered entries. A call to
+// fn already in progress completes, so Close may be called from fn.
+func (s *Subscription) Close() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		close(s.quit)
+		l := s.l
+		l.subsMu.Lock()
+		defer l.subsMu.Unlock()
+		subs := make([]*Subscription, 0, len(l.subs))
+		for _, other := range l.subs {
+			if other != s {
+				subs = append(subs, other)
+			}
+		}
+		l.subs = subs
+	})
+}
+
+// Dropped returns the number of entries the subscriber missed because its
+// buffer was full
+func (s *Subscription) Dropped() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.dropped.Load()
+}
+
+// run delivers buffered entries until the subscription is closed, or until
+// the buffer is empty once the logger is closing
+func (s *Subscription) run() {
+	defer s.l.subsWG.Done()
+	for {
+		select {
+		case e := <-s.ch:
+			s.deliver(e)
+		case <-s.quit:
+			return
+		case <-s.drain:
+			for {
+				select {
+				case e := <-s.ch:
+					s.deliver(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver calls fn unless the subscription was closed meanwhile
+func (s *Subscription) deliver(e *Entry) {
+	select {
+	case <-s.quit:
+	default:
+		s.fn(*e)
+	}
+}
+
+// send buffers an entry according to the subscriber's policy
+func (s *Subscription) send(e *Entry) {
+	switch s.policy {
+	case SubscriberBlock:
+		select {
+		case s.ch <- e:
+		case <-s.quit:
+		}
+	case SubscriberDropOldest:
+		for {
+			select {
+			case s.ch <- e:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// publish hands a written batch to the subscribers
+func (l *Logger) publish(entries []*logEntry) {
+	l.subsMu.RLock()
+	subs := l.subs
+	l.subsMu.RUnlock()
+	if len(subs) == 0 {
+		return
+	}
+
+	exported := make([]*Entry, len(entries))
+	for _, s := range subs {
+		for i, entry := range entries {
+			if levelBelow(entry.level, s.level) {
+				continue
+			}
+			if exported[i] == nil {
+				exported[i] = entry.export()
+				exported[i].Caller = l.formatCaller(entry)
+			}
+			s.send(exported[i])
+		}
+	}
+}
+
+// closeSubscriptions delivers what the subscribers still have buffered and
+// waits for them to finish
+func (l *Logger) closeSubscriptions() {
+	l.subsMu.Lock()
+	l.subsClosed = true
+	subs := l.subs
+	l.subsMu.Unlock()
+
+	for _, s := range subs {
+		close(s.drain)
+	}
+	l.subsWG.Wait()
+}
@@ -0,0 +1,138 @@
+package logger
+
+// badKey is the key of a value given where a key was expected
+const badKey = "!BADKEY"
+
+// The W variants take alternating keys and values after the message, like
+// zap's SugaredLogger, to attach fields to a single call without a child
+// logger or a Fields map:
+//
+//	l.InfoW("Request served", "status", 200, "path", r.URL.Path)
+//
+// A Field may also be passed in place of a key and value. A key that is not
+// a string, or a final key without a value, is logged under "!BADKEY".
+
+// sweeten converts alternating keys and values into fields
+func sweeten(keysAndValues []interface{}) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i++ {
+		switch v := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, v)
+		case string:
+			if i+1 == len(keysAndValues) {
+				fields = append(fields, Field{Key: badKey, Value: v})
+				break
+			}
+			fields = append(fields, Field{Key: v, Value: keysAndValues[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: v})
+		}
+	}
+	return fields
+}
+
+// logW logs msg with fields built from keysAndValues, which are only
+// converted when the entry is logged
+func (l *Logger) logW(level int, flags entryFlags, msg string, keysAndValues []interface{}) {
+	if l == nil || (levelBelow(level, l.minLevel()) && l.ring == nil) {
+		return
+	}
+
+	// Get caller info
+	pc, file, line := l.caller(2)
+
+	l.dispatch(level, flags, msg, sweeten(keysAndValues), pc, file, line)
+}
+
+// TraceW logs a trace message with alternating keys and values
+func (l *Logger) TraceW(msg string, keysAndValues ...interface{}) {
+	l.logW(TRACE, 0, msg, keysAndValues)
+}
+
+// DebugW logs a debug message with alternating keys and values
+func (l *Logger) DebugW(msg string, keysAndValues ...interface{}) {
+	l.logW(DEBUG, 0, msg, keysAndValues)
+}
+
+// InfoW logs an info message with alternating keys and values
+func (l *Logger) InfoW(msg string, keysAndValues ...interface{}) {
+	l.logW(INFO, 0, msg, keysAndValues)
+}
+
+// WarnW logs a warning message with alternating keys and values
+func (l *Logger) WarnW(msg string, keysAndValues ...interface{}) {
+	l.logW(WARN, 0, msg, keysAndValues)
+}
+
+// ErrorW logs an error message with alternating keys and values
+func (l *Logger) ErrorW(msg string, keysAndValues ...interface{}) {
+	l.logW(ERROR, 0, msg, keysAndValues)
+}
+
+// FatalW logs a fatal message with alternating keys and values
+func (l *Logger) FatalW(msg string, keysAndValues ...interface{}) {
+	l.logW(FATAL, flagPriority, msg, keysAndValues)
+}
+
+// PanicW logs a panic message with alternating keys and values, waits for it
+// to be written and then panics with it
+func (l *Logger) PanicW(msg string, keysAndValues ...interface{}) {
+	l.logW(PANIC, flagPriority, msg, keysAndValues)
+	panic(msg)
+}
+
+// TraceW logs a trace message with alternating keys and values to the default logger
+func TraceW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(TRACE, 0, msg, keysAndValues)
+	}
+}
+
+// DebugW logs a debug message with alternating keys and values to the default logger
+func DebugW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(DEBUG, 0, msg, keysAndValues)
+	}
+}
+
+// InfoW logs an info message with alternating keys and values to the default logger
+func InfoW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(INFO, 0, msg, keysAndValues)
+	}
+}
+
+// WarnW logs a warning message with alternating keys and values to the default logger
+func WarnW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(WARN, 0, msg, keysAndValues)
+	}
+}
+
+// ErrorW logs an error message with alternating keys and values to the default logger
+func ErrorW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(ERROR, 0, msg, keysAndValues)
+	}
+}
+
+// FatalW logs a fatal message with alternating keys and values to the default logger
+func FatalW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(FATAL, flagPriority, msg, keysAndValues)
+	}
+}
+
+// PanicW logs a panic message with alternating keys and values to the default
+// logger, waits for it to be written and then panics with it
+func PanicW(msg string, keysAndValues ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.logW(PANIC, flagPriority, msg, keysAndValues)
+	}
+	panic(msg)
+}
@@ -0,0 +1,342 @@
+// Package syslog sends log entries to a local or remote syslog daemon such as
+// rsyslog or syslog-ng, over UDP, TCP or a Unix socket.
+//
+// Add the sink to Config.Sinks so every entry is forwarded with its fields:
+//
+//	s, err := syslog.New(syslog.Options{
+//	    Network: "udp",
+//	    Address: "logs.internal:514",
+//	    Tag:     "api",
+//	})
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.Initialize(logger.Config{Sinks: []logger.Sink{s}})
+//
+// With an empty Network the sink connects to the local daemon through the
+// usual socket paths (/dev/log, /var/run/syslog, /var/run/log).
+//
+// Messages use RFC 5424 by default, with structured fields sent as structured
+// data; set Options.Format to RFC3164 for older daemons.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbarasa/logger/logger"
+)
+
+// Format selects the syslog message format
+type Format int
+
+const (
+	RFC5424 Format = iota // Modern format with structured data (default)
+	RFC3164               // BSD format understood by every daemon
+)
+
+// Syslog severities as defined by RFC 5424
+const (
+	SevCrit    = 2
+	SevErr     = 3
+	SevWarning = 4
+	SevInfo    = 6
+	SevDebug   = 7
+)
+
+// Facilities commonly used by applications
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+	FacilityLocal1 = 17
+	FacilityLocal2 = 18
+	FacilityLocal3 = 19
+	FacilityLocal4 = 20
+	FacilityLocal5 = 21
+	FacilityLocal6 = 22
+	FacilityLocal7 = 23
+)
+
+// DefaultStructuredDataID identifies the structured data element holding entry
+// fields (32473 is the enterprise number reserved for documentation)
+const DefaultStructuredDataID = "fields@32473"
+
+// localSockets are tried in order when no network is configured
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Options configures the syslog sink
+type Options struct {
+	Network  string // "udp", "tcp", "unix" or "unixgram"; empty connects to the local daemon
+	Address  string // host:port for udp/tcp, socket path for unix (ignored when Network is empty)
+	Facility int    // Syslog facility (default: FacilityUser)
+	Tag      string // APP-NAME / TAG of every message (default: program name)
+	Hostname string // HOSTNAME of every message (default: os.Hostname)
+	Format   Format // RFC5424 (default) or RFC3164
+
+	StructuredDataID string // SD-ID used for fields in RFC 5424 (default: DefaultStructuredDataID)
+}
+
+// Sink forwards entries to a syslog daemon
+type Sink struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	network  string
+	address  string
+	stream   bool // Stream transports need message framing
+	facility int
+	tag      string
+	hostname string
+	pid      int
+	format   Format
+	sdID     string
+}
+
+// New connects to the syslog daemon described by opts
+func New(opts Options) (*Sink, error) {
+	if opts.Facility == 0 {
+		opts.Facility = FacilityUser
+	}
+	if opts.Tag == "" {
+		opts.Tag = programName()
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.StructuredDataID == "" {
+		opts.StructuredDataID = DefaultStructuredDataID
+	}
+
+	s := &Sink{
+		network:  opts.Network,
+		address:  opts.Address,
+		facility: opts.Facility,
+		tag:      opts.Tag,
+		hostname: opts.Hostname,
+		pid:      os.Getpid(),
+		format:   opts.Format,
+		sdID:     opts.StructuredDataID,
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Severity maps a logger level to a syslog severity
+func Severity(level int) int {
+	switch level = logger.BaseLevel(level); {
+	case level <= logger.DEBUG:
+		return SevDebug
+	case level == logger.INFO:
+		return SevInfo
+	case level == logger.WARN:
+		return SevWarning
+	case level == logger.ERROR:
+		return SevErr
+	default:
+		return SevCrit
+	}
+}
+
+// Hook sends an entry with its structured fields; it matches logger.Hook
+func (s *Sink) Hook(e *logger.Entry) error {
+	return s.send(e)
+}
+
+// WriteEntries sends a batch of entries; it implements logger.EntrySink
+func (s *Sink) WriteEntries(entries []*logger.Entry) error {
+	for _, e := range entries {
+		if err := s.send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write sends each line of formatted output as an INFO message, so the sink
+// can also serve as a plain logger.Sink (for example a fallback)
+func (s *Sink) Write(p []byte) (int, error) {
+	now := time.Now()
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		e := &logger.Entry{Time: now, Level: logger.INFO, Message: string(line)}
+		if err := s.send(e); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the daemon
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// connect dials the configured daemon, or the first local socket that accepts
+func (s *Sink) connect() error {
+	if s.network != "" {
+		conn, err := net.Dial(s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %v", err)
+		}
+		s.conn = conn
+		s.stream = s.network == "tcp" || s.network == "tcp4" || s.network == "tcp6" || s.network == "unix"
+		return nil
+	}
+
+	for _, path := range localSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				s.conn = conn
+				s.stream = network == "unix"
+				return nil
+			}
+		}
+	}
+	return errors.New("failed to connect to syslog: no local syslog socket found")
+}
+
+// send formats an entry and writes it, reconnecting once if the connection was lost
+func (s *Sink) send(e *logger.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.encode(e)
+	if s.conn != nil {
+		if _, err := s.conn.Write(msg); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write(msg); err != nil {
+		return fmt.Errorf("failed to write to syslog: %v", err)
+	}
+	return nil
+}
+
+// encode renders an entry in the configured format and frames it for the transport
+func (s *Sink) encode(e *logger.Entry) []byte {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	pri := s.facility*8 + Severity(e.Level)
+
+	var buf bytes.Buffer
+	if s.format == RFC3164 {
+		// <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value
+		fmt.Fprintf(&buf, "<%d>%s %s %s[%d]: %s", pri, t.Format(time.Stamp), s.hostname, s.tag, s.pid, e.Message)
+		for _, f := range e.Fields {
+			fmt.Fprintf(&buf, " %s=%v", f.Key, f.Value)
+		}
+	} else {
+		// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+		fmt.Fprintf(&buf, "<%d>1 %s %s %s %d - ", pri, t.Format(time.RFC3339Nano),
+			headerField(s.hostname, 255), headerField(s.tag, 48), s.pid)
+		s.appendStructuredData(&buf, e.Fields)
+		buf.WriteByte(' ')
+		buf.WriteString(e.Message)
+	}
+
+	if !s.stream {
+		return buf.Bytes()
+	}
+
+	// Octet counting framing (RFC 6587) keeps multi-line messages intact
+	framed := make([]byte, 0, buf.Len()+8)
+	framed = strconv.AppendInt(framed, int64(buf.Len()), 10)
+	framed = append(framed, ' ')
+	return append(framed, buf.Bytes()...)
+}
+
+// appendStructuredData writes fields as a single SD element, or "-" when there are none
+func (s *Sink) appendStructuredData(buf *bytes.Buffer, fields []logger.Field) {
+	if len(fields) == 0 {
+		buf.WriteByte('-')
+		return
+	}
+
+	buf.WriteByte('[')
+	buf.WriteString(s.sdID)
+	for _, f := range fields {
+		name := paramName(f.Key)
+		if name == "" {
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(name)
+		buf.WriteString(`="`)
+		buf.WriteString(sdEscaper.Replace(fmt.Sprint(f.Value)))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+}
+
+// sdEscaper escapes the characters RFC 5424 reserves inside PARAM-VALUE
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// paramName converts a field key into a valid SD PARAM-NAME: printable ASCII
+// except space, '=', ']' and '"', at most 32 characters
+func paramName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if r > ' ' && r < 127 && r != '=' && r != ']' && r != '"' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// headerField makes a value safe for an RFC 5424 header: printable ASCII
+// without spaces, truncated to max, or "-" when empty
+func headerField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.Map(func(r rune) rune {
+		if r > ' ' && r < 127 {
+			return r
+		}
+		return '_'
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// programName returns the base name of the running executable
+func programName() string {
+	name := os.Args[0]
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// Special Config.TimeFormat values rendering timestamps as Unix epoch numbers
+const (
+	TimeFormatUnix      = "unix"      // Seconds since the epoch
+	TimeFormatUnixMilli = "unixmilli" // Milliseconds since the epoch
+	TimeFormatUnixNano  = "unixnano"  // Nanoseconds since the epoch
+)
+
+// Default timestamp layouts
+const (
+	defaultTextTimeFormat = "2006/01/02 15:04:05"
+	defaultJSONTimeFormat = time.RFC3339Nano
+)
+
+// appendTime renders a timestamp with the configured format and location.
+// Epoch formats are written as bare numbers, which is also valid JSON.
+func (l *Logger) appendTime(buf *bytes.Buffer, timestamp int64, defaultLayout string) {
+	var b []byte
+	switch l.timeFormat {
+	case TimeFormatUnix:
+		b = strconv.AppendInt(buf.AvailableBuffer(), timestamp/int64(time.Second), 10)
+	case TimeFormatUnixMilli:
+		b = strconv.AppendInt(buf.AvailableBuffer(), timestamp/int64(time.Millisecond), 10)
+	case TimeFormatUnixNano:
+		b = strconv.AppendInt(buf.AvailableBuffer(), timestamp, 10)
+	default:
+		layout := l.timeFormat
+		if layout == "" {
+			layout = defaultLayout
+		}
+		b = l.timeOf(timestamp).AppendFormat(buf.AvailableBuffer(), layout)
+	}
+	buf.Write(b)
+}
+
+// timeOf converts a timestamp to a time in the configured location
+func (l *Logger) timeOf(timestamp int64) time.Time {
+	t := time.Unix(0, timestamp)
+	if l.timeLocation != nil {
+		t = t.In(l.timeLocation)
+	}
+	return t
+}
+
+// isEpochFormat reports whether the time format renders a number
+func (l *Logger) isEpochFormat() bool {
+	switch l.timeFormat {
+	case TimeFormatUnix, TimeFormatUnixMilli, TimeFormatUnixNano:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package logger
+
+import "time"
+
+// Timer times an operation started with Span, which logs "<name> started";
+// End logs "<name> finished" with an "elapsed" field. Both entries are
+// written at Config.TimingLevel:
+//
+//	span := logger.Span("load users", logger.Int("shard", 3))
+//	users, err := loadUsers()
+//	span.End()
+type Timer struct {
+	l      *Logger
+	name   string
+	fields []Field
+	start  time.Time
+	ended  bool
+}
+
+// newTimer starts timing without logging
+func (l *Logger) newTimer(name string, fields []Field) *Timer {
+	return &Timer{l: l, name: name, fields: fields[:len(fields):len(fields)], start: time.Now()}
+}
+
+// Span logs that the named operation started and returns a Timer to End when
+// it is done
+func (l *Logger) Span(name string, fields ...Field) *Timer {
+	l.logFields(l.timingLevelOf(), 0, name+" started", fields)
+	return l.newTimer(name, fields)
+}
+
+// TimeTrack logs that the named operation started and returns a function
+// logging that it finished, with the elapsed time, for use with defer:
+//
+//	defer logger.TimeTrack("load users")()
+func (l *Logger) TimeTrack(name string) func() {
+	l.logFields(l.timingLevelOf(), 0, name+" started", nil)
+	t := l.newTimer(name, nil)
+	return func() { t.finish(2) }
+}
+
+// Span starts timing an operation with the default logger
+func Span(name string, fields ...Field) *Timer {
+	defaultLogger.logFields(defaultLogger.timingLevelOf(), 0, name+" started", fields)
+	return defaultLogger.newTimer(name, fields)
+}
+
+// TimeTrack starts timing an operation with the default logger
+func TimeTrack(name string) func() {
+	defaultLogger.logFields(defaultLogger.timingLevelOf(), 0, name+" started", nil)
+	t := defaultLogger.newTimer(name, nil)
+	return func() { t.finish(2) }
+}
+
+// End logs that the operation finished with its elapsed time, and returns
+// it. Only the first End logs, so a deferred End may follow an earlier one.
+func (t *Timer) End() time.Duration {
+	return t.finish(2)
+}
+
+// Elapsed returns the time since the operation started
+func (t *Timer) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// finish logs the end of the operation; skip is as for caller
+func (t *Timer) finish(skip int) time.Duration {
+	elapsed := time.Since(t.start)
+	if t.ended {
+		return elapsed
+	}
+	t.ended = true
+
+	l := t.l
+	level := l.timingLevelOf()
+	if l == nil || (levelBelow(level, l.minLevel()) && l.ring == nil) {
+		return elapsed
+	}
+	pc, file, line := l.caller(skip)
+	l.dispatch(level, 0, t.name+" finished", append(t.fields, Dur("elapsed", elapsed)), pc, file, line)
+	return elapsed
+}
+
+// timingLevelOf returns the level of timing entries, tolerating a nil logger
+func (l *Logger) timingLevelOf() int {
+	if l == nil {
+		return DEBUG
+	}
+	return l.timingLevel
+}
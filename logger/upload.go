@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Upload defaults
+const (
+	defaultUploadRetries = 3
+	defaultUploadTimeout = 5 * time.Minute
+	uploadQueueSize      = 256
+)
+
+// ArchiveUploader ships a rotated archive to long-term storage such as S3,
+// GCS or Azure Blob Storage. name is unique per archive, e.g.
+// "app-2024-05-01T12-00-00.000.log.gz", so objects from different rotations
+// never overwrite each other. Upload must read r to the end or fail.
+type ArchiveUploader interface {
+	Upload(ctx context.Context, name string, r io.Reader) error
+}
+
+// UploadConfig enables shipping archives after rotation. With Compress, the
+// compressed archive is uploaded once compression finishes.
+type UploadConfig struct {
+	Uploader    ArchiveUploader // Destination of the archives
+	DeleteLocal bool            // Remove the local archive once it is uploaded
+	Retries     int             // Attempts after the first failed one, with growing delays (default: 3)
+	Timeout     time.Duration   // Time limit of one attempt (default: 5m)
+}
+
+// DirUploader is an ArchiveUploader copying archives into a directory, e.g.
+// a mounted network share or a bucket mounted with gcsfuse or s3fs
+type DirUploader string
+
+// Upload copies r to the directory under name, replacing the file atomically
+func (d DirUploader) Upload(ctx context.Context, name string, r io.Reader) error {
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return fmt.Errorf("failed to create upload directory: %v", err)
+	}
+	dst := filepath.Join(string(d), name)
+	tmp := dst + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create uploaded archive: %v", err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to copy archive: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to close uploaded archive: %v", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to move uploaded archive into place: %v", err)
+	}
+	return nil
+}
+
+// queueUpload hands an archive to the upload goroutine without blocking.
+// The archive is kept from retention until it is uploaded.
+func (l *Logger) queueUpload(path string) {
+	if l.upload == nil {
+		return
+	}
+	l.busyArchives.Store(path, true)
+	select {
+	case l.uploadCh <- path:
+	default:
+		l.busyArchives.Delete(path)
+		err := fmt.Errorf("upload queue full")
+		l.reportError(OpUpload, err, "Error uploading archive %s: %v", path, err)
+	}
+}
+
+// watchUploads uploads queued archives one at a time until the queue is
+// closed at shutdown. Archives left by an earlier run that deleted uploaded
+// archives are queued first, as only failed uploads remain.
+func (l *Logger) watchUploads() {
+	l.uploadWG.Add(1)
+	go func() {
+		defer l.uploadWG.Done()
+		for path := range l.uploadCh {
+			l.uploadArchive(path)
+			l.busyArchives.Delete(path)
+		}
+	}()
+
+	if l.upload.DeleteLocal {
+		archives, err := l.listArchives()
+		if err != nil && !os.IsNotExist(err) {
+			l.reportError(OpUpload, err, "Error reading archive directory: %v", err)
+		}
+		for i := len(archives) - 1; i >= 0; i-- {
+			l.queueUpload(archives[i].path)
+		}
+	}
+}
+
+// uploadArchive uploads one archive with retries, then removes the local
+// copy if configured
+func (l *Logger) uploadArchive(path string) {
+	name := l.uploadName(path)
+	delay := time.Second
+	var err error
+	for attempt := 0; attempt <= l.upload.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+			case <-l.abort:
+				return
+			}
+			delay *= 2
+		}
+		if err = l.uploadOnce(path, name); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		l.reportError(OpUpload, err, "Error uploading archive %s: %v", path, err)
+		return
+	}
+	l.stats.uploaded.Add(1)
+
+	if l.upload.DeleteLocal {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			l.reportError(OpUpload, err, "Error removing uploaded archive: %v", err)
+		}
+	}
+}
+
+// uploadOnce makes one upload attempt, cancelled by the timeout or an
+// aborted shutdown
+func (l *Logger) uploadOnce(path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.upload.Timeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.abort:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return l.upload.Uploader.Upload(ctx, name, file)
+}
+
+// uploadName returns the object name of an archive. Lumberjack names carry
+// the rotation time already; numbered archives are renamed after it, as
+// their numbers start over once uploaded archives are deleted.
+func (l *Logger) uploadName(path string) string {
+	if l.naming == ArchiveLumberjack {
+		return filepath.Base(path)
+	}
+	_, base, ext := l.splitLogPath()
+	suffix := ""
+	if strings.HasSuffix(path, compressedExt) {
+		suffix = compressedExt
+	}
+	rotated := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		rotated = info.ModTime()
+	}
+	return base + "-" + rotated.In(l.archiveLocation()).Format(lumberjackTimeFormat) + ext + suffix
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// minWorkerChunk is the fewest entries worth handing to a formatting worker;
+// smaller batches are rendered on the writer goroutine alone
+const minWorkerChunk = 256
+
+// workerBuf holds the lines one formatting worker rendered
+type workerBuf struct {
+	buf  bytes.Buffer
+	ends []int
+}
+
+// renderParallel renders a batch on up to FormatWorkers goroutines. Each
+// takes a contiguous run of entries and their output is joined in order, so
+// lines are written exactly as renderEntries would have written them.
+func (l *Logger) renderParallel(buf *bytes.Buffer, entries []*logEntry, ends []int, split bool) []int {
+	n := l.workers
+	if max := len(entries) / minWorkerChunk; n > max {
+		n = max
+	}
+	if n < 2 {
+		return l.renderEntries(buf, entries, ends, split)
+	}
+	// Rounding the run length up can leave the last workers nothing to do
+	size := (len(entries) + n - 1) / n
+	n = (len(entries) + size - 1) / size
+	for len(l.workerBufs) < n-1 {
+		l.workerBufs = append(l.workerBufs, &workerBuf{})
+	}
+
+	// The writer goroutine renders the first run straight into buf
+	var wg sync.WaitGroup
+	for i := 1; i < n; i++ {
+		chunk := entries[i*size : min(len(entries), (i+1)*size)]
+		w := l.workerBufs[i-1]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.ends = l.renderEntries(&w.buf, chunk, w.ends[:0], split)
+		}()
+	}
+	ends = l.renderEntries(buf, entries[:size], ends, split)
+	wg.Wait()
+
+	for _, w := range l.workerBufs[:n-1] {
+		offset := buf.Len()
+		buf.Write(w.buf.Bytes())
+		for _, end := range w.ends {
+			ends = append(ends, offset+end)
+		}
+
+		// Buffers grown by a burst are let go, as in writeBatch
+		w.buf.Reset()
+		if w.buf.Cap() > maxBatchBuffer {
+			w.buf = bytes.Buffer{}
+		}
+	}
+	return ends
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRenderParallelMatchesSequential(t *testing.T) {
+	l := newTestLogger(t, Config{FormatWorkers: 1000})
+
+	// 512001 entries on 1000 workers once made the last runs start past the end
+	for _, count := range []int{10, 511, 512, 513, 4099, 512001} {
+		entries := make([]*logEntry, count)
+		for i := range entries {
+			entries[i] = &logEntry{
+				level:     INFO,
+				timestamp: time.Unix(0, 0).Add(time.Duration(i)).UnixNano(),
+				msg:       []byte(fmt.Sprintf("entry %d", i)),
+			}
+		}
+
+		var want, got bytes.Buffer
+		wantEnds := l.renderEntries(&want, entries, nil, true)
+		gotEnds := l.renderParallel(&got, entries, nil, true)
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%d entries: parallel output differs from sequential output", count)
+		}
+		if !slices.Equal(gotEnds, wantEnds) {
+			t.Errorf("%d entries: parallel line ends differ from sequential ones", count)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import "fmt"
+
+// flushLocked writes the lines collected under WriteBufferSize to the file.
+// The caller must hold l.mu.
+func (l *Logger) flushLocked() {
+	if len(l.writeBuf) == 0 {
+		return
+	}
+	// Detached while writing, so a rotation the write triggers finds it empty
+	p := l.writeBuf
+	l.writeBuf = nil
+	l.writeFile(p)
+	l.writeBuf = p[:0]
+}
+
+// drainLocked writes the lines collected under WriteBufferSize to the
+// current file without rotating it, for callers about to let the file go.
+// The caller must hold l.mu.
+func (l *Logger) drainLocked() {
+	if len(l.writeBuf) == 0 {
+		return
+	}
+	l.appendFile(l.writeBuf)
+	l.writeBuf = l.writeBuf[:0]
+}
+
+// flushWriteBuffer writes the lines collected under WriteBufferSize
+func (l *Logger) flushWriteBuffer() {
+	if l.writeCap == 0 {
+		return
+	}
+	l.mu.Lock()
+	l.flushLocked()
+	l.mu.Unlock()
+}
+
+// reserveLocked preallocates disk space ahead of the file's end once a write
+// of n bytes would pass the space reserved so far. The caller must hold l.mu.
+func (l *Logger) reserveLocked(n int64) {
+	if l.prealloc == 0 || l.currSize+n <= l.allocEnd {
+		return
+	}
+	if err := preallocate(l.file, l.currSize, l.prealloc); err != nil {
+		// Filesystems without fallocate support keep working unreserved
+		l.prealloc = 0
+		l.reportError(OpWrite, err, "Error preallocating log file, preallocation disabled: %v", err)
+		return
+	}
+	l.allocEnd = l.currSize + l.prealloc
+}
+
+// trimLocked releases the space reserved beyond the end of the file before
+// it is closed, so archives take no more disk than their content. The
+// caller must hold l.mu.
+func (l *Logger) trimLocked() error {
+	if l.allocEnd == 0 {
+		return nil
+	}
+	l.allocEnd = 0
+	info, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %v", err)
+	}
+	if err := l.file.Truncate(info.Size()); err != nil {
+		return fmt.Errorf("failed to release preallocated space: %v", err)
+	}
+	return nil
+}
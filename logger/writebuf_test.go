@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotateWritesBufferOnce(t *testing.T) {
+	l := newTestLogger(t, Config{WriteBufferSize: 1 << 20, MaxFileSize: 1024})
+
+	// Lines collected past MaxFileSize when a rotation starts
+	line := strings.Repeat("x", 2000) + "\n"
+	l.mu.Lock()
+	l.writeBuf = append(l.writeBuf, line...)
+	err := l.rotate()
+	l.mu.Unlock()
+	if err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(filepath.Dir(l.logPath), "archive", "*"))
+	if len(archives) != 1 {
+		t.Fatalf("rotation left %d archives, want 1: %v", len(archives), archives)
+	}
+	data, err := os.ReadFile(archives[0])
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	if string(data) != line {
+		t.Errorf("archive holds %d bytes, want the %d buffered", len(data), len(line))
+	}
+}
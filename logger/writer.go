@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// levelWriter turns writes into log entries at a fixed level
+type levelWriter struct {
+	l     *Logger
+	level int
+}
+
+// Writer returns an io.Writer that logs every line written to it at level.
+// It plugs the logger into libraries that only accept an io.Writer or a
+// *log.Logger:
+//
+//	srv := &http.Server{ErrorLog: log.New(l.Writer(logger.ERROR), "", 0)}
+//	log.SetOutput(l.Writer(logger.INFO))
+//
+// Use flags 0 with log.New or log.SetFlags so timestamps are not duplicated.
+func (l *Logger) Writer(level int) io.Writer {
+	return &levelWriter{l: l, level: level}
+}
+
+// Writer returns an io.Writer that logs to the default logger at level
+func Writer(level int) io.Writer {
+	return defaultLogger.Writer(level)
+}
+
+// writerSkipPrefixes are the packages skipped when looking for the caller of a write
+var writerSkipPrefixes = []string{"github.com/jbarasa/logger/logger.", "log.", "fmt."}
+
+// Write logs each non-empty line of p as a separate entry. The caller is the
+// first frame outside this package and the standard log and fmt packages, so
+// entries written through log.Printf point at the Printf call.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	l := w.l
+	if l == nil || (levelBelow(w.level, l.minLevel()) && l.ring == nil) {
+		return len(p), nil
+	}
+
+	var pc uintptr
+	var file string
+	var line int
+	if !l.callerOff {
+		pc, file, line = externalCaller()
+	}
+	for _, msg := range bytes.Split(bytes.TrimRight(p, "\r\n"), []byte("\n")) {
+		msg = bytes.TrimRight(msg, "\r")
+		if len(msg) == 0 {
+			continue
+		}
+		l.dispatch(w.level, 0, string(msg), nil, pc, file, line)
+	}
+	return len(p), nil
+}
+
+// externalCaller finds the first caller outside writerSkipPrefixes
+func externalCaller() (uintptr, string, int) {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !more || !hasAnyPrefix(frame.Function, writerSkipPrefixes) && !isHelper(frame.Function) {
+			return frame.PC, frame.File, frame.Line
+		}
+	}
+}
+
+// hasAnyPrefix reports whether s starts with any of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}